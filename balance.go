@@ -0,0 +1,241 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// rpcTimeout bounds each -rpc request, so that an unreachable endpoint does not hold up the recovery for long.
+const rpcTimeout = 5 * time.Second
+
+// Selectors of the ERC-20 calls made for the -rpc-tokens balances.
+const (
+	erc20BalanceOf = "70a08231"
+	erc20Decimals  = "313ce567"
+	erc20Symbol    = "95d89b41"
+)
+
+type (
+	// balanceJSON is a balance of the recovered Ethereum address, as looked up with -rpc: the native coin, or an ERC-20 token.
+	balanceJSON struct {
+		Asset    string `json:"asset"`
+		Contract string `json:"contract,omitempty"`
+		Balance  string `json:"balance"`
+	}
+
+	rpcRequest struct {
+		JSONRPC string `json:"jsonrpc"`
+		ID      int    `json:"id"`
+		Method  string `json:"method"`
+		Params  []any  `json:"params"`
+	}
+
+	rpcResponse struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+)
+
+// validateRPC checks the -rpc endpoint and the -rpc-tokens contracts before a recovery is run.
+func validateRPC(rpcURL string, tokens []string) error {
+	u, err := url.Parse(rpcURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid -rpc `%s`, expected an http:// or https:// URL", rpcURL)
+	}
+	for _, token := range tokens {
+		if !common.IsHexAddress(token) {
+			return fmt.Errorf("invalid -rpc-tokens contract `%s`, expected a 0x address", token)
+		}
+	}
+	return nil
+}
+
+// rpcTokenList splits the -rpc-tokens list of contracts, skipping empty entries.
+func rpcTokenList(list string) []string {
+	tokens := make([]string, 0, 4)
+	for _, token := range strings.Split(list, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// lookupBalances queries the -rpc endpoint for the native balance of the address and its balance of each ERC-20 token.
+// A balance that could not be looked up is returned as a warning instead, as the recovery itself has succeeded by then.
+func lookupBalances(rpcURL, address string, tokens []string) ([]balanceJSON, []recovery.Warning) {
+	client := &http.Client{Timeout: rpcTimeout}
+	warn := func(what string, err error) recovery.Warning {
+		return recovery.Warning{Kind: recovery.WarnBalanceFailed, Message: fmt.Sprintf("Could not look up the %s of `%s` with -rpc: %s.", what, address, err)}
+	}
+	balances := make([]balanceJSON, 0, len(tokens)+1)
+	var warnings []recovery.Warning
+
+	wei, err := rpcQuantity(client, rpcURL, "eth_getBalance", address, "latest")
+	if err != nil {
+		// an endpoint that is down fails every call, so the tokens are not tried
+		return nil, []recovery.Warning{warn("balance", err)}
+	}
+	balances = append(balances, balanceJSON{Asset: "Native coin", Balance: formatUnits(wei, 18)})
+
+	for _, token := range tokens {
+		contract := common.HexToAddress(token).Hex()
+		call := func(data string) (string, error) {
+			return rpcCall(client, rpcURL, "eth_call", map[string]string{"to": contract, "data": "0x" + data}, "latest")
+		}
+		raw, err := call(erc20BalanceOf + strings.Repeat("0", 24) + strings.ToLower(strings.TrimPrefix(address, "0x")))
+		if err != nil {
+			warnings = append(warnings, warn("balance of token "+contract, err))
+			continue
+		}
+		amount, err := hexQuantity(raw)
+		if err != nil {
+			warnings = append(warnings, warn("balance of token "+contract, err))
+			continue
+		}
+		// the decimals and symbol are optional in ERC-20, so the raw amount is shown without them
+		decimals, symbol := 0, contract
+		if raw, err = call(erc20Decimals); err == nil {
+			if d, err := hexQuantity(raw); err == nil && d.IsInt64() && d.Int64() <= 77 {
+				decimals = int(d.Int64())
+			}
+		}
+		if raw, err = call(erc20Symbol); err == nil {
+			if s := abiString(raw); s != "" {
+				symbol = s
+			}
+		}
+		balances = append(balances, balanceJSON{Asset: symbol, Contract: contract, Balance: formatUnits(amount, decimals)})
+	}
+	return balances, warnings
+}
+
+// balanceSection outputs the -rpc balances of the address.
+func balanceSection(rpcURL string, balances []balanceJSON) outputSection {
+	host := rpcURL
+	if u, err := url.Parse(rpcURL); err == nil {
+		host = u.Host
+	}
+	section := outputSection{Title: "Balances", Note: fmt.Sprintf("Looked up at %s. Check them against a block explorer you trust.", host)}
+	for _, b := range balances {
+		label := b.Asset
+		if b.Contract != "" && b.Asset != b.Contract {
+			label = fmt.Sprintf("%s (%s)", b.Asset, b.Contract)
+		}
+		section.Fields = append(section.Fields, outputField{Label: label, Value: b.Balance})
+	}
+	return section
+}
+
+// rpcCall makes a JSON-RPC call and returns its result, which for the calls made here is a hex string.
+func rpcCall(client *http.Client, rpcURL, method string, params ...any) (string, error) {
+	body, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rpcURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned HTTP %d", method, resp.StatusCode)
+	}
+	var out rpcResponse
+	if err = json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("%s returned an invalid response: %v", method, err)
+	}
+	if out.Error != nil {
+		return "", fmt.Errorf("%s failed: %s (code %d)", method, out.Error.Message, out.Error.Code)
+	}
+	return out.Result, nil
+}
+
+// rpcQuantity makes a JSON-RPC call whose result is a hex quantity, e.g. eth_getBalance.
+func rpcQuantity(client *http.Client, rpcURL, method string, params ...any) (*big.Int, error) {
+	raw, err := rpcCall(client, rpcURL, method, params...)
+	if err != nil {
+		return nil, err
+	}
+	return hexQuantity(raw)
+}
+
+// hexQuantity decodes a 0x hex number, a quantity or a 32-byte word returned by eth_call.
+func hexQuantity(raw string) (*big.Int, error) {
+	digits := strings.TrimPrefix(raw, "0x")
+	if digits == "" {
+		return nil, fmt.Errorf("empty result `%s`", raw)
+	}
+	n, ok := new(big.Int).SetString(digits, 16)
+	if !ok {
+		return nil, fmt.Errorf("result `%s` is not a hex number", raw)
+	}
+	return n, nil
+}
+
+// abiString decodes the string returned by an eth_call, e.g. of symbol(): an ABI encoded string, or a bytes32 as older tokens return.
+// It returns "" if the result is neither.
+func abiString(raw string) string {
+	data, err := hex.DecodeString(strings.TrimPrefix(raw, "0x"))
+	if err != nil || len(data) < 32 {
+		return ""
+	}
+	if len(data) >= 64 {
+		offset, length := new(big.Int).SetBytes(data[:32]), new(big.Int).SetBytes(data[32:64])
+		if offset.Cmp(big.NewInt(32)) == 0 && length.IsInt64() && 64+length.Int64() <= int64(len(data)) {
+			return printable(string(data[64 : 64+length.Int64()]))
+		}
+	}
+	return printable(string(bytes.TrimRight(data[:32], "\x00")))
+}
+
+// printable keeps a token symbol from a contract, which can be anything, to what is safe to print on a terminal.
+func printable(s string) string {
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return ""
+		}
+	}
+	return s
+}
+
+// formatUnits formats an amount in the smallest unit of a coin or token as a decimal number, e.g. wei as ether with 18 decimals.
+func formatUnits(amount *big.Int, decimals int) string {
+	if decimals == 0 {
+		return amount.String()
+	}
+	digits := amount.String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole, frac := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
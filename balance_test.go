@@ -0,0 +1,119 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+	"github.com/stretchr/testify/assert"
+)
+
+// abiWord left-pads a hex value to an ABI word of 32 bytes.
+func abiWord(hexValue string) string {
+	return strings.Repeat("0", 64-len(hexValue)) + hexValue
+}
+
+// testRPC serves the calls of lookupBalances: a balance of 1.5 ether, and a token with a balance of 12.5 USDC (6 decimals).
+func testRPC(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&req)) {
+			return
+		}
+		result := ""
+		switch req.Method {
+		case "eth_getBalance":
+			result = "0x14d1120d7b160000"
+		case "eth_call":
+			var call struct{ Data string }
+			_ = json.Unmarshal(req.Params[0], &call)
+			switch call.Data[2:10] {
+			case erc20BalanceOf:
+				result = "0x" + abiWord("bebc20")
+			case erc20Decimals:
+				result = "0x" + abiWord("6")
+			case erc20Symbol:
+				result = "0x" + abiWord("20") + abiWord("4") + "55534443" + strings.Repeat("0", 56)
+			}
+		default:
+			_, _ = fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}`)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, result)
+	}))
+}
+
+func TestLookupBalances(t *testing.T) {
+	server := testRPC(t)
+	defer server.Close()
+
+	token := "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	balances, warnings := lookupBalances(server.URL, "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", []string{token})
+	assert.Empty(t, warnings)
+	assert.Equal(t, []balanceJSON{
+		{Asset: "Native coin", Balance: "1.5"},
+		{Asset: "USDC", Contract: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Balance: "12.5"},
+	}, balances)
+
+	section := balanceSection(server.URL, balances)
+	assert.Equal(t, "USDC (0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48)", section.Fields[1].Label)
+}
+
+func TestLookupBalances_Unreachable(t *testing.T) {
+	server := testRPC(t)
+	server.Close()
+
+	balances, warnings := lookupBalances(server.URL, "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", []string{"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"})
+	assert.Empty(t, balances)
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, recovery.WarnBalanceFailed, warnings[0].Kind)
+		assert.Contains(t, warnings[0].Message, "Could not look up the balance of `0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf` with -rpc")
+	}
+}
+
+func TestValidateRPC(t *testing.T) {
+	assert.NoError(t, validateRPC("https://eth.example.com/v1", rpcTokenList("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48, ")))
+	assert.ErrorContains(t, validateRPC("eth.example.com", nil), "expected an http:// or https:// URL")
+	assert.ErrorContains(t, validateRPC("https://eth.example.com", []string{"USDC"}), "invalid -rpc-tokens contract `USDC`")
+}
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		decimals int
+		expected string
+	}{
+		{"Whole", "1000000000000000000", 18, "1"},
+		{"Fraction", "1500000000000000000", 18, "1.5"},
+		{"Below One", "1", 18, "0.000000000000000001"},
+		{"Zero", "0", 18, "0"},
+		{"No Decimals", "42", 0, "42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, _ := new(big.Int).SetString(tt.amount, 10)
+			assert.Equal(t, tt.expected, formatUnits(amount, tt.decimals))
+		})
+	}
+}
+
+func TestABIString(t *testing.T) {
+	assert.Equal(t, "USDC", abiString("0x"+abiWord("20")+abiWord("4")+"55534443"+strings.Repeat("0", 56)))
+	// older tokens such as MKR return a bytes32
+	assert.Equal(t, "MKR", abiString("0x4d4b52"+strings.Repeat("0", 58)))
+	assert.Equal(t, "", abiString("0x1b5b3331"+strings.Repeat("0", 56)))
+	assert.Equal(t, "", abiString("0x"))
+}
@@ -0,0 +1,107 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/config"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+	"github.com/atotto/clipboard"
+)
+
+// Values that -clipboard-value copies to the clipboard.
+const (
+	clipboardAddress = "address"
+	clipboardKey     = "key"
+	clipboardWIF     = "wif"
+)
+
+// defaultClipboardTimeout is how long the value stays on the clipboard by default, in seconds.
+const defaultClipboardTimeout = 30
+
+// clipboardWrite and clipboardRead access the system clipboard: the Windows clipboard, pbcopy on macOS,
+// or xclip, xsel or wl-clipboard on Linux and the BSDs. The tests replace them.
+var (
+	clipboardWrite = clipboard.WriteAll
+	clipboardRead  = clipboard.ReadAll
+)
+
+// validateClipboard checks the -clipboard flags before a recovery is run, so that a missing clipboard is reported straight away.
+func validateClipboard(appConfig config.AppConfig) error {
+	switch appConfig.ClipboardValue {
+	case clipboardAddress, clipboardKey, clipboardWIF:
+	default:
+		return fmt.Errorf("unknown -clipboard-value `%s`, expected %s, %s or %s", appConfig.ClipboardValue, clipboardAddress, clipboardKey, clipboardWIF)
+	}
+	if appConfig.ClipboardTimeout < 1 {
+		return fmt.Errorf("invalid -clipboard-timeout %d, expected a number of seconds of at least 1", appConfig.ClipboardTimeout)
+	}
+	if appConfig.JSON {
+		return fmt.Errorf("-clipboard can't be combined with -json, as the clipboard is meant for a person at the screen")
+	}
+	if appConfig.VerifyOnly && appConfig.ClipboardValue != clipboardAddress {
+		return fmt.Errorf("no private keys are shown in -verify or -addresses-only mode, so only -clipboard-value %s works with it", clipboardAddress)
+	}
+	if clipboard.Unsupported {
+		return fmt.Errorf("-clipboard found no clipboard on this machine; on Linux, install xclip, xsel or wl-clipboard")
+	}
+	return nil
+}
+
+// clipboardValues picks the value that -clipboard-value copies: the Ethereum address or private key, or with -wif-only,
+// the Bitcoin address or WIF of -network (mainnet by default), as -qr-file does. A wif is the Bitcoin WIF in either case.
+func clipboardValues(result *recovery.Result, what, network string, wifOnly bool) (label, value string, secret bool) {
+	address, key := qrFileValues(result, network, wifOnly)
+	switch what {
+	case clipboardAddress:
+		return "address", address, false
+	case clipboardWIF:
+		_, wif := qrFileValues(result, network, true)
+		return "Bitcoin WIF", wif, true
+	}
+	if wifOnly {
+		return "Bitcoin WIF", key, true
+	}
+	return "private key", key, true
+}
+
+// copyToClipboard copies the value that -clipboard-value picks to the clipboard, counts down -clipboard-timeout and then clears it.
+// An interrupt in the meantime clears the clipboard too.
+func copyToClipboard(out io.Writer, interrupted *interrupts, appConfig config.AppConfig, result *recovery.Result) error {
+	label, value, secret := clipboardValues(result, appConfig.ClipboardValue, appConfig.Network, appConfig.WIFOnly)
+	interrupted.clipboard.Store(&value)
+	defer interrupted.clipboard.Store(nil)
+	if err := clipboardWrite(value); err != nil {
+		return fmt.Errorf("could not copy the %s to the clipboard: %v", label, err)
+	}
+	timeout := time.Duration(appConfig.ClipboardTimeout) * time.Second
+	if secret {
+		fmt.Fprintf(out, "\n⚠ THE %s IS ON THE CLIPBOARD! Any app on this machine can read it, and a clipboard manager or clipboard sync may keep a copy.\n"+
+			"⚠ Paste it into your wallet now: the clipboard is cleared in %d s, or as soon as the tool is stopped.\n", strings.ToUpper(label), appConfig.ClipboardTimeout)
+	} else {
+		fmt.Fprintf(out, "\nCopied the %s to the clipboard. It is cleared in %d s.\n", label, appConfig.ClipboardTimeout)
+	}
+	for left := timeout; left > 0; left -= time.Second {
+		fmt.Fprintf(out, "\rClearing the clipboard in %2d s…", int(left/time.Second))
+		time.Sleep(min(left, time.Second))
+	}
+	if err := clearClipboard(value); err != nil {
+		return fmt.Errorf("could not clear the clipboard, so the %s may still be on it: %v", label, err)
+	}
+	fmt.Fprintf(out, "\rThe clipboard was cleared.            \n")
+	return nil
+}
+
+// clearClipboard empties the clipboard if it still holds value. Anything copied since is left alone.
+func clearClipboard(value string) error {
+	if current, err := clipboardRead(); err == nil && current != value {
+		return nil
+	}
+	return clipboardWrite("")
+}
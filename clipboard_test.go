@@ -0,0 +1,103 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClipboard replaces the system clipboard for a test, and returns every value written to it.
+func fakeClipboard(t *testing.T) *[]string {
+	t.Helper()
+	written := make([]string, 0, 2)
+	write, read := clipboardWrite, clipboardRead
+	t.Cleanup(func() { clipboardWrite, clipboardRead = write, read })
+	clipboardWrite = func(value string) error {
+		written = append(written, value)
+		return nil
+	}
+	clipboardRead = func() (string, error) {
+		if len(written) == 0 {
+			return "", nil
+		}
+		return written[len(written)-1], nil
+	}
+	return &written
+}
+
+func TestCopyToClipboard(t *testing.T) {
+	written := fakeClipboard(t)
+	result := testResult(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", "")
+	appConfig := config.AppConfig{Clipboard: true, ClipboardValue: clipboardKey, ClipboardTimeout: 1}
+
+	var out bytes.Buffer
+	interrupted := new(interrupts)
+	if !assert.NoError(t, copyToClipboard(&out, interrupted, appConfig, result)) {
+		return
+	}
+	assert.Equal(t, []string{"0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", ""}, *written)
+	assert.Contains(t, out.String(), "⚠ THE PRIVATE KEY IS ON THE CLIPBOARD!")
+	assert.Contains(t, out.String(), "The clipboard was cleared.")
+	assert.Nil(t, interrupted.clipboard.Load())
+}
+
+func TestClearClipboard(t *testing.T) {
+	written := fakeClipboard(t)
+
+	// something copied since is left alone
+	_ = clipboardWrite("something else")
+	assert.NoError(t, clearClipboard("0xkey"))
+	assert.Equal(t, []string{"something else"}, *written)
+
+	_ = clipboardWrite("0xkey")
+	assert.NoError(t, clearClipboard("0xkey"))
+	assert.Equal(t, []string{"something else", "0xkey", ""}, *written)
+}
+
+func TestClipboardValues(t *testing.T) {
+	result := testResult(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", "")
+	tests := []struct {
+		name, what, network string
+		wifOnly             bool
+		label, value        string
+	}{
+		{"Key", clipboardKey, "", false, "private key", "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7"},
+		{"Address", clipboardAddress, "", false, "address", result.Chains.Ethereum},
+		{"WIF", clipboardWIF, "", false, "Bitcoin WIF", result.Chains.BitcoinMainnet.WIF},
+		{"Testnet WIF", clipboardWIF, networkTestnet, false, "Bitcoin WIF", result.Chains.BitcoinTestnet.WIF},
+		{"WIF Only Address", clipboardAddress, "", true, "address", result.Chains.BitcoinMainnet.Address},
+		{"WIF Only Key", clipboardKey, "", true, "Bitcoin WIF", result.Chains.BitcoinMainnet.WIF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label, value, secret := clipboardValues(result, tt.what, tt.network, tt.wifOnly)
+			assert.Equal(t, tt.label, label)
+			assert.Equal(t, tt.value, value)
+			assert.Equal(t, tt.what != clipboardAddress, secret)
+		})
+	}
+}
+
+func TestValidateClipboard(t *testing.T) {
+	tests := []struct {
+		name      string
+		appConfig config.AppConfig
+		expected  string
+	}{
+		{"Unknown Value", config.AppConfig{ClipboardValue: "seed", ClipboardTimeout: 30}, "unknown -clipboard-value `seed`"},
+		{"No Timeout", config.AppConfig{ClipboardValue: clipboardKey}, "invalid -clipboard-timeout 0"},
+		{"JSON", config.AppConfig{ClipboardValue: clipboardKey, ClipboardTimeout: 30, JSON: true}, "can't be combined with -json"},
+		{"Key In Verify Mode", config.AppConfig{ClipboardValue: clipboardKey, ClipboardTimeout: 30, VerifyOnly: true}, "only -clipboard-value address works"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, validateClipboard(tt.appConfig), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+// scrypt presets for the wallet v3 file key derivation
+const (
+	scryptStandard = "standard"
+	scryptLight    = "light"
+	scryptCustom   = "custom"
+
+	// bounds of the custom scrypt parameters: N from the strength of the light preset to about 4 GB of memory
+	minScryptN, maxScryptN = 1 << 10, 1 << 22
+	maxScryptP             = 16
+
+	// scryptR is the scrypt block size used by go-ethereum's keystore
+	scryptR = 8
+
+	// keystoreFileMode keeps the wallet v3 file readable by its owner only. Windows ignores it.
+	keystoreFileMode os.FileMode = 0o600
+)
+
+// passwordEnvVar holds the wallet v3 file password for scripted recoveries, keeping it out of the shell history.
+const passwordEnvVar = "VAULT_KS_PASSWORD"
+
+// keystorePassword returns the wallet v3 file password from -password, -password-file or the VAULT_KS_PASSWORD
+// environment variable, in that order. It is empty if none is set.
+func keystorePassword(password, passwordFile string) (string, error) {
+	if password != "" && passwordFile != "" {
+		return "", fmt.Errorf("use either -password or -password-file, not both")
+	}
+	if password != "" {
+		return password, nil
+	}
+	if passwordFile == "" {
+		return os.Getenv(passwordEnvVar), nil
+	}
+	content, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return "", fmt.Errorf("⚠ unable to read password file `%s`: %s", passwordFile, err)
+	}
+	defer clear(content)
+	// the newline that ends the file is not part of the password
+	if password = strings.TrimRight(string(content), "\r\n"); password == "" {
+		return "", fmt.Errorf("⚠ password file `%s` is empty", passwordFile)
+	}
+	return password, nil
+}
+
+// scryptParams returns the scrypt parameters of a preset. The custom preset takes them from -scrypt-n and -scrypt-p,
+// which must be given for it and only for it.
+func scryptParams(preset string, customN, customP int) (n, p int, err error) {
+	if preset != scryptCustom && (customN != 0 || customP != 0) {
+		return 0, 0, fmt.Errorf("-scrypt-n and -scrypt-p are only supported with -scrypt %s", scryptCustom)
+	}
+	switch preset {
+	case scryptStandard:
+		return keystore.StandardScryptN, keystore.StandardScryptP, nil
+	case scryptLight:
+		return keystore.LightScryptN, keystore.LightScryptP, nil
+	case scryptCustom:
+		// scrypt only takes a power of two for N, and large values exhaust the memory before failing
+		if customN < minScryptN || customN > maxScryptN || customN&(customN-1) != 0 {
+			return 0, 0, fmt.Errorf("invalid -scrypt-n %d, expected a power of two from %d to %d", customN, minScryptN, maxScryptN)
+		}
+		if customP < 1 || customP > maxScryptP {
+			return 0, 0, fmt.Errorf("invalid -scrypt-p %d, expected 1 to %d", customP, maxScryptP)
+		}
+		return customN, customP, nil
+	default:
+		return 0, 0, fmt.Errorf("unknown -scrypt `%s`, expected %s, %s or %s", preset, scryptStandard, scryptLight, scryptCustom)
+	}
+}
+
+// scryptMemory is the approximate amount of memory in bytes that scrypt needs for the N parameter.
+func scryptMemory(n int) int64 {
+	return 128 * int64(n) * scryptR
+}
+
+// exportKeystore writes an Ethereum wallet v3 file for the recovered ECDSA key.
+func exportKeystore(filename, password string, ecSK []byte, scryptN, scryptP int) (err error) {
+	privKey, err := ethcrypto.ToECDSA(ecSK)
+	if err != nil {
+		return fmt.Errorf("could not load the private key for the wallet v3 file: %v", err)
+	}
+	ksUuid, err := uuid.NewRandom()
+	if err != nil {
+		return fmt.Errorf("could not create random uuid: %v", err)
+	}
+	key := &keystore.Key{
+		Id:         ksUuid,
+		Address:    ethcrypto.PubkeyToAddress(privKey.PublicKey),
+		PrivateKey: privKey,
+	}
+
+	// this only turns a panic of keystore.EncryptKey into an error; running out of memory in scrypt is a fatal runtime error
+	// that no recover catches, which is why the error of a failed export hints at the lighter -scrypt settings
+	defer func() {
+		if r := recover(); r != nil {
+			err = keystoreKDFError(fmt.Errorf("%v", r), scryptN)
+		}
+	}()
+	keyfile, err := keystore.EncryptKey(key, password, scryptN, scryptP)
+	if err != nil {
+		return keystoreKDFError(err, scryptN)
+	}
+	return writeFileAtomic(filename, keyfile, keystoreFileMode)
+}
+
+// checkOverwrite refuses to replace an existing file unless force is set.
+func checkOverwrite(filename string, force bool) error {
+	if _, err := os.Stat(filename); err == nil && !force {
+		return fmt.Errorf("⚠ file `%s` already exists; pass -force to overwrite it", filename)
+	}
+	return nil
+}
+
+// checkWritable checks that files can be created in dir, by creating and removing a temporary file in it.
+func checkWritable(dir string) error {
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("⚠ unable to write files to `%s`: %s", dir, err)
+	}
+	_ = probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// writeFileAtomic writes data to a temporary file next to filename and renames it into place,
+// so that a crash during the write cannot leave a truncated file behind.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// keystoreKDFError wraps an error of the wallet v3 key derivation with the memory that scrypt needs, and a hint at -scrypt light.
+func keystoreKDFError(err error, scryptN int) error {
+	hint := fmt.Sprintf("The wallet v3 key derivation (scrypt N=%d) needs about %d MB of memory.", scryptN, scryptMemory(scryptN)>>20)
+	if scryptN > keystore.LightScryptN {
+		hint += fmt.Sprintf(" If this machine is low on memory, retry with -scrypt %s (needs about %d MB).", scryptLight, scryptMemory(keystore.LightScryptN)>>20)
+	}
+	return fmt.Errorf("could not create the wallet v3 file json: %v. Your keys were recovered and are part of the output. %s", err, hint)
+}
+
+// verifyAgainstKeystore decrypts an existing wallet v3 file and reports whether it holds the recovered ECDSA key.
+func verifyAgainstKeystore(filename, password string, ecSK []byte) (bool, error) {
+	keyfile, err := os.ReadFile(filename)
+	if err != nil {
+		return false, fmt.Errorf("could not read the wallet v3 file `%s`: %v", filename, err)
+	}
+	key, err := keystore.DecryptKey(keyfile, password)
+	if err != nil {
+		return false, fmt.Errorf("could not decrypt the wallet v3 file `%s`: %v", filename, err)
+	}
+	ksSK := ethcrypto.FromECDSA(key.PrivateKey)
+	defer clear(ksSK)
+	return subtle.ConstantTimeCompare(ksSK, ecSK) == 1, nil
+}
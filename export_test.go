@@ -0,0 +1,238 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// A key with leading zero bytes must round trip through the wallet v3 file unchanged.
+func TestExportKeystore_SmallScalar(t *testing.T) {
+	sk := make([]byte, 32)
+	sk[31] = 1
+	filename := filepath.Join(t.TempDir(), "wallet.json")
+	n, p, err := scryptParams(scryptLight, 0, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, exportKeystore(filename, "hunter2", sk, n, p)) {
+		return
+	}
+	matches, err := verifyAgainstKeystore(filename, "hunter2", sk)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, matches)
+}
+
+func TestExportKeystore_Light(t *testing.T) {
+	ecSK, _ := hex.DecodeString("4cc05b1d3216da8ef91729744159019b25ea1ed5932e387199f1de6ff6667ac2")
+	filename := filepath.Join(t.TempDir(), "wallet.json")
+
+	n, p, err := scryptParams(scryptLight, 0, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, exportKeystore(filename, "hunter2", ecSK, n, p)) {
+		return
+	}
+	keyfile, err := os.ReadFile(filename)
+	if !assert.NoError(t, err) {
+		return
+	}
+	key, err := keystore.DecryptKey(keyfile, "hunter2")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "0x620Ac72121234f1b313BD4e8b78C81323502679A", key.Address.Hex())
+	assert.Equal(t, ecSK, ethcrypto.FromECDSA(key.PrivateKey))
+}
+
+func TestExportKeystore_KDFError(t *testing.T) {
+	ecSK, _ := hex.DecodeString("4cc05b1d3216da8ef91729744159019b25ea1ed5932e387199f1de6ff6667ac2")
+	filename := filepath.Join(t.TempDir(), "wallet.json")
+
+	// N must be a power of 2, so scrypt refuses this one
+	err := exportKeystore(filename, "hunter2", ecSK, keystore.StandardScryptN+1, keystore.StandardScryptP)
+	if !assert.Error(t, err) {
+		return
+	}
+	assert.Contains(t, err.Error(), "needs about 256 MB of memory")
+	assert.Contains(t, err.Error(), "retry with -scrypt light")
+	assert.NoFileExists(t, filename)
+}
+
+func TestScryptParams(t *testing.T) {
+	tests := []struct {
+		name             string
+		preset           string
+		customN, customP int
+		n, p             int
+		wantErr          string
+	}{
+		{"Standard", scryptStandard, 0, 0, keystore.StandardScryptN, keystore.StandardScryptP, ""},
+		{"Light", scryptLight, 0, 0, keystore.LightScryptN, keystore.LightScryptP, ""},
+		{"Custom", scryptCustom, 1 << 20, 2, 1 << 20, 2, ""},
+		{"Unknown", "heavy", 0, 0, 0, 0, "unknown -scrypt"},
+		{"Custom Flags Without Custom", scryptLight, 1 << 20, 0, 0, 0, "only supported with -scrypt custom"},
+		{"Custom N Not A Power Of Two", scryptCustom, 1000000, 1, 0, 0, "invalid -scrypt-n"},
+		{"Custom N Too Large", scryptCustom, 1 << 23, 1, 0, 0, "invalid -scrypt-n"},
+		{"Custom N Missing", scryptCustom, 0, 1, 0, 0, "invalid -scrypt-n"},
+		{"Custom P Missing", scryptCustom, 1 << 12, 0, 0, 0, "invalid -scrypt-p"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, p, err := scryptParams(tt.preset, tt.customN, tt.customP)
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.wantErr)
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.n, n)
+			assert.Equal(t, tt.p, p)
+		})
+	}
+}
+
+func TestVerifyAgainstKeystore(t *testing.T) {
+	ecSK, _ := hex.DecodeString("4cc05b1d3216da8ef91729744159019b25ea1ed5932e387199f1de6ff6667ac2")
+	otherSK, _ := hex.DecodeString("0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7")
+	filename := filepath.Join(t.TempDir(), "wallet.json")
+	if !assert.NoError(t, exportKeystore(filename, "hunter2", ecSK, keystore.LightScryptN, keystore.LightScryptP)) {
+		return
+	}
+
+	matches, err := verifyAgainstKeystore(filename, "hunter2", ecSK)
+	if assert.NoError(t, err) {
+		assert.True(t, matches)
+	}
+	matches, err = verifyAgainstKeystore(filename, "hunter2", otherSK)
+	if assert.NoError(t, err) {
+		assert.False(t, matches)
+	}
+	_, err = verifyAgainstKeystore(filename, "wrong", ecSK)
+	assert.Error(t, err)
+	_, err = verifyAgainstKeystore(filepath.Join(t.TempDir(), "missing.json"), "hunter2", ecSK)
+	assert.Error(t, err)
+}
+
+func TestExportKeystore_FileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on Windows")
+	}
+	ecSK, _ := hex.DecodeString("4cc05b1d3216da8ef91729744159019b25ea1ed5932e387199f1de6ff6667ac2")
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "wallet.json")
+
+	// a world-readable file from an earlier export is replaced
+	if !assert.NoError(t, os.WriteFile(filename, []byte("old"), 0o644)) {
+		return
+	}
+	n, p, err := scryptParams(scryptLight, 0, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, exportKeystore(filename, "hunter2", ecSK, n, p)) {
+		return
+	}
+	info, err := os.Stat(filename)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, entries, 1)
+}
+
+func TestKeystorePassword(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password.txt")
+	if !assert.NoError(t, os.WriteFile(passwordFile, []byte("hunter2\n"), 0o600)) {
+		return
+	}
+	emptyFile := filepath.Join(dir, "empty.txt")
+	if !assert.NoError(t, os.WriteFile(emptyFile, []byte("\n"), 0o600)) {
+		return
+	}
+	t.Setenv(passwordEnvVar, "from-env")
+
+	tests := []struct {
+		name         string
+		password     string
+		passwordFile string
+		expected     string
+		wantErr      string
+	}{
+		{"Flag", "hunter3", "", "hunter3", ""},
+		{"File", "", passwordFile, "hunter2", ""},
+		{"Environment", "", "", "from-env", ""},
+		{"Both", "hunter3", passwordFile, "", "not both"},
+		{"Empty File", "", emptyFile, "", "is empty"},
+		{"Missing File", "", filepath.Join(dir, "missing.txt"), "", "unable to read password file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password, err := keystorePassword(tt.password, tt.passwordFile)
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.wantErr)
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expected, password)
+		})
+	}
+}
+
+func TestCheckOverwrite(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "wallet.json")
+	assert.NoError(t, checkOverwrite(filename, false))
+
+	if !assert.NoError(t, os.WriteFile(filename, []byte("old"), 0o600)) {
+		return
+	}
+	if err := checkOverwrite(filename, false); assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "-force")
+	}
+	assert.NoError(t, checkOverwrite(filename, true))
+}
+
+func TestCheckWritable(t *testing.T) {
+	dir := t.TempDir()
+	if !assert.NoError(t, checkWritable(dir)) {
+		return
+	}
+	// the probe file is removed again
+	entries, err := os.ReadDir(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Empty(t, entries)
+
+	err = checkWritable(filepath.Join(dir, "missing"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unable to write files to")
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+)
+
+// renderGenerations explains, per vault, which reshare generation can be used for recovery.
+func renderGenerations(vaults []recovery.VaultGenerations) string {
+	var sb strings.Builder
+	for _, vault := range vaults {
+		fmt.Fprintf(&sb, "Vault \"%s\" (%s)\n", vault.Name, vault.VaultID)
+		for _, gen := range vault.Generations {
+			mark := ""
+			if gen.Shares >= gen.Threshold {
+				mark = "  ✓"
+			}
+			fmt.Fprintf(&sb, "  nonce %d: %d share(s), threshold %d%s\n", gen.Nonce, gen.Shares, gen.Threshold, mark)
+		}
+
+		latest := vault.Generations[0]
+		gen, ok := vault.Recoverable()
+		switch {
+		case ok && gen.Nonce == latest.Nonce && vault.MixedNonces:
+			fmt.Fprintf(&sb, "  → Recoverable with the latest generation: -vault-id %s -nonce %d\n", vault.VaultID, gen.Nonce)
+		case ok && gen.Nonce == latest.Nonce:
+			fmt.Fprintf(&sb, "  → Recoverable with the latest generation: -vault-id %s\n", vault.VaultID)
+		case ok:
+			fmt.Fprintf(&sb, "  → Recoverable with an older generation: -vault-id %s -nonce %d -threshold %d\n", vault.VaultID, gen.Nonce, gen.Threshold)
+		default:
+			fmt.Fprintf(&sb, "  → Not recoverable: no single generation has enough shares. Find %d more share(s) from nonce %d.\n",
+				latest.Threshold-latest.Shares, latest.Nonce)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
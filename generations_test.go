@@ -0,0 +1,50 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderGenerations(t *testing.T) {
+	tests := []struct {
+		name  string
+		vault recovery.VaultGenerations
+		want  string
+	}{
+		{
+			name:  "latest",
+			vault: recovery.VaultGenerations{VaultID: "v1", Name: "A", Generations: []recovery.VaultGeneration{{Nonce: 1, Shares: 2, Threshold: 2}}},
+			want:  "→ Recoverable with the latest generation: -vault-id v1\n",
+		},
+		{
+			name:  "latest, mixed nonces",
+			vault: recovery.VaultGenerations{VaultID: "v1", Name: "A", Generations: []recovery.VaultGeneration{{Nonce: 1, Shares: 2, Threshold: 2}}, MixedNonces: true},
+			want:  "→ Recoverable with the latest generation: -vault-id v1 -nonce 1\n",
+		},
+		{
+			name: "older",
+			vault: recovery.VaultGenerations{VaultID: "v1", Name: "A", Generations: []recovery.VaultGeneration{
+				{Nonce: 2, Shares: 1, Threshold: 3}, {Nonce: 1, Shares: 2, Threshold: 2},
+			}},
+			want: "→ Recoverable with an older generation: -vault-id v1 -nonce 1 -threshold 2\n",
+		},
+		{
+			name: "none",
+			vault: recovery.VaultGenerations{VaultID: "v1", Name: "A", Generations: []recovery.VaultGeneration{
+				{Nonce: 2, Shares: 1, Threshold: 3}, {Nonce: 1, Shares: 1, Threshold: 2},
+			}},
+			want: "Find 2 more share(s) from nonce 2.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Contains(t, renderGenerations([]recovery.VaultGenerations{tt.vault}), tt.want)
+		})
+	}
+}
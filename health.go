@@ -0,0 +1,34 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+)
+
+// renderHealth renders the health report for humans, starting with the overall result.
+func renderHealth(report *recovery.HealthReport) string {
+	var sb strings.Builder
+	if report.Pass {
+		fmt.Fprintf(&sb, "PASS: all %d vault(s) are recoverable.\n\n", report.Total)
+	} else {
+		fmt.Fprintf(&sb, "FAIL: %d of %d vault(s) are at risk.\n\n", report.AtRisk, report.Total)
+	}
+	for _, vault := range report.Vaults {
+		status := "OK     "
+		if !vault.Recoverable {
+			status = "AT RISK"
+		}
+		fmt.Fprintf(&sb, "%s  \"%s\" (%s): nonce %d, %d share(s), threshold %d\n",
+			status, vault.Name, vault.VaultID, vault.Nonce, vault.Shares, vault.Threshold)
+		if vault.Problem != "" {
+			fmt.Fprintf(&sb, "         %s\n", vault.Problem)
+		}
+	}
+	return sb.String()
+}
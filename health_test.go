@@ -0,0 +1,26 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderHealth(t *testing.T) {
+	report := &recovery.HealthReport{Total: 2, AtRisk: 1, Vaults: []recovery.VaultHealth{
+		{VaultID: "v1", Name: "A", Recoverable: true, Nonce: 1, Shares: 2, Threshold: 2},
+		{VaultID: "v2", Name: "B", Nonce: 0, Shares: 1, Threshold: 2, Problem: "not enough shares at the latest nonce 0 (need 2, have 1)"},
+	}}
+	assert.Equal(t, "FAIL: 1 of 2 vault(s) are at risk.\n\n"+
+		"OK       \"A\" (v1): nonce 1, 2 share(s), threshold 2\n"+
+		"AT RISK  \"B\" (v2): nonce 0, 1 share(s), threshold 2\n"+
+		"         not enough shares at the latest nonce 0 (need 2, have 1)\n", renderHealth(report))
+
+	report = &recovery.HealthReport{Pass: true, Total: 1, Vaults: report.Vaults[:1]}
+	assert.Contains(t, renderHealth(report), "PASS: all 1 vault(s) are recoverable.\n")
+}
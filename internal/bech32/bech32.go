@@ -0,0 +1,119 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package bech32
+
+import (
+	"fmt"
+	"strings"
+)
+
+/* See https://github.com/bitcoin/bips/blob/master/bip-0173.mediawiki */
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+// EncodeSegwitAddress encodes a segwit v0 witness program with the human readable part hrp, e.g. "bc" or "tb".
+func EncodeSegwitAddress(hrp string, version byte, program []byte) (string, error) {
+	if version != 0 {
+		return "", fmt.Errorf("witness version %d is not supported", version)
+	}
+	if len(program) != 20 && len(program) != 32 {
+		return "", fmt.Errorf("invalid witness program length %d", len(program))
+	}
+	data := append([]byte{version}, convertBits(program, 8, 5)...)
+	return encode(hrp, data), nil
+}
+
+// Encode encodes 8-bit data, such as a Cosmos account address, with the human readable part hrp, e.g. "cosmos".
+func Encode(hrp string, data []byte) string {
+	return encode(hrp, convertBits(data, 8, 5))
+}
+
+// ValidateHRP checks that hrp can be used as a human readable part: 1 to 83 printable US-ASCII characters, in a single case.
+func ValidateHRP(hrp string) error {
+	if len(hrp) < 1 || len(hrp) > 83 {
+		return fmt.Errorf("human readable part must be 1 to 83 characters, got %d", len(hrp))
+	}
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return fmt.Errorf("human readable part `%s` has an invalid character at position %d", hrp, i+1)
+		}
+	}
+	if hrp != strings.ToLower(hrp) && hrp != strings.ToUpper(hrp) {
+		return fmt.Errorf("human readable part `%s` mixes upper and lower case", hrp)
+	}
+	return nil
+}
+
+// encode encodes 5-bit data with hrp and appends the bech32 checksum.
+func encode(hrp string, data []byte) string {
+	hrp = strings.ToLower(hrp)
+	combined := append(data, checksum(hrp, data)...)
+
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, b := range combined {
+		sb.WriteByte(charset[b])
+	}
+	return sb.String()
+}
+
+func checksum(hrp string, data []byte) []byte {
+	values := append(hrpExpand(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := polymod(values) ^ 1
+	sum := make([]byte, 6)
+	for i := range sum {
+		sum[i] = byte((mod >> uint(5*(5-i))) & 31)
+	}
+	return sum
+}
+
+func polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i, g := range generator {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+	return chk
+}
+
+func hrpExpand(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+// convertBits regroups data from fromBits to toBits per value, padding the last group with zeros.
+func convertBits(data []byte, fromBits, toBits uint) []byte {
+	acc, bits := uint32(0), uint(0)
+	maxV := uint32(1)<<toBits - 1
+	out := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, v := range data {
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte((acc>>bits)&maxV))
+		}
+	}
+	if bits > 0 {
+		out = append(out, byte((acc<<(toBits-bits))&maxV))
+	}
+	return out
+}
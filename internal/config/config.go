@@ -5,9 +5,63 @@
 package config
 
 type AppConfig struct {
-	Filenames      []string
-	NonceOverride  int
-	QuorumOverride int
-	ExportKSFile   string
-	PasswordForKS  string
+	Filenames        []string
+	NonceOverride    int
+	QuorumOverride   int
+	AutoThreshold    bool
+	ExportKSFile     string
+	OutputDir        string
+	Force            bool
+	PasswordForKS    string
+	PasswordFile     string
+	ScryptPreset     string
+	ScryptN          int
+	ScryptP          int
+	VerifyAgainst    string
+	VerifyPassword   string
+	MinInflatedKB    float64
+	MaxInflatedKB    float64
+	WIFOnly          bool
+	Network          string
+	BTCAddressType   string
+	Bech32HRP        string
+	Coins            []string
+	OnlyChains       []string
+	SignMessage      string
+	AsMnemonic       bool
+	QR               bool
+	QRPrivate        bool
+	QRFile           string
+	ListCSV          string
+	Report           string
+	VerifyHashes     string
+	RPC              string
+	RPCTokens        []string
+	SweepTo          string
+	SweepNonce       int
+	SweepGasPrice    string
+	ChainID          int64
+	SweepBalance     string
+	Filter           string
+	ShowShares       bool
+	QRPrivateFile    string
+	UncompressedWIF  bool
+	VerifyOnly       bool
+	AddressesOnly    bool
+	ExpectedAddress  string
+	MnemonicsFile    string
+	MnemonicsStdin   bool
+	WordByWord       bool
+	Plain            bool
+	ListGenerations  bool
+	RevealDelay      int
+	RevealClear      bool
+	RevealOnKey      bool
+	RevealTimeout    int
+	Clipboard        bool
+	ClipboardValue   string
+	ClipboardTimeout int
+	Verbose          bool
+	JSON             bool
+	Quiet            bool
 }
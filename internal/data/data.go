@@ -5,10 +5,16 @@
 package data
 
 import (
+	"bufio"
 	"bytes"
 	"compress/flate"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 )
 
 // DEFLATE (customized)
@@ -23,12 +29,139 @@ const deflateCommonJSONDict = `null` +
 	`Anomalous` + `M-221` + `E-222` + `M-511` + `E-521` + `NIST P-224` + `Curve1174` + `curve25519` + `BN(2,254)` + `brainpoolP256t1` + `ANSSI` + `FRP256v1` + `NIST P-256` + `E-382` + `M-383` + `Curve383187` + `brainpoolP384t1` + `NIST P-384` + `Curve41417` + `Ed448-Goldilocks` +
 	`LocalSecrets` + `LocalPreParams`
 
+var (
+	// ErrInflatedTooLarge is returned when data inflates beyond the upper bound, e.g. a decompression bomb.
+	ErrInflatedTooLarge = errors.New("save data inflates beyond the size limit; it may be corrupt or malicious")
+	// ErrInflatedTooSmall is returned when data inflates to less than the lower bound, which indicates corruption.
+	ErrInflatedTooSmall = errors.New("save data inflates to less than the expected minimum size; it may be corrupt")
+)
+
 // InflateSaveDataJSON decompresses TSS save data in JSON format using the DEFLATE algorithm using a custom dictionary.
-func InflateSaveDataJSON(compressed []byte) ([]byte, error) {
+// The inflated size must be within minSize and maxSize bytes; inflation stops as soon as maxSize is exceeded.
+func InflateSaveDataJSON(compressed []byte, minSize, maxSize int) ([]byte, error) {
 	reader := flate.NewReaderDict(bytes.NewReader(compressed), []byte(deflateCommonJSONDict))
-	decompressed, err := io.ReadAll(reader)
+	decompressed, err := io.ReadAll(io.LimitReader(reader, int64(maxSize)+1))
+	// the share data is secret, so what was inflated of a share that is rejected is wiped
 	if err != nil {
+		clear(decompressed)
 		return nil, fmt.Errorf("failed to read from flate reader: %v", err)
 	}
+	if len(decompressed) > maxSize {
+		clear(decompressed)
+		return nil, ErrInflatedTooLarge
+	}
+	if len(decompressed) < minSize {
+		clear(decompressed)
+		return nil, ErrInflatedTooSmall
+	}
 	return decompressed, reader.Close()
 }
+
+// GZIP
+
+// MaxBackupFileSize bounds the decompressed size of a gzipped backup file, as it could be a decompression bomb.
+const MaxBackupFileSize = 1 << 30
+
+// ErrBackupTooLarge is returned when a gzipped backup file decompresses beyond MaxBackupFileSize.
+var ErrBackupTooLarge = fmt.Errorf("gzipped backup file decompresses beyond %d MB; it may be corrupt or malicious", MaxBackupFileSize>>20)
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// openFile opens a file as it is stored, without decompressing it. The path may also name an entry of a zip archive.
+func openFile(path string) (io.ReadCloser, error) {
+	if archive, entry, ok := SplitZipPath(path); ok {
+		return openZipEntry(archive, entry)
+	}
+	return os.Open(path)
+}
+
+// FileSHA256 hashes a file as it is stored, e.g. still gzipped, as sha256sum does. The path may also name an entry of a zip archive.
+// The file is hashed as it is read, so that a large file is not held in memory.
+func FileSHA256(path string) (string, error) {
+	f, err := openFile(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReadBackupFile reads a backup file, decompressing it first if it is gzipped, e.g. a .json.gz file.
+// The path may also name an entry of a zip archive (see ZipEntrySeparator). The content of any other file is returned as is.
+func ReadBackupFile(path string) ([]byte, error) {
+	reader, err := OpenBackupFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// OpenBackupFile opens a backup file to be read as a stream, decompressing it on the fly if it is gzipped, so that a large
+// backup file is never held in memory as a whole. The path may also name an entry of a zip archive (see ZipEntrySeparator).
+// Reading a gzipped file fails with ErrBackupTooLarge once it decompresses beyond MaxBackupFileSize.
+func OpenBackupFile(path string) (io.ReadCloser, error) {
+	f, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+	buffered := bufio.NewReader(f)
+	if magic, _ := buffered.Peek(len(gzipMagic)); !bytes.Equal(magic, gzipMagic) {
+		return &readCloser{Reader: buffered, closers: []io.Closer{f}}, nil
+	}
+	reader, err := gzip.NewReader(buffered)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read gzipped file: %v", err)
+	}
+	return &readCloser{
+		Reader:  &limitedReader{reader: reader, left: MaxBackupFileSize, prefix: "failed to decompress gzipped file: ", tooLarge: ErrBackupTooLarge},
+		closers: []io.Closer{reader, f},
+	}, nil
+}
+
+// readCloser reads from a stream that is layered over others, e.g. a gzip stream over a file, and closes all of them.
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (rc *readCloser) Close() error {
+	var errs []error
+	for _, c := range rc.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// limitedReader fails with tooLarge once more than left bytes are read from the reader, e.g. of a decompression bomb.
+// Other errors of the reader are prefixed with prefix.
+type limitedReader struct {
+	reader   io.Reader
+	left     int64
+	prefix   string
+	tooLarge error
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, l.tooLarge
+	}
+	// one byte more than the limit is read, to tell a stream of exactly the limit from a larger one
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.reader.Read(p)
+	if l.left -= int64(n); l.left < 0 {
+		return 0, l.tooLarge
+	}
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("%s%v", l.prefix, err)
+	}
+	return n, err
+}
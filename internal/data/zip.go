@@ -0,0 +1,76 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package data
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// ZIP
+
+// ZipEntrySeparator separates a zip archive from the name of an entry in it, as in backups.zip!/party1.json.
+// Such a path stands for the entry wherever a backup file is expected.
+const ZipEntrySeparator = "!/"
+
+// IsZipFile reports whether the path names a zip archive by its extension.
+func IsZipFile(name string) bool {
+	return strings.EqualFold(path.Ext(name), ".zip")
+}
+
+// SplitZipPath splits a path of a zip entry into the archive and the entry name. ok is false for any other path.
+func SplitZipPath(name string) (archive, entry string, ok bool) {
+	archive, entry, ok = strings.Cut(name, ZipEntrySeparator)
+	if !ok || !IsZipFile(archive) {
+		return name, "", false
+	}
+	return archive, entry, true
+}
+
+// ZipEntries returns the paths of the JSON backup files in a zip archive (.json and .json.gz entries), in archive order.
+// Folders, macOS resource forks and other files are skipped.
+func ZipEntries(archive string) ([]string, error) {
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open zip archive `%s`: %v", archive, err)
+	}
+	defer reader.Close()
+
+	entries := make([]string, 0, len(reader.File))
+	for _, f := range reader.File {
+		base := path.Base(f.Name)
+		if f.FileInfo().IsDir() || strings.HasPrefix(f.Name, "__MACOSX/") || strings.HasPrefix(base, ".") {
+			continue
+		}
+		if lower := strings.ToLower(base); strings.HasSuffix(lower, ".json") || strings.HasSuffix(lower, ".json.gz") {
+			entries = append(entries, archive+ZipEntrySeparator+f.Name)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("zip archive `%s` has no JSON backup files", archive)
+	}
+	return entries, nil
+}
+
+// openZipEntry opens an entry of a zip archive to be read as a stream. Reading it fails once it goes beyond MaxBackupFileSize bytes.
+func openZipEntry(archive, entry string) (io.ReadCloser, error) {
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open zip archive `%s`: %v", archive, err)
+	}
+	f, err := reader.Open(entry)
+	if err != nil {
+		reader.Close()
+		return nil, fmt.Errorf("zip entry `%s`: %v", entry, err)
+	}
+	return &readCloser{
+		Reader: &limitedReader{reader: f, left: MaxBackupFileSize, prefix: "zip entry `" + entry + "`: ",
+			tooLarge: fmt.Errorf("zip entry `%s`: decompresses beyond %d MB; it may be corrupt or malicious", entry, MaxBackupFileSize>>20)},
+		closers: []io.Closer{f, reader},
+	}, nil
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+// Package secmem optionally locks buffers holding secret material into RAM, so that they are not swapped to disk,
+// and wipes them once they are no longer needed.
+package secmem
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+var (
+	// ErrUnsupported is returned by Enable on platforms that have no mlock.
+	ErrUnsupported = errors.New("memory locking is not supported on this platform")
+
+	enabled  atomic.Bool
+	errMu    sync.Mutex
+	firstErr error
+)
+
+// Enable turns on locking for the buffers subsequently passed to Lock and LockInt.
+func Enable() error {
+	if !supported {
+		return ErrUnsupported
+	}
+	enabled.Store(true)
+	return nil
+}
+
+// Lock locks the memory backing b, if locking is enabled.
+// Pages are never unlocked, because mlock is not reference counted and other secrets may share a page;
+// the OS releases them when the process exits.
+func Lock(b []byte) {
+	if !enabled.Load() || len(b) == 0 {
+		return
+	}
+	if err := mlock(b); err != nil {
+		errMu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		errMu.Unlock()
+	}
+}
+
+// LockInt locks the memory backing the words of a big.Int, if locking is enabled.
+func LockInt(i *big.Int) {
+	if i == nil {
+		return
+	}
+	words := i.Bits()
+	if len(words) == 0 {
+		return
+	}
+	Lock(unsafe.Slice((*byte)(unsafe.Pointer(&words[0])), len(words)*int(unsafe.Sizeof(words[0]))))
+}
+
+// WipeInt overwrites the words of a big.Int with zeros and sets it to 0.
+// Unlike SetInt64(0), this also clears the backing array, which big.Int keeps for reuse.
+func WipeInt(i *big.Int) {
+	if i == nil {
+		return
+	}
+	clear(i.Bits())
+	i.SetInt64(0)
+}
+
+// Err returns the first error encountered while locking memory, e.g. when RLIMIT_MEMLOCK was exceeded.
+func Err() error {
+	errMu.Lock()
+	defer errMu.Unlock()
+	return firstErr
+}
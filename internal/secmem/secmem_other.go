@@ -0,0 +1,13 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package secmem
+
+const supported = false
+
+func mlock(_ []byte) error {
+	return ErrUnsupported
+}
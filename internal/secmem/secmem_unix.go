@@ -0,0 +1,17 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package secmem
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+const supported = true
+
+func mlock(b []byte) error {
+	return unix.Mlock(b)
+}
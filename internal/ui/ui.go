@@ -6,38 +6,88 @@ package ui
 
 import (
 	"fmt"
+	"io"
+	"os"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/version"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 const (
+	// WORDS is the length of the phrase of a backup file that does not name its cipher.
 	WORDS = 24
 )
 
+// phraseLengths are the BIP39 phrase lengths whose entropy is an AES key: 128, 192 or 256 bits.
+var phraseLengths = []int{12, 18, 24}
+
 var (
-	// ANSI escape seqs for colours in the terminal
-	AnsiCodes = map[string]string{
-		"bold":        "\033[1m",
-		"invertOn":    "\033[7m",
-		"darkRedBG":   "\033[41m",
-		"darkGreenBG": "\033[42m",
-		"reset":       "\033[0m",
-	}
+	// the styles of the banner and boxes; lipgloss leaves them out where the terminal has no color support, e.g. an older Windows console
+	bannerStyle     = lipgloss.NewStyle().Reverse(true).Bold(true).Width(37).Align(lipgloss.Center)
+	errorLabelStyle = lipgloss.NewStyle().Background(lipgloss.ANSIColor(1)).Bold(true).Width(9).Align(lipgloss.Center)
+	successStyle    = lipgloss.NewStyle().Background(lipgloss.ANSIColor(2)).Bold(true).Width(16).Align(lipgloss.Center)
+
+	// colorOff is set by DisableColor
+	colorOff bool
 )
 
+// EnableTerminalStyling turns on the processing of ANSI escape sequences by the Windows console for stdout and stderr,
+// which older consoles otherwise print as is. Elsewhere it does nothing. The console mode is left on when the tool exits.
+func EnableTerminalStyling() {
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		_, _ = termenv.EnableVirtualTerminalProcessing(termenv.NewOutput(f))
+	}
+}
+
+// WantsColor reports whether output to w should be styled: it is a terminal, and NO_COLOR (https://no-color.org) is not set.
+func WantsColor(w io.Writer) bool {
+	return os.Getenv("NO_COLOR") == "" && IsTerminal(w)
+}
+
+// DisableColor turns off all the styling of the output, that of the banner and boxes as well as of the forms and tables.
+func DisableColor() {
+	colorOff = true
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
+// ColorDisabled reports whether the output is not styled, as DisableColor was called or the terminal has no color support.
+func ColorDisabled() bool {
+	return colorOff || lipgloss.ColorProfile() == termenv.Ascii
+}
+
 func Banner() string {
+	if ColorDisabled() {
+		return "\nio.finnet Key Recovery Tool " + version.Version + "\n\n"
+	}
 	b := "\n"
-	b += fmt.Sprintf("%s%s                                     %s\n", AnsiCodes["invertOn"], AnsiCodes["bold"], AnsiCodes["reset"])
-	b += fmt.Sprintf("%s%s     io.finnet Key Recovery Tool     %s\n", AnsiCodes["invertOn"], AnsiCodes["bold"], AnsiCodes["reset"])
-	b += fmt.Sprintf("%s%s               v5.1.4                %s\n", AnsiCodes["invertOn"], AnsiCodes["bold"], AnsiCodes["reset"])
-	b += fmt.Sprintf("%s%s                                     %s\n", AnsiCodes["invertOn"], AnsiCodes["bold"], AnsiCodes["reset"])
+	for _, line := range []string{"", "io.finnet Key Recovery Tool", version.Version, ""} {
+		b += bannerStyle.Render(line) + "\n"
+	}
 	b += "\n"
 	return b
 }
 
 func ErrorBox(err error) string {
+	if ColorDisabled() {
+		return fmt.Sprintf("\nError: %s.\n\n", err)
+	}
 	b := "\n"
-	b += fmt.Sprintf("%s%s         %s\n", AnsiCodes["darkRedBG"], AnsiCodes["bold"], AnsiCodes["reset"])
-	b += fmt.Sprintf("%s%s  Error  %s  %s.\n", AnsiCodes["darkRedBG"], AnsiCodes["bold"], AnsiCodes["reset"], err)
-	b += fmt.Sprintf("%s%s         %s\n", AnsiCodes["darkRedBG"], AnsiCodes["bold"], AnsiCodes["reset"])
+	b += errorLabelStyle.Render("") + "\n"
+	b += fmt.Sprintf("%s  %s.\n", errorLabelStyle.Render("Error"), err)
+	b += errorLabelStyle.Render("") + "\n"
 	b += "\n"
 	return b
 }
+
+// SuccessBox renders the success banner shown once a vault has been recovered.
+func SuccessBox() string {
+	if ColorDisabled() {
+		return "Success!\n"
+	}
+	b := ""
+	for _, line := range []string{"", "Success!", ""} {
+		b += successStyle.Render(line) + "\n"
+	}
+	return b
+}
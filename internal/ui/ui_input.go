@@ -6,31 +6,39 @@ package ui
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/config"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
 	"github.com/cdfmlr/ellipsis"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/list"
 	errors2 "github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
 )
 
 type (
-	VaultsDataFile struct {
-		File      string
-		Mnemonics string
-	}
+	// VaultsDataFile is a backup file with the phrase entered for it.
+	VaultsDataFile = recovery.VaultsDataFile
 
 	// MnemonicsFormModel is a struct that represents the model for the mnemonics entry.
 	MnemonicsFormModel struct {
-		filenames []string
+		filenames  []string
+		out        io.Writer
+		wordByWord bool
 	}
 )
 
-func NewMnemonicsForm(config config.AppConfig) MnemonicsFormModel {
+// NewMnemonicsForm returns the form for entering the phrase of each file. The form is drawn on out.
+func NewMnemonicsForm(config config.AppConfig, out io.Writer) MnemonicsFormModel {
 	return MnemonicsFormModel{
-		filenames: config.Filenames,
+		filenames:  config.Filenames,
+		out:        out,
+		wordByWord: config.WordByWord,
 	}
 }
 
@@ -40,49 +48,123 @@ func (m MnemonicsFormModel) Run() (*[]VaultsDataFile, error) {
 	for _, pathname := range m.filenames {
 		displayFileName := ellipsis.Centering(filepath.Base(pathname), 64)
 
-		input := huh.NewText().
-			Key("phrase").
-			Title(fmt.Sprintf("Mnemonics for %s", displayFileName)).
-			Description(fmt.Sprintf("Enter the %d word phrase", WORDS)).
-			Validate(func(input string) error {
-				fileWithMnemonic := VaultsDataFile{File: pathname, Mnemonics: input}
-				return fileWithMnemonic.ValidateMnemonics()
-			})
-
-		var form *huh.Form
-
 		// Show the list of files added if there are more than one
+		var header []huh.Field
 		if len(filesWithMnemonics) > 0 {
-			form = huh.NewForm(
-				huh.NewGroup(
-					huh.NewNote().Description(m.fileList(filesWithMnemonics)),
-					input,
-				),
-			).WithTheme(huh.ThemeBase16())
-		} else {
-			form = huh.NewForm(huh.NewGroup(input)).WithTheme(huh.ThemeBase16())
+			header = append(header, huh.NewNote().Description(m.fileList(filesWithMnemonics)))
 		}
 
-		err := form.Run()
-		if err != nil {
+		// older backups may take a 12 or 18 word phrase, which the file tells by its cipher
+		wordCount := recovery.PhraseWords(pathname)
+		var form *huh.Form
+		var phrase func() string
+		if m.wordByWord {
+			form, phrase = wordsForm(displayFileName, header, wordCount)
+		} else {
+			form, phrase = pasteForm(displayFileName, header, wordCount)
+		}
+		if err := form.WithTheme(huh.ThemeBase16()).WithOutput(m.out).Run(); err != nil {
 			return nil, err
 		}
 
-		mnemonics := form.GetString("phrase")
-		if mnemonics == "" {
+		mnemonics := phrase()
+		if strings.TrimSpace(mnemonics) == "" {
 			return nil, fmt.Errorf("phrase for %s is empty", displayFileName)
 		}
+		mnemonics, err := normalizeMnemonic(mnemonics, wordCount)
+		if err != nil {
+			return nil, err
+		}
 
 		f := VaultsDataFile{File: pathname, Mnemonics: mnemonics}
 		filesWithMnemonics = append(filesWithMnemonics, f)
 	}
 
-	fmt.Println(m.fileList(filesWithMnemonics))
-	fmt.Print("All mnemonics entered\n\n")
+	fmt.Fprintln(m.out, m.fileList(filesWithMnemonics))
+	fmt.Fprint(m.out, "All mnemonics entered\n\n")
 
 	return &filesWithMnemonics, nil
 }
 
+// pasteForm takes the whole phrase of wordCount words in a single text area, e.g. pasted from a secure note.
+// With a wordCount of 0, a phrase of any of phraseLengths is taken.
+func pasteForm(displayFileName string, header []huh.Field, wordCount int) (*huh.Form, func() string) {
+	length := "12, 18 or 24"
+	if wordCount > 0 {
+		length = strconv.Itoa(wordCount)
+	}
+	// the description is updated as the phrase is typed, to catch typos before decryption
+	var phrase string
+	input := huh.NewText().
+		Key("phrase").
+		Value(&phrase).
+		Title(fmt.Sprintf("Mnemonics for %s", displayFileName)).
+		DescriptionFunc(func() string {
+			return fmt.Sprintf("Enter the %s word phrase. %s", length, phraseStatus(phrase, wordCount))
+		}, &phrase).
+		Validate(func(input string) error {
+			_, err := normalizeMnemonic(input, wordCount)
+			return err
+		})
+	return huh.NewForm(huh.NewGroup(append(header, input)...)), func() string { return phrase }
+}
+
+// wordsPerPage is the number of word inputs shown at once by the word by word form.
+const wordsPerPage = 6
+
+// wordsForm takes the phrase one word at a time, completing each word from the BIP39 word list with tab.
+// The whole phrase may still be pasted into the first word, which then skips the other words. There is an input for each of
+// the wordCount words, or for WORDS words if the file does not tell.
+func wordsForm(displayFileName string, header []huh.Field, wordCount int) (*huh.Form, func() string) {
+	if wordCount == 0 {
+		wordCount = WORDS
+	}
+	words := make([]string, wordCount)
+	pasted := func() bool { return len(strings.Fields(words[0])) > 1 }
+
+	wordInput := func(i int) *huh.Input {
+		return huh.NewInput().
+			Title(fmt.Sprintf("Word %d", i+1)).
+			Value(&words[i]).
+			Suggestions(bip39.GetWordList()).
+			Validate(func(word string) error {
+				if _, ok := bip39.GetWordIndex(strings.ToLower(strings.TrimSpace(word))); !ok {
+					return fmt.Errorf("word %d is not in the BIP39 word list", i+1)
+				}
+				return nil
+			})
+	}
+
+	first := wordInput(0).
+		Title(fmt.Sprintf("Mnemonics for %s: word 1", displayFileName)).
+		Description(fmt.Sprintf("Enter the %d words one by one; press tab to complete a word. Or paste the whole phrase here.", wordCount)).
+		Validate(func(input string) error {
+			if len(strings.Fields(input)) > 1 {
+				_, err := normalizeMnemonic(input, wordCount)
+				return err
+			}
+			if _, ok := bip39.GetWordIndex(strings.ToLower(strings.TrimSpace(input))); !ok {
+				return fmt.Errorf("word 1 is not in the BIP39 word list")
+			}
+			return nil
+		})
+	groups := []*huh.Group{huh.NewGroup(append(header, first)...)}
+	for start := 1; start < wordCount; start += wordsPerPage {
+		fields := make([]huh.Field, 0, wordsPerPage)
+		for i := start; i < min(start+wordsPerPage, wordCount); i++ {
+			fields = append(fields, wordInput(i))
+		}
+		groups = append(groups, huh.NewGroup(fields...).WithHideFunc(pasted))
+	}
+
+	return huh.NewForm(groups...), func() string {
+		if pasted() {
+			return words[0]
+		}
+		return strings.Join(words, " ")
+	}
+}
+
 func (m MnemonicsFormModel) fileList(filesWithMnemonics []VaultsDataFile) string {
 	if len(filesWithMnemonics) == 0 {
 		return ""
@@ -112,35 +194,35 @@ func (m MnemonicsFormModel) fileList(filesWithMnemonics []VaultsDataFile) string
 /**
  * VaultPickerItem is a struct that represents the model for the vault picker form.
  */
-type VaultPickerItem struct {
-	VaultID          string
-	Name             string
-	Quorum           int
-	LastReShareNonce int
-	NumberOfShares   int
-}
+type VaultPickerItem = recovery.VaultSummary
 
-func RunVaultPickerForm(vaultsData []VaultPickerItem) (string, error) {
+// RunVaultPickerForm asks for the vault to recover, drawing the form on out.
+func RunVaultPickerForm(vaultsData []VaultPickerItem, out io.Writer) (string, error) {
 	var chosenVaultId string
 
 	vaultSelectOptions := make([]huh.Option[string], len(vaultsData))
 	for i, vault := range vaultsData {
-		vaultSelectOptions[i] = huh.NewOption(fmt.Sprintf("%s (%d/%d)", vault.Name, vault.NumberOfShares, vault.Quorum), vault.VaultID)
+		label := fmt.Sprintf("%d. %s (%d/%d)", i+1, vault.Name, vault.NumberOfShares, vault.Quorum)
+		if vault.NumberOfShares < vault.Quorum {
+			label += " ⚠ not enough shares"
+		}
+		vaultSelectOptions[i] = huh.NewOption(label, vault.VaultID)
 	}
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewSelect[string]().
 				Title("Select a vault").
+				Description("Type / to search the vaults by name.").
 				Options(vaultSelectOptions...).
 				Value(&chosenVaultId),
 		),
-	).WithTheme(huh.ThemeBase16())
+	).WithTheme(huh.ThemeBase16()).WithOutput(out)
 	err := form.Run()
 	if err != nil {
 		return "", errors2.Wrapf(err, "unable to run form")
 	}
 	if chosenVaultId == "" {
-		fmt.Println("No vault selected")
+		fmt.Fprintln(out, "No vault selected")
 		return "", errors2.Errorf("No vault selected")
 	}
 	return chosenVaultId, nil
@@ -0,0 +1,96 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+	errors2 "github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// ReadMnemonicsFile reads the phrases for the input files from a file, for scripted recoveries without the form.
+// The file is either a JSON object mapping each input file (by path or base name) to its phrase,
+// or has one phrase per line in the order of the input files.
+func ReadMnemonicsFile(path string, filenames []string) (*[]VaultsDataFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors2.Errorf("⚠ unable to read mnemonics file `%s`: %s", path, err)
+	}
+	defer clear(content)
+
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseMnemonicsJSON(trimmed, filenames)
+	}
+	return parseMnemonicsLines(content, filenames)
+}
+
+// ReadMnemonics reads newline-delimited phrases, e.g. piped from a password manager, one per input file in file order.
+func ReadMnemonics(r io.Reader, filenames []string) (*[]VaultsDataFile, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors2.Errorf("⚠ unable to read the phrases: %s", err)
+	}
+	defer clear(content)
+	return parseMnemonicsLines(content, filenames)
+}
+
+func parseMnemonicsJSON(content []byte, filenames []string) (*[]VaultsDataFile, error) {
+	phrases := make(map[string]string, len(filenames))
+	if err := json.Unmarshal(content, &phrases); err != nil {
+		return nil, errors2.Wrapf(err, "⚠ invalid mnemonics file, expecting a JSON object of file to phrase")
+	}
+	filesWithMnemonics := make([]VaultsDataFile, 0, len(filenames))
+	for _, file := range filenames {
+		phrase, ok := phrases[file]
+		if !ok {
+			phrase, ok = phrases[filepath.Base(file)]
+		}
+		if !ok {
+			return nil, errors2.Errorf("⚠ no phrase for `%s` in the mnemonics file", file)
+		}
+		filesWithMnemonics = append(filesWithMnemonics, VaultsDataFile{File: file, Mnemonics: phrase})
+	}
+	return validatePhrases(filesWithMnemonics)
+}
+
+func parseMnemonicsLines(content []byte, filenames []string) (*[]VaultsDataFile, error) {
+	phrases := make([]string, 0, len(filenames))
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			phrases = append(phrases, line)
+		}
+	}
+	if len(phrases) != len(filenames) {
+		return nil, errors2.Errorf("⚠ got %d phrases for %d files, expecting one phrase per file in the same order", len(phrases), len(filenames))
+	}
+	filesWithMnemonics := make([]VaultsDataFile, 0, len(filenames))
+	for i, file := range filenames {
+		filesWithMnemonics = append(filesWithMnemonics, VaultsDataFile{File: file, Mnemonics: phrases[i]})
+	}
+	return validatePhrases(filesWithMnemonics)
+}
+
+// validatePhrases checks every phrase up front, so that a typo is reported against its file before any decryption.
+func validatePhrases(filesWithMnemonics []VaultsDataFile) (*[]VaultsDataFile, error) {
+	for i, f := range filesWithMnemonics {
+		phrase, err := normalizeMnemonic(f.Mnemonics, recovery.PhraseWords(f.File))
+		if err != nil {
+			return nil, errors2.Errorf("%s for `%s`", err, f.File)
+		}
+		f.Mnemonics = phrase
+		if _, err := bip39.EntropyFromMnemonic(f.Mnemonics); err != nil {
+			return nil, errors2.Errorf("⚠ invalid phrase for `%s`: %s", f.File, err)
+		}
+		filesWithMnemonics[i] = f
+	}
+	return &filesWithMnemonics, nil
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadMnemonicsFile(t *testing.T) {
+	// BIP39 test vectors for all-zero and all-one entropy
+	phrase1 := strings.Repeat("abandon ", 23) + "art"
+	phrase2 := strings.Repeat("zoo ", 23) + "vote"
+	badChecksum := strings.TrimSpace(strings.Repeat("abandon ", 24))
+	filenames := []string{"backups/file1.json", "backups/file2.json"}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"Lines", phrase1 + "\n" + phrase2 + "\n", ""},
+		{"Lines With Blanks And CRLF", "\r\n  " + phrase1 + "  \r\n\r\n" + phrase2 + "\r\n", ""},
+		{"JSON By Path", `{"backups/file1.json": "` + phrase1 + `", "backups/file2.json": "` + phrase2 + `"}`, ""},
+		{"JSON By Base Name", `{"file2.json": "` + phrase2 + `", "file1.json": "` + phrase1 + `"}`, ""},
+		{"Too Few Lines", phrase1 + "\n", "got 1 phrases for 2 files"},
+		{"JSON Missing File", `{"file1.json": "` + phrase1 + `"}`, "no phrase for `backups/file2.json`"},
+		{"Short Phrase", phrase1 + "\nabandon abandon\n", "wanted 12, 18 or 24 phrase words but got 2 for `backups/file2.json`"},
+		{"Bad Checksum", badChecksum + "\n" + phrase2 + "\n", "invalid phrase for `backups/file1.json`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "mnemonics.txt")
+			if !assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600)) {
+				return
+			}
+			files, err := ReadMnemonicsFile(path, filenames)
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.wantErr)
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, []VaultsDataFile{
+				{File: "backups/file1.json", Mnemonics: phrase1},
+				{File: "backups/file2.json", Mnemonics: phrase2},
+			}, *files)
+		})
+	}
+}
+
+func TestReadMnemonics(t *testing.T) {
+	phrase1 := strings.Repeat("abandon ", 23) + "art"
+	phrase2 := strings.Repeat("zoo ", 23) + "vote"
+	filenames := []string{"file1.json", "file2.json"}
+
+	files, err := ReadMnemonics(strings.NewReader("  "+phrase1+"\t\n"+phrase2), filenames)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []VaultsDataFile{
+		{File: "file1.json", Mnemonics: phrase1},
+		{File: "file2.json", Mnemonics: phrase2},
+	}, *files)
+
+	_, err = ReadMnemonics(strings.NewReader(phrase1+"\n"+phrase2+"\n"+phrase1+"\n"), filenames)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "got 3 phrases for 2 files")
+	}
+}
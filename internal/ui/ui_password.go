@@ -0,0 +1,56 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	errors2 "github.com/pkg/errors"
+	"golang.org/x/term"
+)
+
+// passwordAttempts is how many times the password may be entered before the prompt gives up on a mismatch.
+const passwordAttempts = 3
+
+// PromptPassword asks on the terminal for the password of the wallet v3 file, without echoing it, and asks again to confirm it.
+// An empty password means that no wallet v3 file is to be exported.
+func PromptPassword(in *os.File, out io.Writer, filename string) (string, error) {
+	return promptPassword(func() ([]byte, error) {
+		password, err := term.ReadPassword(int(in.Fd()))
+		// the newline that ended the input was not echoed either
+		fmt.Fprintln(out)
+		return password, err
+	}, out, filename)
+}
+
+func promptPassword(read func() ([]byte, error), out io.Writer, filename string) (string, error) {
+	for range passwordAttempts {
+		fmt.Fprintf(out, "Password for the wallet v3 file `%s` (leave empty to skip the export): ", filename)
+		password, err := read()
+		if err != nil {
+			return "", errors2.Wrapf(err, "⚠ unable to read the password")
+		}
+		if len(password) == 0 {
+			return "", nil
+		}
+		fmt.Fprint(out, "Repeat the password: ")
+		confirmation, err := read()
+		if err != nil {
+			clear(password)
+			return "", errors2.Wrapf(err, "⚠ unable to read the password")
+		}
+		matches := string(password) == string(confirmation)
+		clear(confirmation)
+		if matches {
+			defer clear(password)
+			return string(password), nil
+		}
+		clear(password)
+		fmt.Fprintln(out, "⚠ The passwords do not match, try again.")
+	}
+	return "", errors2.Errorf("⚠ the passwords did not match %d times", passwordAttempts)
+}
@@ -0,0 +1,51 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package ui
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromptPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		inputs   []string
+		expected string
+		wantErr  string
+	}{
+		{"Confirmed", []string{"hunter2", "hunter2"}, "hunter2", ""},
+		{"Skipped", []string{""}, "", ""},
+		{"Retried", []string{"hunter2", "hunter3", "hunter2", "hunter2"}, "hunter2", ""},
+		{"Mismatch", []string{"a", "b", "a", "b", "a", "b"}, "", "did not match 3 times"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputs := tt.inputs
+			read := func() ([]byte, error) {
+				if len(inputs) == 0 {
+					return nil, io.EOF
+				}
+				input := inputs[0]
+				inputs = inputs[1:]
+				return []byte(input), nil
+			}
+			password, err := promptPassword(read, io.Discard, "wallet.json")
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.wantErr)
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expected, password)
+			assert.Empty(t, inputs)
+		})
+	}
+}
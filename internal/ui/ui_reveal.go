@@ -0,0 +1,89 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+)
+
+// clearScreen moves the cursor home and clears the screen and the scrollback buffer.
+const clearScreen = "\033[H\033[2J\033[3J"
+
+// IsInteractive reports whether stdin and stdout are attached to a terminal.
+func IsInteractive() bool {
+	return StdinIsTerminal() && isTerminal(os.Stdout)
+}
+
+// StdinIsTerminal reports whether stdin is attached to a terminal, rather than e.g. a pipe.
+func StdinIsTerminal() bool {
+	return isTerminal(os.Stdin)
+}
+
+// IsTerminal reports whether w is a file attached to a terminal.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && isTerminal(f)
+}
+
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// WaitForReveal counts down from delay seconds and then waits for Enter,
+// giving the user time to make sure nobody else can see the screen.
+func WaitForReveal(in io.Reader, out io.Writer, delay time.Duration) error {
+	fmt.Fprintf(out, "\nThe private keys are about to be shown. Make sure nobody is watching or recording your screen.\n")
+	for left := delay; left > 0; left -= time.Second {
+		fmt.Fprintf(out, "\rRevealing in %2d s…", int(left/time.Second))
+		time.Sleep(min(left, time.Second))
+	}
+	fmt.Fprintf(out, "\rPress Enter to reveal the keys.")
+	return waitForEnter(in)
+}
+
+// WaitAndClearScreen waits for Enter and then clears the screen, including the scrollback.
+func WaitAndClearScreen(in io.Reader, out io.Writer) error {
+	fmt.Fprintf(out, "\nPress Enter to clear the screen.")
+	if err := waitForEnter(in); err != nil {
+		return err
+	}
+	fmt.Fprint(out, clearScreen)
+	return nil
+}
+
+// ClearAfter counts down from delay seconds and then clears the screen, including the scrollback.
+func ClearAfter(out io.Writer, delay time.Duration) {
+	fmt.Fprintln(out)
+	for left := delay; left > 0; left -= time.Second {
+		fmt.Fprintf(out, "\rClearing the keys from the screen in %2d s…", int(left/time.Second))
+		time.Sleep(min(left, time.Second))
+	}
+	fmt.Fprint(out, clearScreen)
+}
+
+// Confirm asks a yes or no question, where anything but y or yes is a no.
+func Confirm(in io.Reader, out io.Writer, question string) (bool, error) {
+	fmt.Fprintf(out, "\n%s [y/N] ", question)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+func waitForEnter(in io.Reader) error {
+	if _, err := bufio.NewReader(in).ReadString('\n'); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{"Yes", "y\n", true},
+		{"Yes In Full", " YES \n", true},
+		{"No", "n\n", false},
+		{"Empty Is No", "\n", false},
+		{"End Of Input Is No", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			confirmed, err := Confirm(strings.NewReader(tt.input), &out, "Show it?")
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expected, confirmed)
+			assert.Contains(t, out.String(), "Show it? [y/N] ")
+		})
+	}
+}
+
+func TestClearAfter(t *testing.T) {
+	var out bytes.Buffer
+	ClearAfter(&out, time.Second)
+	assert.Contains(t, out.String(), "Clearing the keys from the screen in  1 s…")
+	assert.True(t, strings.HasSuffix(out.String(), clearScreen))
+}
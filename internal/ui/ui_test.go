@@ -0,0 +1,36 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWantsColor(t *testing.T) {
+	// a buffer, like a pipe or a file, is not a terminal
+	assert.False(t, WantsColor(&bytes.Buffer{}))
+
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+	assert.False(t, WantsColor(f))
+
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, WantsColor(os.Stdout))
+}
+
+func TestErrorBox_NoColor(t *testing.T) {
+	colorOff = true
+	defer func() { colorOff = false }()
+	assert.Equal(t, "\nError: bad.\n\n", ErrorBox(errors.New("bad")))
+	assert.NotContains(t, Banner(), "\033")
+}
@@ -5,31 +5,161 @@
 package ui
 
 import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/config"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/data"
+	"github.com/charmbracelet/lipgloss"
 	errors2 "github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
 )
 
-func (v VaultsDataFile) ValidateMnemonics() error {
-	phrase := cleanMnemonicInput(v.Mnemonics)
-	words := strings.Split(phrase, " ")
-	if len(words) != WORDS {
-		return errors2.Errorf("⚠ wanted %d phrase words but got %d", WORDS, len(words))
+// ExpandFiles expands the input files into the backup files to read, reporting to out how many were found:
+//   - a directory stands for the backup files (*.json and *.json.gz) in it,
+//   - a glob pattern, such as backups/party*.json, for the backup files it matches,
+//   - a zip archive for the JSON files in it, in archive order. Their paths name the archive and the entry,
+//     as in backups.zip!/party1.json, so an error names the entry it is about.
+//
+// JSON files found in a directory or by a pattern that are not backup files, e.g. an exported wallet v3 file, are skipped.
+// Any other path is kept as is.
+func ExpandFiles(files []string, out io.Writer) ([]string, error) {
+	expanded := make([]string, 0, len(files))
+	for _, file := range files {
+		var found []string
+		skipped := 0
+		info, statErr := os.Stat(file)
+		switch {
+		case statErr == nil && info.IsDir():
+			entries, err := os.ReadDir(file)
+			if err != nil {
+				return nil, errors2.Errorf("⚠ unable to read folder `%s`: %s", file, err)
+			}
+			for _, entry := range entries {
+				if name := strings.ToLower(entry.Name()); !entry.IsDir() && (strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".json.gz")) {
+					found, skipped = appendIfBackup(found, skipped, filepath.Join(file, entry.Name()))
+				}
+			}
+		case statErr != nil && strings.ContainsAny(file, "*?["):
+			matches, err := filepath.Glob(file)
+			if err != nil {
+				return nil, errors2.Errorf("⚠ invalid file pattern `%s`: %s", file, err)
+			}
+			for _, match := range matches {
+				if info, err := os.Stat(match); err != nil || info.IsDir() {
+					continue
+				}
+				if data.IsZipFile(match) {
+					entries, err := data.ZipEntries(match)
+					if err != nil {
+						return nil, errors2.Errorf("⚠ %s", err)
+					}
+					found = append(found, entries...)
+					continue
+				}
+				found, skipped = appendIfBackup(found, skipped, match)
+			}
+		case data.IsZipFile(file):
+			entries, err := data.ZipEntries(file)
+			if err != nil {
+				return nil, errors2.Errorf("⚠ %s", err)
+			}
+			found = entries
+		default:
+			expanded = append(expanded, file)
+			continue
+		}
+		if len(found) == 0 {
+			return nil, errors2.Errorf("⚠ no backup files found in `%s`", file)
+		}
+		msg := fmt.Sprintf("Found %d backup file(s) in `%s`", len(found), file)
+		if skipped > 0 {
+			msg += fmt.Sprintf(", skipped %d other JSON file(s)", skipped)
+		}
+		fmt.Fprintln(out, msg+".")
+		expanded = append(expanded, found...)
 	}
-	return nil
+	return expanded, nil
+}
+
+// appendIfBackup appends the file to found if it is a backup file, and counts it as skipped otherwise.
+func appendIfBackup(found []string, skipped int, file string) ([]string, int) {
+	if !isBackupFile(file) {
+		return found, skipped + 1
+	}
+	return append(found, file), skipped
+}
+
+// isBackupFile reports whether the file is a JSON object with vaults, as every backup file is. The file is read as a stream,
+// and only up to its vaults, as a large backup file is checked in full when it is read for the recovery.
+func isBackupFile(file string) bool {
+	reader, err := data.OpenBackupFile(file)
+	if err != nil {
+		return false
+	}
+	defer reader.Close()
+	dec := json.NewDecoder(reader)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return false
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		if name, _ := key.(string); strings.EqualFold(name, "vaults") {
+			return true
+		}
+		if err = dec.Decode(new(json.RawMessage)); err != nil {
+			return false
+		}
+	}
+	return false
 }
 
-func ValidateFiles(appConfig config.AppConfig) error {
+// firstByte reads the first byte of a backup file, after it is decompressed, without reading the rest of it.
+// It is empty for an empty file.
+func firstByte(file string) ([]byte, error) {
+	reader, err := data.OpenBackupFile(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	first := make([]byte, 1)
+	n, err := io.ReadFull(reader, first)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return first[:n], nil
+}
+
+// ValidateFiles checks that the input files exist, are unique and hold JSON, and prints their SHA-256 hashes to out,
+// so that they can be recorded. With -verify-hashes, the hashes must match those of the manifest.
+func ValidateFiles(appConfig config.AppConfig, out io.Writer) error {
 	files := appConfig.Filenames
+	var manifest map[string]string
+	if appConfig.VerifyHashes != "" {
+		var err error
+		if manifest, err = readHashManifest(appConfig.VerifyHashes); err != nil {
+			return err
+		}
+	}
 
 	// Make sure all files exist, and ensure they're unique
 	{
 		uniqueFiles := make(map[string]struct{})
 		for _, file := range files {
-			// read file and basic validate
-			if _, err := os.Stat(file); err != nil {
+			// read file and basic validate; for an entry of a zip archive, the archive must exist
+			archive, _, _ := data.SplitZipPath(file)
+			if _, err := os.Stat(archive); err != nil {
 				return errors2.Errorf("⚠ unable to see file `%s` - does it exist?: %s", file, err)
 			}
 			if _, ok := uniqueFiles[file]; ok {
@@ -39,27 +169,160 @@ func ValidateFiles(appConfig config.AppConfig) error {
 		}
 	}
 
-	for _, file := range files {
+	for i, file := range files {
 		// read file and basic validate
-		if _, err := os.Stat(file); err != nil {
+		archive, _, _ := data.SplitZipPath(file)
+		if _, err := os.Stat(archive); err != nil {
 			return errors2.Errorf("unable to see file `%s` - does it exist?: %s", file, err)
 		}
 		// fmt.Print("Reading file ", file, " ... ")
 
-		content, err := os.ReadFile(file)
+		sum, err := data.FileSHA256(file)
+		if err != nil {
+			return errors2.Errorf("unable to read file `%s`: %s", file, err)
+		}
+		if i == 0 {
+			fmt.Fprintln(out, "SHA-256 of the input files:")
+		}
+		fmt.Fprintf(out, "  %s  %s\n", sum, file)
+		if manifest != nil {
+			if err = checkHash(manifest, file, sum); err != nil {
+				return err
+			}
+		}
+
+		first, err := firstByte(file)
 		if err != nil {
 			return errors2.Errorf("unable to read file `%s`: %s", file, err)
 		}
-		if len(content) == 0 || content[0] != '{' {
-			return errors2.Errorf("⚠ invalid file format, expecting json. first char is %s", content[:1])
+		if len(first) == 0 || first[0] != '{' {
+			return errors2.Errorf("⚠ invalid file format, expecting json. first char is %s", first)
 		}
 	}
+	if manifest != nil {
+		fmt.Fprintf(out, "The hashes of all %d input file(s) match `%s`.\n", len(files), appConfig.VerifyHashes)
+	}
+	fmt.Fprintln(out)
+	return nil
+}
+
+// readHashManifest reads a manifest of SHA-256 hashes in the format of sha256sum, i.e. lines of a hash and a file name,
+// as recorded from the output of the tool or by sha256sum itself. Blank lines and # comments are skipped.
+func readHashManifest(file string) (map[string]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors2.Errorf("⚠ unable to read the -verify-hashes manifest: %s", err)
+	}
+	defer f.Close()
+
+	manifest := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		sum, name, ok := strings.Cut(text, " ")
+		// sha256sum marks a file that was read in binary mode with a * before its name
+		name = strings.TrimPrefix(strings.TrimSpace(name), "*")
+		if decoded, err := hex.DecodeString(sum); !ok || name == "" || err != nil || len(decoded) != 32 {
+			return nil, errors2.Errorf("⚠ line %d of the -verify-hashes manifest is not a SHA-256 hash and a file name", line)
+		}
+		manifest[filepath.Clean(name)] = strings.ToLower(sum)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, errors2.Errorf("⚠ unable to read the -verify-hashes manifest: %s", err)
+	}
+	if len(manifest) == 0 {
+		return nil, errors2.Errorf("⚠ the -verify-hashes manifest `%s` lists no files", file)
+	}
+	return manifest, nil
+}
+
+// checkHash checks the hash of a file against the manifest. As the files may have been moved to another machine since they were
+// hashed, a file is looked up by its path as given and then by its base name.
+func checkHash(manifest map[string]string, file, sum string) error {
+	want, ok := manifest[filepath.Clean(file)]
+	if !ok {
+		want, ok = manifest[filepath.Base(file)]
+	}
+	switch {
+	case !ok:
+		return errors2.Errorf("⚠ file `%s` is not in the -verify-hashes manifest, so it can't be checked", file)
+	case want != sum:
+		return errors2.Errorf("⚠ file `%s` has the SHA-256 hash %s, but the -verify-hashes manifest records %s; "+
+			"it was altered or corrupted since the hash was recorded", file, sum, want)
+	}
 	return nil
 }
 
-func cleanMnemonicInput(input string) string {
-	input = strings.Replace(input, "\n", "", -1)
-	input = strings.Replace(input, "\r", "", -1)
-	input = strings.TrimSpace(input)
-	return input
+// normalizeMnemonic lowercases the phrase and separates its words with single spaces, whatever whitespace they were pasted with.
+// It checks the word count, which is any of phraseLengths when wordCount is 0, and that every word is in the BIP39 word list,
+// naming the position of the first unknown word.
+func normalizeMnemonic(input string, wordCount int) (string, error) {
+	words := strings.Fields(strings.ToLower(input))
+	switch {
+	case wordCount > 0 && len(words) != wordCount:
+		return "", errors2.Errorf("⚠ wanted %d phrase words but got %d", wordCount, len(words))
+	case wordCount == 0 && !slices.Contains(phraseLengths, len(words)):
+		return "", errors2.Errorf("⚠ wanted 12, 18 or 24 phrase words but got %d", len(words))
+	}
+	for i, word := range words {
+		if _, ok := bip39.GetWordIndex(word); !ok {
+			return "", errors2.Errorf("⚠ word %d of the phrase is not in the BIP39 word list, check its spelling", i+1)
+		}
+	}
+	return strings.Join(words, " "), nil
+}
+
+// maxSuggestions caps the BIP39 words suggested for a partially typed word.
+const maxSuggestions = 4
+
+var invalidWordStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#D70000", Dark: "#FF5F5F"})
+
+// phraseStatus describes a phrase as it is being typed: the word count, the position of any word
+// that is not in the BIP39 word list, and completions for the word being typed. The count is out of wordCount, unless it is 0.
+func phraseStatus(input string, wordCount int) string {
+	words := strings.Fields(strings.ToLower(input))
+	typing := len(words) > 0 && !strings.ContainsAny(input[len(input)-1:], " \t\r\n")
+
+	var suggestions []string
+	invalid := make([]string, 0, 1)
+	for i, word := range words {
+		if _, ok := bip39.GetWordIndex(word); ok {
+			continue
+		}
+		// the word being typed is only invalid once no word list entry starts with it
+		if typing && i == len(words)-1 {
+			if suggestions = wordsWithPrefix(word); len(suggestions) > 0 {
+				continue
+			}
+		}
+		invalid = append(invalid, fmt.Sprintf("%d", i+1))
+	}
+
+	status := fmt.Sprintf("%d words", len(words))
+	if wordCount > 0 {
+		status = fmt.Sprintf("%d/%d words", len(words), wordCount)
+	}
+	if len(invalid) > 0 {
+		status += " · " + invalidWordStyle.Render(fmt.Sprintf("✗ not in the BIP39 word list: word %s", strings.Join(invalid, ", ")))
+	}
+	if len(suggestions) > 0 {
+		status += " · " + strings.Join(suggestions, " ")
+	}
+	return status
+}
+
+func wordsWithPrefix(prefix string) []string {
+	matches := make([]string, 0, maxSuggestions)
+	for _, word := range bip39.GetWordList() {
+		if strings.HasPrefix(word, prefix) {
+			if len(matches) == maxSuggestions {
+				return append(matches, "…")
+			}
+			matches = append(matches, word)
+		}
+	}
+	return matches
 }
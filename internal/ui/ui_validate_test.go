@@ -0,0 +1,214 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package ui
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeMnemonic(t *testing.T) {
+	phrase := strings.Repeat("abandon ", 23) + "art"
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"Clean", phrase, ""},
+		{"Surrounding Whitespace", "\n  " + phrase + " \r\n", ""},
+		{"Double Spaces And Tabs", strings.ReplaceAll(phrase, " ", " \t "), ""},
+		{"Newline Separated", strings.ReplaceAll(phrase, " ", "\r\n"), ""},
+		{"Mixed Case", strings.ToUpper(phrase[:7]) + phrase[7:], ""},
+		{"Too Few Words", "abandon art", "wanted 24 phrase words but got 2"},
+		{"Unknown Word", strings.Repeat("abandon ", 4) + "abandn " + strings.Repeat("abandon ", 18) + "art", "word 5 of the phrase is not in the BIP39 word list"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := normalizeMnemonic(tt.input, WORDS)
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.wantErr)
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, phrase, result)
+		})
+	}
+}
+
+func TestNormalizeMnemonic_WordCount(t *testing.T) {
+	// BIP39 test vectors for all-zero entropy of 128 and 192 bits
+	phrase12 := strings.Repeat("abandon ", 11) + "about"
+	phrase18 := strings.Repeat("abandon ", 17) + "agent"
+
+	for _, phrase := range []string{phrase12, phrase18, strings.Repeat("abandon ", 23) + "art"} {
+		result, err := normalizeMnemonic(phrase, 0)
+		if assert.NoError(t, err) {
+			assert.Equal(t, phrase, result)
+		}
+	}
+	_, err := normalizeMnemonic(phrase12, 18)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "wanted 18 phrase words but got 12")
+	}
+	_, err = normalizeMnemonic(phrase12+" abandon", 0)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "wanted 12, 18 or 24 phrase words but got 13")
+	}
+}
+
+func TestPhraseStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Empty", "", "0/24 words"},
+		{"Complete Words", "abandon ability ", "2/24 words"},
+		{"Typing A Word", "abandon abil", "2/24 words · ability"},
+		{"Typing A Common Prefix", "abandon ab", "2/24 words · abandon ability able about …"},
+		{"Typo While Typing", "abandon abx", "2/24 words · ✗ not in the BIP39 word list: word 2"},
+		{"Typo In Earlier Words", "abandn ability abilty ", "3/24 words · ✗ not in the BIP39 word list: word 1, 3"},
+		{"Mixed Case", "Abandon ABILITY", "2/24 words"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, phraseStatus(tt.input, WORDS))
+		})
+	}
+	assert.Equal(t, "2 words", phraseStatus("abandon ability", 0))
+}
+
+func TestExpandFiles_Zip(t *testing.T) {
+	content, err := os.ReadFile("../../test-files/new_single.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+	archive := filepath.Join(t.TempDir(), "backups.zip")
+	f, err := os.Create(archive)
+	if !assert.NoError(t, err) {
+		return
+	}
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"party1.json", "README.txt", "__MACOSX/._party1.json", "parties/party2.json"} {
+		w, err := zw.Create(name)
+		if !assert.NoError(t, err) {
+			return
+		}
+		if _, err = w.Write(content); !assert.NoError(t, err) {
+			return
+		}
+	}
+	if !assert.NoError(t, zw.Close()) || !assert.NoError(t, f.Close()) {
+		return
+	}
+
+	files, err := ExpandFiles([]string{"plain.json", archive}, io.Discard)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"plain.json", archive + "!/party1.json", archive + "!/parties/party2.json"}, files)
+	assert.NoError(t, ValidateFiles(config.AppConfig{Filenames: files[1:]}, io.Discard))
+
+	// a missing entry names itself
+	err = ValidateFiles(config.AppConfig{Filenames: []string{archive + "!/party3.json"}}, io.Discard)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "party3.json")
+	}
+}
+
+func TestExpandFiles_FolderAndPattern(t *testing.T) {
+	content, err := os.ReadFile("../../test-files/new_single.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+	dir := t.TempDir()
+	for name, body := range map[string][]byte{
+		"party1.json": content,
+		"party2.json": content,
+		"wallet.json": []byte(`{"address": "7e5f4552091a69125d5dfcb7b8c2659029395bdf", "crypto": {}}`),
+		"notes.txt":   []byte("not a backup"),
+	} {
+		if !assert.NoError(t, os.WriteFile(filepath.Join(dir, name), body, 0o600)) {
+			return
+		}
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+		report   string
+	}{
+		{"Folder", dir, []string{filepath.Join(dir, "party1.json"), filepath.Join(dir, "party2.json")}, "Found 2 backup file(s) in `" + dir + "`, skipped 1 other JSON file(s).\n"},
+		{"Pattern", filepath.Join(dir, "party*.json"), []string{filepath.Join(dir, "party1.json"), filepath.Join(dir, "party2.json")}, "Found 2 backup file(s)"},
+		{"Single Match", filepath.Join(dir, "*1.json"), []string{filepath.Join(dir, "party1.json")}, "Found 1 backup file(s)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			files, err := ExpandFiles([]string{tt.input}, &out)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expected, files)
+			assert.Contains(t, out.String(), tt.report)
+		})
+	}
+
+	_, err = ExpandFiles([]string{filepath.Join(dir, "*.csv")}, io.Discard)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "no backup files found")
+	}
+}
+
+func TestValidateFiles_VerifyHashes(t *testing.T) {
+	const file, sum = "../../test-files/new_single.json", "b17b47f4a1dbd3647615c5538ce91466f87ddc528bc9d96a3b033ff6740a9928"
+	other := strings.Repeat("0", 64)
+
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{"Path As Given", sum + "  " + file + "\n", ""},
+		{"Base Name In Binary Mode", "# recorded on the air-gapped machine\n\n" + strings.ToUpper(sum) + " *new_single.json\n", ""},
+		{"Altered File", other + "  new_single.json\n", "was altered or corrupted"},
+		{"File Not Listed", sum + "  other.json\n", "is not in the -verify-hashes manifest"},
+		{"Not A Manifest", "new_single.json\n", "line 1 of the -verify-hashes manifest"},
+		{"Empty Manifest", "\n# nothing\n", "lists no files"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := filepath.Join(t.TempDir(), "hashes.txt")
+			if !assert.NoError(t, os.WriteFile(manifest, []byte(tt.manifest), 0o600)) {
+				return
+			}
+			out := new(bytes.Buffer)
+			err := ValidateFiles(config.AppConfig{Filenames: []string{file}, VerifyHashes: manifest}, out)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Contains(t, out.String(), "  "+sum+"  "+file+"\n")
+			assert.Contains(t, out.String(), "The hashes of all 1 input file(s) match")
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package version
+
+import (
+	"runtime/debug"
+)
+
+// Version, Commit and Date describe the build of the tool. Commit and Date are set by the Makefile with -ldflags "-X ...",
+// Date to the time of the commit so that the same commit builds the same binary. Without them, they are read from
+// the VCS details that the go command stamps into a build from a git checkout.
+var (
+	Version = "v5.1.4"
+	Commit  = ""
+	Date    = ""
+)
+
+// unknown stands in for a detail that the build does not carry.
+const unknown = "unknown"
+
+// Info returns the commit and build date of the tool, or "unknown" for a detail that the build does not carry.
+func Info() (commit, date string) {
+	commit, date = Commit, Date
+	if info, ok := debug.ReadBuildInfo(); ok && (commit == "" || date == "") {
+		stamped, modified := "", false
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && commit == "":
+				stamped = setting.Value[:min(len(setting.Value), 12)]
+			case setting.Key == "vcs.modified":
+				modified = setting.Value == "true"
+			case setting.Key == "vcs.time" && date == "":
+				date = setting.Value
+			}
+		}
+		if stamped != "" && modified {
+			stamped += "-dirty"
+		}
+		if commit == "" {
+			commit = stamped
+		}
+	}
+	if commit == "" {
+		commit = unknown
+	}
+	if date == "" {
+		date = unknown
+	}
+	return commit, date
+}
@@ -0,0 +1,28 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInfo(t *testing.T) {
+	prevCommit, prevDate := Commit, Date
+	defer func() { Commit, Date = prevCommit, prevDate }()
+
+	// the values set with -ldflags take precedence over the VCS details of the build
+	Commit, Date = "0123456789ab", "2024-05-01T12:00:00Z"
+	commit, date := Info()
+	assert.Equal(t, "0123456789ab", commit)
+	assert.Equal(t, "2024-05-01T12:00:00Z", date)
+
+	// a test binary carries no VCS details
+	Commit, Date = "", ""
+	commit, date = Info()
+	assert.Equal(t, "unknown", commit)
+	assert.Equal(t, "unknown", date)
+}
@@ -9,11 +9,15 @@ import (
 /* Base-58 Encode/Decode */
 /******************************************************************************/
 
-// b58encode encodes a byte slice b into a base-58 encoded string.
-func b58encode(b []byte) (s string) {
-	/* See https://en.bitcoin.it/wiki/Base58Check_encoding */
+// Base-58 alphabets. Ripple's encodes the same digits with other characters, so that its zero digit is r.
+const (
+	bitcoinBase58Table = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	rippleBase58Table  = "rpshnaf39wBUDNEGHJKLM4PQRST7VWXYZ2bcdeCg65jkm8oFqi1tuvAxyz"
+)
 
-	const BITCOIN_BASE58_TABLE = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+// b58encode encodes a byte slice b into a base-58 encoded string, with the digits of the given alphabet.
+func b58encode(b []byte, table string) (s string) {
+	/* See https://en.bitcoin.it/wiki/Base58Check_encoding */
 
 	/* Convert big endian bytes to big int */
 	x := new(big.Int).SetBytes(b)
@@ -29,7 +33,7 @@ func b58encode(b []byte) (s string) {
 		/* x, r = (x / 58, x % 58) */
 		x.QuoRem(x, m, r)
 		/* Prepend ASCII character */
-		s = string(BITCOIN_BASE58_TABLE[r.Int64()]) + s
+		s = string(table[r.Int64()]) + s
 	}
 
 	return s
@@ -39,8 +43,8 @@ func b58encode(b []byte) (s string) {
 /* Base-58 Check Encode/Decode */
 /******************************************************************************/
 
-// b58checkencode encodes version ver and byte slice b into a base-58 check encoded string.
-func b58checkencode(ver uint8, b []byte) (s string) {
+// b58checkencode encodes version ver and byte slice b into a base-58 check encoded string, with the digits of the given alphabet.
+func b58checkencode(ver uint8, b []byte, table string) (s string) {
 	/* Prepend version */
 	bcpy := append([]byte{ver}, b...)
 
@@ -61,15 +65,38 @@ func b58checkencode(ver uint8, b []byte) (s string) {
 	bcpy = append(bcpy, hash2[0:4]...)
 
 	/* Encode base58 string */
-	s = b58encode(bcpy)
+	s = b58encode(bcpy, table)
 
-	/* For number of leading 0's in bytes, prepend 1 */
+	/* For number of leading 0's in bytes, prepend the zero digit, e.g. 1 */
 	for _, v := range bcpy {
 		if v != 0 {
 			break
 		}
-		s = "1" + s
+		s = table[:1] + s
 	}
 
 	return s
 }
+
+// Base58Encode encodes byte slice b into a base-58 string, keeping its leading zero bytes as leading 1's.
+func Base58Encode(b []byte) string {
+	s := b58encode(b, bitcoinBase58Table)
+	for _, v := range b {
+		if v != 0 {
+			break
+		}
+		s = "1" + s
+	}
+	return s
+}
+
+// Base58CheckEncode encodes version ver and byte slice b into a base-58 check encoded string.
+func Base58CheckEncode(ver uint8, b []byte) string {
+	return b58checkencode(ver, b, bitcoinBase58Table)
+}
+
+// RippleBase58CheckEncode encodes version ver and byte slice b into a base-58 check encoded string with Ripple's alphabet,
+// as the XRP Ledger does for its addresses and seeds.
+func RippleBase58CheckEncode(ver uint8, b []byte) string {
+	return b58checkencode(ver, b, rippleBase58Table)
+}
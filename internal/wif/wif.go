@@ -4,16 +4,43 @@
 
 package wif
 
-// ToBitcoinWIF converts a private key to Bitcoin Wallet Import Format (WIF)
-func ToBitcoinWIF(privKey []byte, testNet, compressed bool) string {
+import (
+	"fmt"
+)
+
+// keyLen is the length of a secp256k1 private key, which a WIF always encodes in full.
+const keyLen = 32
+
+// WIF versions of the coins that share Bitcoin's WIF format with a version byte of their own.
+const (
+	BitcoinMainnet = 0x80
+	BitcoinTestnet = 0xef
+	Litecoin       = 0xb0
+	Dogecoin       = 0x9e
+)
+
+// ToBitcoinWIF converts a private key to Bitcoin Wallet Import Format (WIF).
+// A key shorter than 32 bytes, e.g. a scalar with leading zero bytes, is left padded with zeros, as a wallet reads it as 32 bytes.
+func ToBitcoinWIF(privKey []byte, testNet, compressed bool) (string, error) {
+	if testNet {
+		return ToWIF(privKey, BitcoinTestnet, compressed)
+	}
+	return ToWIF(privKey, BitcoinMainnet, compressed)
+}
+
+// ToWIF converts a private key to the Wallet Import Format of a coin, given the coin's WIF version, e.g. Litecoin.
+// It pads a short key like ToBitcoinWIF.
+func ToWIF(privKey []byte, version uint8, compressed bool) (string, error) {
+	if len(privKey) == 0 || len(privKey) > keyLen {
+		return "", fmt.Errorf("a WIF takes a private key of up to %d bytes, but the key has %d bytes", keyLen, len(privKey))
+	}
+	// copy the key, so that the caller's slice is never appended to, and wipe the copy once it is encoded
+	payload := make([]byte, keyLen, keyLen+1)
+	defer clear(payload)
+	copy(payload[keyLen-len(privKey):], privKey)
 	if compressed {
 		// Append 0x01 to tell Bitcoin wallet to use compressed public keys
-		privKey = append(privKey, []byte{0x01}...)
-	}
-	// Convert bytes to base-58 check encoded string with version 0x80 (mainnet) or 0xef (testnet)
-	ver := uint8(0x80)
-	if testNet {
-		ver = 0xef
+		payload = append(payload, 0x01)
 	}
-	return b58checkencode(ver, privKey)
+	return b58checkencode(version, payload, bitcoinBase58Table), nil
 }
@@ -0,0 +1,110 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package wif
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToBitcoinWIF(t *testing.T) {
+	// the key of the WIF examples of the Bitcoin wiki, https://en.bitcoin.it/wiki/Wallet_import_format
+	wikiKey, _ := hex.DecodeString("0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d")
+
+	tests := []struct {
+		name       string
+		key        []byte
+		testNet    bool
+		compressed bool
+		want       string
+	}{
+		{"Mainnet Uncompressed", wikiKey, false, false, "5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTJ"},
+		{"Mainnet Compressed", wikiKey, false, true, "KwdMAjGmerYanjeui5SHS7JkmpZvVipYvB2LJGU1ZxJwYvP98617"},
+		{"Testnet Uncompressed", wikiKey, true, false, "91gGn1HgSap6CbU12F6z3pJri26xzp7Ay1VW6NHCoEayNXwRpu2"},
+		{"Testnet Compressed", wikiKey, true, true, "cMzLdeGd5vEqxB8B6VFQoRopQ3sLAAvEzDAoQgvX54xwofSWj1fx"},
+		// the key 1, given without its 31 leading zero bytes, must encode as the padded 32 byte key
+		{"Mainnet Uncompressed Padded", []byte{0x01}, false, false, "5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf"},
+		{"Mainnet Compressed Padded", []byte{0x01}, false, true, "KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn"},
+		{"Testnet Uncompressed Padded", []byte{0x01}, true, false, "91avARGdfge8E4tZfYLoxeJ5sGBdNJQH4kvjJoQFacbgwmaKkrx"},
+		{"Testnet Compressed Padded", []byte{0x01}, true, true, "cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToBitcoinWIF(tt.key, tt.testNet, tt.compressed)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestToBitcoinWIF_Padding(t *testing.T) {
+	key, _ := hex.DecodeString("00a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f")
+
+	for _, compressed := range []bool{false, true} {
+		padded, err := ToBitcoinWIF(key, false, compressed)
+		if !assert.NoError(t, err) {
+			return
+		}
+		short, err := ToBitcoinWIF(key[1:], false, compressed)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, padded, short)
+	}
+}
+
+func TestToBitcoinWIF_DoesNotAppendToKey(t *testing.T) {
+	buf := make([]byte, 33)
+	buf[31], buf[32] = 0x01, 0xaa
+	key := buf[:32]
+
+	_, err := ToBitcoinWIF(key, false, true)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// the compression flag must not be written past the end of the key, into the caller's buffer
+	assert.Equal(t, byte(0xaa), buf[32])
+}
+
+func TestToBitcoinWIF_BadLength(t *testing.T) {
+	for _, key := range [][]byte{nil, make([]byte, 33)} {
+		_, err := ToBitcoinWIF(key, false, true)
+		assert.Error(t, err)
+	}
+}
+
+func TestRippleBase58CheckEncode(t *testing.T) {
+	// ACCOUNT_ZERO and ACCOUNT_ONE of the XRP Ledger, whose leading zero bytes are r's
+	assert.Equal(t, "rrrrrrrrrrrrrrrrrrrrrhoLvTp", RippleBase58CheckEncode(0x00, make([]byte, 20)))
+	assert.Equal(t, "rrrrrrrrrrrrrrrrrrrrBZbvji", RippleBase58CheckEncode(0x00, append(make([]byte, 19), 0x01)))
+}
+
+func TestToWIF_Litecoin(t *testing.T) {
+	// the WIFs of the key 1, which a Litecoin wallet reads with the version 0xb0
+	got, err := ToWIF([]byte{0x01}, Litecoin, true)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "T33ydQRKp4FCW5LCLLUB7deioUMoveiwekdwUwyfRDeGZm76aUjV", got)
+	}
+	got, err = ToWIF([]byte{0x01}, Litecoin, false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "6u823ozcyt2rjPH8Z2ErsSXJB5PPQwK7VVTwwN4mxLBFrao69XQ", got)
+	}
+}
+
+func TestToWIF_Dogecoin(t *testing.T) {
+	// the WIFs of the key 1, which a Dogecoin wallet reads with the version 0x9e
+	got, err := ToWIF([]byte{0x01}, Dogecoin, true)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "QNcdLVw8fHkixm6NNyN6nVwxKek4u7qrioRbQmjxac5TVoTtZuot", got)
+	}
+	got, err = ToWIF([]byte{0x01}, Dogecoin, false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "6J8csdv3eDrnJcpSEb4shfjMh2JTiG9MKzC1Yfge4Y4GyUsjdM6", got)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+)
+
+// interrupts handles Ctrl-C (and SIGTERM) so that no keys are left in memory when the tool is stopped.
+// While the backup files are being processed, ctx is cancelled and the recovery package wipes the shares it has decoded
+// before returning; at any other time the recovered keys are wiped and the tool exits right away.
+type interrupts struct {
+	ctx    context.Context
+	busy   atomic.Bool
+	result atomic.Pointer[recovery.Result]
+	// clipboard is the value that -clipboard copied, until it is cleared
+	clipboard atomic.Pointer[string]
+}
+
+func handleInterrupts() *interrupts {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := &interrupts{ctx: ctx}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+		// the running step returns the cancellation error, which fails the tool
+		if in.busy.Load() {
+			return
+		}
+		in.exit()
+	}()
+	return in
+}
+
+// run runs a step that processes the backup files and stops once ctx is cancelled. An interrupt that lands just as the step
+// is done is handled once it returns.
+func (in *interrupts) run(step func(ctx context.Context)) {
+	in.busy.Store(true)
+	step(in.ctx)
+	in.busy.Store(false)
+	if in.ctx.Err() != nil {
+		in.exit()
+	}
+}
+
+// exit wipes the recovered keys, if any, and exits with the status of an interrupted process.
+func (in *interrupts) exit() {
+	if result := in.result.Load(); result != nil {
+		result.Wipe()
+	}
+	if value := in.clipboard.Load(); value != nil {
+		_ = clearClipboard(*value)
+	}
+	fmt.Fprintln(errOut, "\n⚠ Interrupted. Any recovered keys were cleared from memory.")
+	os.Exit(130)
+}
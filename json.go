@@ -0,0 +1,282 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/ui"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+)
+
+type (
+	// recoveryJSON is the -json output of a recovered vault. The private keys are left out in -verify mode, but not the public keys.
+	recoveryJSON struct {
+		VaultID            string         `json:"vaultId"`
+		Name               string         `json:"name"`
+		EthereumAddress    string         `json:"ethereumAddress,omitempty"`
+		ECDSAPublicKey     string         `json:"ecdsaPublicKey,omitempty"`
+		CosmosAddress      string         `json:"cosmosAddress,omitempty"`
+		LitecoinAddress    string         `json:"litecoinAddress,omitempty"`
+		LitecoinLegacy     string         `json:"litecoinLegacyAddress,omitempty"`
+		DogecoinAddress    string         `json:"dogecoinAddress,omitempty"`
+		XRPAddress         string         `json:"xrpAddress,omitempty"`
+		PrivateKey         string         `json:"privateKey,omitempty"`
+		PrivateKeyMnemonic string         `json:"privateKeyMnemonic,omitempty"`
+		MainnetWIF         string         `json:"mainnetWif,omitempty"`
+		TestnetWIF         string         `json:"testnetWif,omitempty"`
+		LitecoinWIF        string         `json:"litecoinWif,omitempty"`
+		DogecoinWIF        string         `json:"dogecoinWif,omitempty"`
+		EdDSAPrivateKey    string         `json:"eddsaPrivateKey,omitempty"`
+		EdDSAPublicKey     string         `json:"eddsaPublicKey,omitempty"`
+		Addresses          []addressJSON  `json:"addresses,omitempty"`
+		SignedMessage      *signedMessage `json:"signedMessage,omitempty"`
+		Balances           []balanceJSON  `json:"balances,omitempty"`
+		Sweep              *sweepTx       `json:"sweep,omitempty"`
+		ExportedFiles      []string       `json:"exportedFiles"`
+		Warnings           []string       `json:"warnings,omitempty"`
+	}
+
+	// addressJSON is an address of the -addresses-only table.
+	addressJSON struct {
+		Chain   string `json:"chain"`
+		Label   string `json:"label"`
+		Address string `json:"address"`
+	}
+
+	// vaultJSON is a vault of the -json vault list.
+	vaultJSON struct {
+		VaultID       string   `json:"vaultId"`
+		Name          string   `json:"name"`
+		Quorum        int      `json:"quorum"`
+		Shares        int      `json:"shares"`
+		ReshareNonces []int    `json:"reshareNonces"`
+		Files         []string `json:"files,omitempty"`
+	}
+
+	// shareJSON is a share of the -show-shares list.
+	shareJSON struct {
+		File       string `json:"file"`
+		Nonce      int    `json:"nonce"`
+		Curve      string `json:"curve"`
+		ShareID    string `json:"shareId"`
+		PartyIndex int    `json:"partyIndex,omitempty"`
+		Parties    int    `json:"parties,omitempty"`
+		Duplicate  bool   `json:"duplicate"`
+	}
+
+	shareListJSON struct {
+		VaultID   string      `json:"vaultId"`
+		Name      string      `json:"name"`
+		Threshold int         `json:"threshold"`
+		Shares    []shareJSON `json:"shares"`
+		Skipped   []string    `json:"skipped,omitempty"`
+	}
+
+	vaultListJSON struct {
+		Vaults []vaultJSON `json:"vaults"`
+	}
+
+	// recoveredListJSON is the -json output of several recovered vaults, e.g. with -all.
+	recoveredListJSON struct {
+		Recovered []recoveryJSON `json:"recovered"`
+	}
+
+	// selfTestJSON is the -json output of a passed -self-test, with the version of the build that passed it.
+	selfTestJSON struct {
+		versionJSON
+		Pass            bool                `json:"pass"`
+		VaultID         string              `json:"vaultId"`
+		EthereumAddress string              `json:"ethereumAddress"`
+		SolanaAddress   string              `json:"solanaAddress"`
+		Checks          []selfTestCheckJSON `json:"checks"`
+	}
+
+	// selfTestCheckJSON is one check of -self-test, of an address of the sample vault against its known one.
+	selfTestCheckJSON struct {
+		Check    string `json:"check"`
+		Expected string `json:"expected"`
+		Actual   string `json:"actual"`
+		Pass     bool   `json:"pass"`
+	}
+
+	// versionJSON is the -json output of -version.
+	versionJSON struct {
+		Version   string `json:"version"`
+		Commit    string `json:"commit"`
+		BuildDate string `json:"buildDate"`
+	}
+
+	// errorJSON is the -json output of an error. Kind names the kind of a recovery error, see exitCodes.
+	errorJSON struct {
+		Error string `json:"error"`
+		Kind  string `json:"kind,omitempty"`
+	}
+)
+
+// exitCodes are the exit statuses of the kinds of recovery errors, and their names in the -json output.
+// Any other error exits with status 1, and a panic with status 70.
+var exitCodes = []struct {
+	kind error
+	code int
+	name string
+}{
+	{recovery.ErrInvalidInput, 2, "invalidInput"},
+	{recovery.ErrWrongMnemonic, 3, "wrongMnemonic"},
+	{recovery.ErrInsufficientShares, 4, "insufficientShares"},
+	{recovery.ErrPubKeyMismatch, 5, "pubKeyMismatch"},
+	{recovery.ErrBadFormat, 6, "badFormat"},
+}
+
+// errorKind returns the exit status of err and the name of its kind, or 1 and an empty name for an error of no known kind.
+func errorKind(err error) (code int, name string) {
+	for _, kind := range exitCodes {
+		if errors.Is(err, kind.kind) {
+			return kind.code, kind.name
+		}
+	}
+	return 1, ""
+}
+
+// newRecoveryJSON describes a recovered vault. Only the WIF of the given network is included, or those of both networks if none is given,
+// and with wifOnly set, only the WIFs are. Without withKeys, e.g. in -verify mode, only the vault, its address and public keys are described.
+func newRecoveryJSON(result *recovery.Result, network string, wifOnly, withKeys bool, warnings []recovery.Warning) recoveryJSON {
+	out := recoveryJSON{
+		VaultID:       result.VaultID,
+		Name:          result.Name,
+		ExportedFiles: make([]string, 0, 1),
+	}
+	for _, w := range warnings {
+		out.Warnings = append(out.Warnings, recovery.Redact(w.Message))
+	}
+	if !wifOnly {
+		out.EthereumAddress = result.Address
+		out.CosmosAddress = result.Chains.Cosmos
+		out.LitecoinAddress, out.LitecoinLegacy = result.Chains.Litecoin.Address, result.Chains.Litecoin.LegacyAddress
+		out.DogecoinAddress = result.Chains.Dogecoin.Address
+		out.XRPAddress = result.Chains.XRP
+		out.ECDSAPublicKey = result.Chains.ECDSAPublicKey
+		out.EdDSAPublicKey = result.Chains.EdDSAPublicKey
+	}
+	if !withKeys {
+		return out
+	}
+	if !wifOnly {
+		out.PrivateKey = hex.EncodeToString(result.ECDSAKey)
+		out.LitecoinWIF = result.Chains.Litecoin.WIF
+		out.DogecoinWIF = result.Chains.Dogecoin.WIF
+	}
+	if network != networkTestnet {
+		out.MainnetWIF = result.Chains.BitcoinMainnet.WIF
+	}
+	if network != networkMainnet {
+		out.TestnetWIF = result.Chains.BitcoinTestnet.WIF
+	}
+	if result.EdDSAKey != nil && !wifOnly {
+		out.EdDSAPrivateKey = hex.EncodeToString(result.EdDSAKey)
+	}
+	return out
+}
+
+// selectChains leaves out the addresses and keys of the chains that -only-chains does not name, if it names any, like selectSections.
+// The private key is kept for any of the coins that it is imported into, and the ECDSA public key is always kept.
+func (out *recoveryJSON) selectChains(coins []string) {
+	if len(coins) == 0 {
+		return
+	}
+	has := func(coin string) bool { return slices.Contains(coins, coin) }
+	if !has(coinEthereum) {
+		out.EthereumAddress = ""
+	}
+	if !has(coinCosmos) {
+		out.CosmosAddress = ""
+	}
+	if !has(coinEthereum) && !has(coinTron) && !has(coinCosmos) && !has(recovery.CoinXRP) {
+		out.PrivateKey = ""
+	}
+	if !has(coinBitcoin) {
+		out.MainnetWIF, out.TestnetWIF = "", ""
+	}
+	if !has(coinSolana) {
+		out.EdDSAPrivateKey, out.EdDSAPublicKey = "", ""
+	}
+	if !has(recovery.CoinLitecoin) {
+		out.LitecoinAddress, out.LitecoinLegacy, out.LitecoinWIF = "", "", ""
+	}
+	if !has(recovery.CoinDogecoin) {
+		out.DogecoinAddress, out.DogecoinWIF = "", ""
+	}
+	if !has(recovery.CoinXRP) {
+		out.XRPAddress = ""
+	}
+}
+
+func newAddressesJSON(sections []outputSection) []addressJSON {
+	addresses := make([]addressJSON, 0, 12)
+	for _, section := range sections {
+		for _, field := range section.Fields {
+			addresses = append(addresses, addressJSON{Chain: section.Title, Label: field.Label, Address: field.Value})
+		}
+	}
+	return addresses
+}
+
+func newVaultListJSON(vaults []ui.VaultPickerItem) vaultListJSON {
+	list := vaultListJSON{Vaults: make([]vaultJSON, 0, len(vaults))}
+	for _, vault := range vaults {
+		list.Vaults = append(list.Vaults, vaultJSON{VaultID: vault.VaultID, Name: vault.Name, Quorum: vault.Quorum, Shares: vault.NumberOfShares,
+			ReshareNonces: vault.ReShareNonces, Files: vault.Files})
+	}
+	return list
+}
+
+func newShareListJSON(vault *recovery.VaultShares) shareListJSON {
+	list := shareListJSON{VaultID: vault.VaultID, Name: vault.Name, Threshold: vault.Threshold, Shares: make([]shareJSON, 0, len(vault.Shares)),
+		Skipped: vault.Skipped}
+	for _, share := range vault.Shares {
+		list.Shares = append(list.Shares, shareJSON{File: share.File, Nonce: share.Nonce, Curve: share.Curve, ShareID: share.ShareID,
+			PartyIndex: share.PartyIndex, Parties: share.Parties, Duplicate: share.Duplicate})
+	}
+	return list
+}
+
+// writeJSON outputs v as an indented JSON object on stdout.
+func writeJSON(v any) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(dataOut, string(out))
+	return err
+}
+
+// exitWithError prints the error and exits with the status of its kind, see exitCodes. In -json mode, the error is output
+// on stdout as {"error": "...", "kind": "..."}. Any key material in the error is redacted first.
+func exitWithError(err error, jsonMode bool) {
+	msg := recovery.Redact(err.Error())
+	code, kind := errorKind(err)
+	if jsonMode {
+		_ = writeJSON(errorJSON{Error: strings.TrimPrefix(msg, "⚠ "), Kind: kind})
+	} else {
+		fmt.Fprintln(errOut, ui.ErrorBox(errors.New(msg)))
+	}
+	os.Exit(code)
+}
+
+// exitOnPanic reports a panic of the main goroutine with its stack and exits, instead of the runtime's report,
+// so that any key material in the panic value is redacted like in any other error.
+func exitOnPanic() {
+	if r := recover(); r != nil {
+		fmt.Fprintf(errOut, "⚠ unexpected error: %s\n\n%s", recovery.Redact(fmt.Sprint(r)), debug.Stack())
+		os.Exit(70)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/ui"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRecoveryJSON(t *testing.T) {
+	result := testResult(t, "0000000000000000000000000000000000000000000000000000000000000001", "")
+	address, publicKey := "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	mainnetWIF, testnetWIF := "KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn", "cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA"
+
+	tests := []struct {
+		name     string
+		withKeys bool
+		network  string
+		wifOnly  bool
+		expected recoveryJSON
+	}{
+		{
+			name:     "all",
+			withKeys: true,
+			expected: recoveryJSON{VaultID: "v1", Name: "A", EthereumAddress: address, ECDSAPublicKey: publicKey,
+				PrivateKey: "0000000000000000000000000000000000000000000000000000000000000001", MainnetWIF: mainnetWIF, TestnetWIF: testnetWIF},
+		},
+		{
+			name:     "wif only, mainnet",
+			withKeys: true,
+			network:  networkMainnet,
+			wifOnly:  true,
+			expected: recoveryJSON{VaultID: "v1", Name: "A", MainnetWIF: mainnetWIF},
+		},
+		{
+			name:     "verify",
+			expected: recoveryJSON{VaultID: "v1", Name: "A", EthereumAddress: address, ECDSAPublicKey: publicKey},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := newRecoveryJSON(result, tt.network, tt.wifOnly, tt.withKeys, nil)
+			tt.expected.ExportedFiles = []string{}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestRecoveryJSON_SelectChains(t *testing.T) {
+	result := testResult(t, "0000000000000000000000000000000000000000000000000000000000000001", "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44")
+	out := newRecoveryJSON(result, networkMainnet, false, true, nil)
+	out.selectChains([]string{coinBitcoin, coinTron})
+	assert.Equal(t, recoveryJSON{VaultID: "v1", Name: "A", ECDSAPublicKey: "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		PrivateKey: "0000000000000000000000000000000000000000000000000000000000000001", MainnetWIF: "KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn",
+		ExportedFiles: []string{}}, out)
+
+	// without -only-chains, nothing is left out
+	out = newRecoveryJSON(result, networkMainnet, false, true, nil)
+	out.selectChains(nil)
+	assert.NotEmpty(t, out.EthereumAddress)
+	assert.NotEmpty(t, out.EdDSAPrivateKey)
+}
+
+func TestNewRecoveryJSON_EdDSAPublicKey(t *testing.T) {
+	result := testResult(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44")
+
+	// the public keys are not secret, so they are part of the -verify output too
+	verified := newRecoveryJSON(result, "", false, false, nil)
+	assert.Equal(t, "fdae759228c8a6fcd37a5c3dc20d23e6d058795e5724eafd2b658a97c0edf0d9", verified.EdDSAPublicKey)
+	assert.Len(t, verified.ECDSAPublicKey, 66)
+	assert.Empty(t, verified.EdDSAPrivateKey)
+}
+
+func TestNewVaultListJSON(t *testing.T) {
+	list := newVaultListJSON([]ui.VaultPickerItem{{VaultID: "v1", Name: "A", Quorum: 2, NumberOfShares: 3, ReShareNonces: []int{0, 1}}})
+	out, err := json.Marshal(list)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.JSONEq(t, `{"vaults": [{"vaultId": "v1", "name": "A", "quorum": 2, "shares": 3, "reshareNonces": [0, 1]}]}`, string(out))
+}
+
+func TestWriteJSON_DataOut(t *testing.T) {
+	var data, log bytes.Buffer
+	prevData, prevLog := dataOut, logOut
+	dataOut, logOut = &data, &log
+	defer func() { dataOut, logOut = prevData, prevLog }()
+
+	if !assert.NoError(t, writeJSON(errorJSON{Error: "x"})) {
+		return
+	}
+	printWarnings(logOut, []recovery.Warning{{Message: "careful"}})
+	assert.JSONEq(t, `{"error": "x"}`, data.String())
+	assert.Contains(t, log.String(), "careful")
+}
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+		kind string
+	}{
+		{"Invalid Flag", recovery.WithKind(recovery.ErrInvalidInput, errors.New("-qr-private only works together with -qr")), 2, "invalidInput"},
+		{"Wrong Phrase", fmt.Errorf("failed: %w", recovery.ErrWrongMnemonic), 3, "wrongMnemonic"},
+		{"Not Enough Shares", recovery.ErrInsufficientShares, 4, "insufficientShares"},
+		{"Public Key Mismatch", recovery.ErrPubKeyMismatch, 5, "pubKeyMismatch"},
+		{"Bad Format", recovery.ErrBadFormat, 6, "badFormat"},
+		{"Other", errors.New("failed to run form"), 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, kind := errorKind(tt.err)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.kind, kind)
+		})
+	}
+}
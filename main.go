@@ -5,159 +5,938 @@
 package main
 
 import (
-	"encoding/hex"
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/bech32"
 	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/config"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/secmem"
 	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/ui"
-	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/wif"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/version"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/decred/dcrd/dcrec/edwards/v2"
 )
 
 const (
-	v2MagicPrefix = "_V2_"
+	networkMainnet = "mainnet"
+	networkTestnet = "testnet"
 )
 
+// logOut receives the progress and warnings of the tool, and dataOut the recovered data and reports.
+// promptOut receives the forms and prompts, and errOut the errors. In -json mode all but dataOut go to stderr,
+// so that stdout only carries the JSON output. In -quiet mode the log is dropped, and the prompts and errors go to stderr.
+var logOut, dataOut, promptOut, errOut io.Writer = os.Stdout, os.Stdout, os.Stdout, os.Stdout
+
 func main() {
-	vaultID := flag.String("vault-id", "", "(Optional) The vault id to export the keys for.")
-	nonceOverride := flag.Int("nonce", -1, "(Optional) Reshare Nonce override. Try it if the tool advises you to do so.")
+	defer exitOnPanic()
+	var vaultIDs vaultIDsFlag
+	vaultName := flag.String("vault-name", "", "(Optional) The name of the vault to export the keys for, ignoring case. If -vault-id is also given, both must be the same vault.")
+	flag.Var(&vaultIDs, "vault-id", "(Optional) The vault id to export the keys for. An id prefix, the vault name or its number in the vault list also work. Repeat it to recover several vaults.")
+	allVaults := flag.Bool("all", false, "(Optional) Recover every vault in the files, one after the other. Use -output-dir to export their wallet v3 files.")
+	outputDir := flag.String("output-dir", "", "(Optional) Folder to export the wallet v3 file of each recovered vault to, named after the vault, instead of -export.")
+	force := flag.Bool("force", false, "(Optional) Overwrite an existing wallet v3 file at -export or in -output-dir, or an existing -qr-file or -qr-private-file.")
+	nonceOverride := flag.Int("nonce", -1, "(Optional) Reshare Nonce override. Try it if the tool advises you to do so. Only the shares at this nonce are used, and every file must hold some.")
 	quorumOverride := flag.Int("threshold", 0, "(Optional) Vault Quorum (Threshold) override. Try it if the tool advises you to do so.")
-	passwordForKS := flag.String("password", "", "(Optional) Encryption password for the Ethereum wallet v3 file; use with -export")
-	exportKSFile := flag.String("export", "wallet.json", "(Optional) Filename to export a Ethereum wallet v3 JSON to; use with -password.")
+	autoThreshold := flag.Bool("auto-threshold", false, "(Optional) If the vault's public key is not recovered with its stored threshold, try every threshold up to the number of shares and report the one that works.")
+	passwordForKS := flag.String("password", "", "(Optional) Encryption password for the Ethereum wallet v3 file; use with -export. Visible in the shell history, prefer -password-file, the "+passwordEnvVar+" environment variable or the prompt.")
+	passwordFile := flag.String("password-file", "", "(Optional) File with the encryption password for the Ethereum wallet v3 file, instead of -password.")
+	exportKSFile := flag.String("export", "wallet.json", "(Optional) Filename to export a Ethereum wallet v3 JSON to. The password is asked for if it is not otherwise given.")
+	verifyAgainst := flag.String("verify-against", "", "(Optional) Wallet v3 file from a prior recovery to check the recovered key against.")
+	verifyPassword := flag.String("verify-against-password", "", "(Optional) Password of the -verify-against wallet v3 file. Defaults to -password.")
+	scryptPreset := flag.String("scrypt", scryptStandard, "(Optional) Key derivation strength for the wallet v3 file: standard (needs ~256 MB of memory), light (for low memory machines) or custom (see -scrypt-n and -scrypt-p).")
+	customScryptN := flag.Int("scrypt-n", 0, "(Optional) scrypt N (CPU/memory cost) of the wallet v3 file, a power of two; use with -scrypt custom. Needs about N KB of memory.")
+	customScryptP := flag.Int("scrypt-p", 0, "(Optional) scrypt P (parallelization) of the wallet v3 file, from 1 to 16; use with -scrypt custom.")
+	wifOnly := flag.Bool("wif-only", false, "(Optional) Only output the Bitcoin WIF(s) after recovery. Skips other chains and the wallet v3 export.")
+	network := flag.String("network", "", "(Optional) Bitcoin network to output the WIF for: mainnet or testnet. Both are shown by default.")
+	wifCompressed := flag.Bool("wif-compressed", true, "(Optional) Output the WIFs for the compressed public key. Set -wif-compressed=false for older wallets that expect an uncompressed WIF; the legacy Bitcoin address is shown then.")
+	addressesOnly := flag.Bool("addresses-only", false, "(Optional) Recover the vault and show all of its addresses on every supported chain, without any private keys, and export no wallet v3 file.")
+	asMnemonic := flag.Bool("as-mnemonic", false, "(Optional) Also output the Ethereum private key as a 24-word BIP39 phrase, for wallets that import a phrase as raw key entropy. This is not an HD wallet seed.")
+	showQR := flag.Bool("qr", false, "(Optional) Also show the Ethereum address as a QR code, or the Bitcoin addresses with -wif-only, to scan into a phone or signing device.")
+	showQRPrivate := flag.Bool("qr-private", false, "(Optional) With -qr, also show the private key and WIFs as QR codes, once confirmed on the terminal.")
+	qrFile := flag.String("qr-file", "", "(Optional) Write the Ethereum address as a PNG QR code to this file, or the Bitcoin address with -wif-only.")
+	qrPrivateFile := flag.String("qr-private-file", "", "(Optional) Write the Ethereum private key as a PNG QR code to this file, or the WIF with -wif-only. Anyone who gets the file has the key!")
+	nameFilter := flag.String("filter", "", "(Optional) Only list, pick or recover the vaults whose name contains this text, ignoring case.")
+	showShares := flag.Bool("show-shares", false, "(Optional) List the share id, party and curve of each share of the vault in the files, without reconstructing its key.")
+	listVaults := flag.Bool("list", false, "(Optional) Print a table of the vaults in the files, with their threshold, shares and last reshare nonce, instead of recovering one.")
+	verifyHashes := flag.String("verify-hashes", "", "(Optional) Check the SHA-256 hashes of the input files against this manifest, in the format of sha256sum, and exit with an error if any file differs or is not in it.")
+	reportFile := flag.String("report", "", "(Optional) Write a Markdown report of the recovery to this file for an audit: the input files and their SHA-256 hashes, and the recovered vaults and their addresses. It holds no private keys.")
+	rpcURL := flag.String("rpc", "", "(Optional) Look up the balance of the recovered Ethereum address at this JSON-RPC endpoint, e.g. https://eth.example.com. This is the only flag that connects to a network; leave it out on an air-gapped machine.")
+	rpcTokens := flag.String("rpc-tokens", "", "(Optional) With -rpc, also look up the balances of these ERC-20 token contracts, separated by commas.")
+	sweepTo := flag.String("sweep-to", "", "(Optional) Sign a transaction that sends the whole balance of the recovered Ethereum address, less the fee, to this 0x address, and print it for you to broadcast. It is never broadcast by the tool.")
+	sweepNonce := flag.Int("sweep-nonce", -1, "(Optional) With -sweep-to, the nonce of the transaction. Looked up with -rpc by default.")
+	sweepGasPrice := flag.String("sweep-gas-price", "", "(Optional) With -sweep-to, the gas price of the transaction in gwei, e.g. 20 or 1.5. Looked up with -rpc by default.")
+	sweepBalance := flag.String("sweep-balance", "", "(Optional) With -sweep-to, the balance of the address in ether, to sweep without -rpc on an air-gapped machine. Looked up with -rpc by default.")
+	listCSV := flag.String("list-csv", "", "(Optional) Write the list of vaults in the files to this CSV file, with their threshold, shares and last reshare nonce, instead of recovering one.")
+	chainID := flag.Int64("chain-id", 1, "(Optional) Chain id of the EVM network to sign the -sweep-to transaction for, e.g. 1 for Ethereum, 10 for Optimism or 11155111 for Sepolia.")
+	signMsg := flag.String("sign-message", "", "(Optional) Sign this message with the recovered Ethereum key (personal_sign), to prove control of the vault without moving funds.")
+	bech32HRP := flag.String("bech32-hrp", "", "(Optional) Also show the Cosmos SDK address of the vault with this bech32 prefix, e.g. cosmos, osmo or celestia.")
+	coins := flag.String("coin", "", "(Optional) Also show the WIF and addresses of these coins, separated by commas: ltc, doge or xrp.")
+	onlyChains := flag.String("only-chains", "", "(Optional) Show only the keys and addresses of these chains, separated by commas: eth, btc, tron, sol, cosmos, ltc, doge or xrp. By default all of eth, btc, tron and sol are shown, not just eth and the btc WIFs; use -only-chains eth,btc for those. Can't be combined with -coin.")
+	btcAddressType := flag.String("btc-address-type", recovery.BTCAddressBech32, "(Optional) Bitcoin address type to show: legacy (1...), p2sh (3...) or bech32 (bc1...).")
+	verifyOnly := flag.Bool("verify", false, "(Optional) Verify that the vault can be recovered, showing only its addresses. No private keys are shown or exported.")
+	expectedAddress := flag.String("expected-address", "", "(Optional) Address the recovered vault must have, e.g. its Ethereum, Bitcoin or Solana address. Exits with an error before any private key is shown if it does not match.")
+	mnemonicsFile := flag.String("mnemonics-file", "", "(Optional) File with the phrase of each input file, instead of entering them: one phrase per line in file order, or a JSON object of file name to phrase.")
+	mnemonicsStdin := flag.Bool("stdin", false, "(Optional) Read the phrases from stdin, one per line in file order. Implied when stdin is not a terminal.")
+	wordByWord := flag.Bool("word-by-word", false, "(Optional) Enter the phrases one word at a time, with completion from the BIP39 word list, instead of pasting them whole.")
+	noColor := flag.Bool("no-color", false, "(Optional) Output no colors or other ANSI styling. Also set by the NO_COLOR environment variable, and when stdout is not a terminal.")
+	plain := flag.Bool("plain", false, "(Optional) Output the recovered data without any styling, for minimal terminals.")
+	minKB := flag.Float64("min-kb", recovery.DefaultMinInflatedKB, "(Optional) Smallest accepted size in KB of an inflated V2 share. Smaller shares are treated as corrupt.")
+	maxKB := flag.Float64("max-kb", recovery.DefaultMaxInflatedKB, "(Optional) Largest accepted size in KB of an inflated V2 share. Protects against decompression bombs.")
+	revealDelay := flag.Int("reveal-delay", 0, "(Optional) Seconds to count down before the private keys are shown. The keys are shown after you press Enter.")
+	revealClear := flag.Bool("reveal-clear", false, "(Optional) Clear the screen after you press Enter once the private keys have been shown.")
+	revealOnKey := flag.Bool("reveal-on-keypress", false, "(Optional) First show the addresses with the private keys masked, and only reveal the keys once you press Enter.")
+	copyClipboard := flag.Bool("clipboard", false, "(Optional) Copy the recovered private key to the clipboard once it is shown, and clear the clipboard again after -clipboard-timeout.")
+	clipboardValue := flag.String("clipboard-value", clipboardKey, "(Optional) With -clipboard, what to copy: key, wif or address. With -wif-only, the key is the Bitcoin WIF and the address the Bitcoin address.")
+	clipboardTimeout := flag.Int("clipboard-timeout", defaultClipboardTimeout, "(Optional) With -clipboard, seconds after which the clipboard is cleared.")
+	revealTimeout := flag.Int("reveal-timeout", 0, "(Optional) With -reveal-on-keypress, seconds after which the revealed keys are cleared from the screen again.")
+	listGens := flag.Bool("list-generations", false, "(Optional) Show the share count of each vault per reshare nonce and which generation can be recovered, then exit.")
+	verbose := flag.Bool("verbose", false, "(Optional) Print extra details while decoding the backup files.")
+	quiet := flag.Bool("quiet", false, "(Optional) Only print the recovered address and key block, or nothing in -verify mode: no banner, progress or warnings. Errors and prompts still go to stderr.")
+	jsonOut := flag.Bool("json", false, "(Optional) Output the recovered vault, the vault list (without -vault-id) or the health report as a JSON object on stdout. Errors are output as {\"error\": \"...\"}.")
+	selfTest := flag.Bool("self-test", false, "(Optional) Recover a built-in sample vault and check its known addresses, to confirm that the tool works on this machine. Needs no files.")
+	showVersion := flag.Bool("version", false, "(Optional) Print the version, git commit and build date of the tool, and exit.")
+	mlock := flag.Bool("mlock", false, "(Optional) Lock secret key material into RAM so it is not swapped to disk. Not supported on Windows.")
 
-	flag.Parse()
+	// the health subcommand checks that every vault in the files can be recovered, without revealing keys
+	healthCheck := len(os.Args) > 1 && os.Args[1] == "health"
+	if healthCheck {
+		_ = flag.CommandLine.Parse(os.Args[2:])
+	} else {
+		flag.Parse()
+	}
 	files := flag.Args()
-	if len(files) < 1 {
-		fmt.Println("Please supply some input files on the command line. \nExample: recovery-tool.exe [-flags] file1.json file2.json … \n\nOptional flags:")
+	if *showVersion {
+		commit, date := version.Info()
+		if *jsonOut {
+			if err := writeJSON(versionJSON{Version: version.Version, Commit: commit, BuildDate: date}); err != nil {
+				exitWithError(err, true)
+			}
+		} else {
+			fmt.Fprintf(dataOut, "io.finnet Key Recovery Tool %s\ncommit: %s\nbuilt: %s\n", version.Version, commit, date)
+		}
+		return
+	}
+	if len(files) < 1 && !*selfTest {
+		fmt.Fprintln(logOut, "Please supply some input files on the command line. \nExample: recovery-tool.exe [-flags] file1.json file2.json … \n"+
+			"To check that every vault in the files can be recovered: recovery-tool.exe health [-flags] file1.json file2.json … \n\nOptional flags:")
 		flag.PrintDefaults()
 		return
 	}
+	// in -json mode stdout only carries the JSON output, so everything else, including the forms, goes to stderr
+	if *jsonOut {
+		logOut, promptOut, errOut = os.Stderr, os.Stderr, os.Stderr
+	}
+	if *quiet {
+		logOut, promptOut, errOut = io.Discard, os.Stderr, os.Stderr
+	}
+	ui.EnableTerminalStyling()
+	if *noColor || !ui.WantsColor(os.Stdout) {
+		ui.DisableColor()
+	}
+	if *jsonOut && *listGens {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-json is not supported with -list-generations")), true)
+	}
+	fmt.Fprint(logOut, ui.Banner())
+	interrupted := handleInterrupts()
 
-	fmt.Print(ui.Banner())
+	if *selfTest {
+		var passed selfTestJSON
+		var err error
+		interrupted.run(func(ctx context.Context) {
+			passed, err = runSelfTest(ctx)
+		})
+		if err != nil {
+			exitWithError(err, *jsonOut)
+		}
+		if *jsonOut {
+			if err = writeJSON(passed); err != nil {
+				exitWithError(err, true)
+			}
+		} else {
+			fmt.Fprintf(dataOut, "✓ Self-test of io.finnet Key Recovery Tool %s (commit %s) passed: the sample vault %s was recovered with its known Ethereum address %s and Solana address %s.\n",
+				passed.Version, passed.Commit, passed.VaultID, passed.EthereumAddress, passed.SolanaAddress)
+		}
+		os.Exit(0)
+	}
+
+	if *mlock {
+		if err := secmem.Enable(); err != nil {
+			fmt.Fprintf(logOut, "⚠ -mlock: %s. Secrets may be swapped to disk.\n\n", err)
+		}
+	}
 
 	appConfig := config.AppConfig{
-		Filenames:      files,
-		NonceOverride:  *nonceOverride,
-		QuorumOverride: *quorumOverride,
-		ExportKSFile:   *exportKSFile,
-		PasswordForKS:  *passwordForKS,
+		Filenames:        files,
+		NonceOverride:    *nonceOverride,
+		QuorumOverride:   *quorumOverride,
+		AutoThreshold:    *autoThreshold,
+		ExportKSFile:     *exportKSFile,
+		OutputDir:        *outputDir,
+		Force:            *force,
+		PasswordForKS:    *passwordForKS,
+		PasswordFile:     *passwordFile,
+		ScryptPreset:     *scryptPreset,
+		ScryptN:          *customScryptN,
+		ScryptP:          *customScryptP,
+		VerifyAgainst:    *verifyAgainst,
+		VerifyPassword:   *verifyPassword,
+		MinInflatedKB:    *minKB,
+		MaxInflatedKB:    *maxKB,
+		WIFOnly:          *wifOnly,
+		Network:          *network,
+		BTCAddressType:   *btcAddressType,
+		Bech32HRP:        *bech32HRP,
+		Coins:            rpcTokenList(*coins),
+		OnlyChains:       rpcTokenList(*onlyChains),
+		SignMessage:      *signMsg,
+		AsMnemonic:       *asMnemonic,
+		QR:               *showQR,
+		QRPrivate:        *showQRPrivate,
+		QRFile:           *qrFile,
+		ListCSV:          *listCSV,
+		Report:           *reportFile,
+		VerifyHashes:     *verifyHashes,
+		RPC:              *rpcURL,
+		RPCTokens:        rpcTokenList(*rpcTokens),
+		SweepTo:          *sweepTo,
+		SweepNonce:       *sweepNonce,
+		SweepGasPrice:    *sweepGasPrice,
+		SweepBalance:     *sweepBalance,
+		ChainID:          *chainID,
+		Filter:           *nameFilter,
+		ShowShares:       *showShares,
+		QRPrivateFile:    *qrPrivateFile,
+		AddressesOnly:    *addressesOnly,
+		UncompressedWIF:  !*wifCompressed,
+		VerifyOnly:       *verifyOnly,
+		ExpectedAddress:  *expectedAddress,
+		MnemonicsFile:    *mnemonicsFile,
+		MnemonicsStdin:   *mnemonicsStdin,
+		WordByWord:       *wordByWord,
+		Plain:            *plain,
+		ListGenerations:  *listGens,
+		RevealDelay:      *revealDelay,
+		RevealClear:      *revealClear,
+		RevealOnKey:      *revealOnKey,
+		RevealTimeout:    *revealTimeout,
+		Clipboard:        *copyClipboard,
+		ClipboardValue:   *clipboardValue,
+		ClipboardTimeout: *clipboardTimeout,
+		Verbose:          *verbose,
+		JSON:             *jsonOut,
+		Quiet:            *quiet,
+	}
+	if appConfig.Network != "" && appConfig.Network != networkMainnet && appConfig.Network != networkTestnet {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("unknown -network `%s`, expected %s or %s", appConfig.Network, networkMainnet, networkTestnet)), appConfig.JSON)
+	}
+	if appConfig.BTCAddressType != recovery.BTCAddressLegacy && appConfig.BTCAddressType != recovery.BTCAddressP2SH && appConfig.BTCAddressType != recovery.BTCAddressBech32 {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("unknown -btc-address-type `%s`, expected %s, %s or %s", appConfig.BTCAddressType, recovery.BTCAddressLegacy, recovery.BTCAddressP2SH, recovery.BTCAddressBech32)), appConfig.JSON)
+	}
+	// segwit addresses need a compressed public key, so an uncompressed WIF comes with its legacy address
+	if appConfig.UncompressedWIF {
+		btcTypeSet := false
+		flag.Visit(func(f *flag.Flag) {
+			btcTypeSet = btcTypeSet || f.Name == "btc-address-type"
+		})
+		if btcTypeSet && appConfig.BTCAddressType != recovery.BTCAddressLegacy {
+			exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-wif-compressed=false only works with -btc-address-type %s, as %s addresses need a compressed public key",
+				recovery.BTCAddressLegacy, appConfig.BTCAddressType)), appConfig.JSON)
+		}
+		appConfig.BTCAddressType = recovery.BTCAddressLegacy
+	}
+	if appConfig.Bech32HRP != "" {
+		if err := bech32.ValidateHRP(appConfig.Bech32HRP); err != nil {
+			exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("invalid -bech32-hrp: %v", err)), appConfig.JSON)
+		}
+	}
+	if err := recovery.ValidateCoins(appConfig.Coins); err != nil {
+		exitWithError(err, appConfig.JSON)
+	}
+	if err := validateOnlyChains(appConfig.OnlyChains); err != nil {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, err), appConfig.JSON)
+	}
+	if len(appConfig.OnlyChains) > 0 {
+		// -coin adds to the chains that are shown, and -only-chains replaces them, so one of the two is enough
+		if len(appConfig.Coins) > 0 {
+			exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-coin can't be combined with -only-chains; name its coins in -only-chains instead")), appConfig.JSON)
+		}
+		if appConfig.WIFOnly && !slices.Contains(appConfig.OnlyChains, coinBitcoin) {
+			exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-wif-only only shows the Bitcoin WIFs, so -only-chains must include %s", coinBitcoin)), appConfig.JSON)
+		}
+		// the chains of -only-chains that are only derived on request are derived as with -coin
+		for _, coin := range appConfig.OnlyChains {
+			if slices.Contains(derivedCoins, coin) && !slices.Contains(appConfig.Coins, coin) {
+				appConfig.Coins = append(appConfig.Coins, coin)
+			}
+		}
+		if slices.Contains(appConfig.OnlyChains, coinCosmos) && appConfig.Bech32HRP == "" {
+			appConfig.Bech32HRP = recovery.CosmosHRP
+		}
+	}
+	if appConfig.MinInflatedKB < 0 || appConfig.MaxInflatedKB <= appConfig.MinInflatedKB {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("invalid share size bounds: -min-kb %.2f must be at least 0 and below -max-kb %.2f", appConfig.MinInflatedKB, appConfig.MaxInflatedKB)), appConfig.JSON)
+	}
+	if appConfig.RevealDelay < 0 {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("invalid -reveal-delay %d, expected a number of seconds", appConfig.RevealDelay)), appConfig.JSON)
+	}
+	if appConfig.RevealTimeout < 0 {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("invalid -reveal-timeout %d, expected a number of seconds", appConfig.RevealTimeout)), appConfig.JSON)
+	}
+	if appConfig.RevealTimeout > 0 && !appConfig.RevealOnKey {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-reveal-timeout only works together with -reveal-on-keypress")), appConfig.JSON)
+	}
+	if appConfig.RevealTimeout > 0 && appConfig.RevealClear {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-reveal-timeout clears the screen on its own, so it can't be combined with -reveal-clear")), appConfig.JSON)
+	}
+	if appConfig.QRPrivate && !appConfig.QR {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-qr-private only works together with -qr")), appConfig.JSON)
+	}
+	if appConfig.Quiet && appConfig.Verbose {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-verbose adds to the output that -quiet leaves out, so they can't be combined")), appConfig.JSON)
+	}
+	if appConfig.ChainID <= 0 {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("invalid -chain-id %d, expected a positive EVM chain id, e.g. 1 for Ethereum", appConfig.ChainID)), appConfig.JSON)
+	}
+	if appConfig.RPC != "" {
+		if err := validateRPC(appConfig.RPC, appConfig.RPCTokens); err != nil {
+			exitWithError(recovery.WithKind(recovery.ErrInvalidInput, err), appConfig.JSON)
+		}
+	} else if len(appConfig.RPCTokens) > 0 {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-rpc-tokens needs -rpc, the endpoint to look up the token balances at")), appConfig.JSON)
+	}
+	if appConfig.QR && appConfig.JSON {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-qr can't be combined with -json, as the QR codes are meant for a terminal")), appConfig.JSON)
+	}
+	// a lookup of the addresses is a dry run with more addresses, including the Cosmos Hub one if no other prefix was given
+	if appConfig.AddressesOnly {
+		appConfig.VerifyOnly = true
+		if appConfig.Bech32HRP == "" {
+			appConfig.Bech32HRP = recovery.CosmosHRP
+		}
+	}
+	if appConfig.SweepTo != "" {
+		if err := validateSweep(appConfig); err != nil {
+			exitWithError(recovery.WithKind(recovery.ErrInvalidInput, err), appConfig.JSON)
+		}
+	} else if appConfig.SweepNonce >= 0 || appConfig.SweepGasPrice != "" || appConfig.SweepBalance != "" {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-sweep-nonce, -sweep-gas-price and -sweep-balance only work together with -sweep-to")), appConfig.JSON)
+	}
+	if appConfig.Clipboard {
+		if err := validateClipboard(appConfig); err != nil {
+			exitWithError(recovery.WithKind(recovery.ErrInvalidInput, err), appConfig.JSON)
+		}
+	}
+	// the form can't run on piped input, so the phrases are read from it instead
+	if appConfig.MnemonicsFile == "" && !ui.StdinIsTerminal() {
+		appConfig.MnemonicsStdin = true
+	}
+	if appConfig.MnemonicsStdin && appConfig.MnemonicsFile != "" {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("use either -stdin or -mnemonics-file, not both")), appConfig.JSON)
+	}
+	vaultChosen := len(vaultIDs) > 0 || *vaultName != ""
+	if appConfig.ListCSV != "" && (vaultChosen || *allVaults) {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-list-csv only lists the vaults, so it can't be combined with -vault-id, -vault-name or -all")), appConfig.JSON)
+	}
+	if *listVaults && (vaultChosen || *allVaults) {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-list only lists the vaults, so it can't be combined with -vault-id, -vault-name or -all")), appConfig.JSON)
+	}
+	if vaultChosen && *allVaults {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("use either -vault-id, -vault-name or -all, not several")), appConfig.JSON)
+	}
+	if *vaultName != "" && len(vaultIDs) > 1 {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-vault-name picks a single vault, so it can only be combined with one -vault-id")), appConfig.JSON)
+	}
+	if appConfig.MnemonicsStdin && !vaultChosen && !*allVaults && !healthCheck && !appConfig.ListGenerations && !appConfig.JSON && appConfig.ListCSV == "" && !*listVaults {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-vault-id or -vault-name is required when the phrases are read from stdin, as the vault picker needs a terminal")), appConfig.JSON)
+	}
+	if appConfig.WIFOnly {
+		// the Bitcoin user does not want an Ethereum wallet v3 file
+		appConfig.ExportKSFile, appConfig.OutputDir = "", ""
+	}
+	// the default -export is only asked a password for when it was chosen explicitly
+	exportSet := false
+	flag.Visit(func(f *flag.Flag) {
+		exportSet = exportSet || f.Name == "export"
+	})
+	scryptN, scryptP, err := scryptParams(appConfig.ScryptPreset, appConfig.ScryptN, appConfig.ScryptP)
+	if err != nil {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, err), appConfig.JSON)
+	}
+	if appConfig.PasswordForKS, err = keystorePassword(appConfig.PasswordForKS, appConfig.PasswordFile); err != nil {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, err), appConfig.JSON)
+	}
+	if appConfig.OutputDir != "" {
+		if err = os.MkdirAll(appConfig.OutputDir, 0o700); err != nil {
+			exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("⚠ unable to create -output-dir `%s`: %s", appConfig.OutputDir, err)), appConfig.JSON)
+		}
+	}
+	// a wallet v3 file that can't be written is reported now, rather than once a long recovery is done;
+	// the export is only attempted with a password, or when one is asked for
+	exportsKeystore := !appConfig.VerifyOnly && !appConfig.ShowShares &&
+		(appConfig.PasswordForKS != "" || (exportSet || appConfig.OutputDir != "") && ui.StdinIsTerminal())
+	switch {
+	case !exportsKeystore:
+	case appConfig.OutputDir != "":
+		err = checkWritable(appConfig.OutputDir)
+	case appConfig.ExportKSFile != "" && len(vaultIDs) <= 1 && !*allVaults:
+		if err = checkOverwrite(appConfig.ExportKSFile, appConfig.Force); err == nil {
+			err = checkWritable(filepath.Dir(appConfig.ExportKSFile))
+		}
+	}
+	if err != nil {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, err), appConfig.JSON)
+	}
+	for _, filename := range []string{appConfig.QRFile, appConfig.QRPrivateFile, appConfig.ListCSV, appConfig.Report} {
+		if filename == "" || filename == appConfig.QRPrivateFile && appConfig.VerifyOnly {
+			continue
+		}
+		if err = checkOverwrite(filename, appConfig.Force); err == nil {
+			err = checkWritable(filepath.Dir(filename))
+		}
+		if err != nil {
+			exitWithError(recovery.WithKind(recovery.ErrInvalidInput, err), appConfig.JSON)
+		}
+	}
+	if appConfig.QRPrivateFile != "" && !appConfig.VerifyOnly {
+		fmt.Fprintf(logOut, "⚠ -qr-private-file: the private key will be written to `%s` as a QR code. Anyone who gets or sees this file "+
+			"can take the funds of the vault: keep it offline and delete it once you are done.\n\n", appConfig.QRPrivateFile)
 	}
 
+	// folders, patterns and zip archives are replaced by the backup files in them
+	if appConfig.Filenames, err = ui.ExpandFiles(appConfig.Filenames, logOut); err != nil {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, err), appConfig.JSON)
+	}
 	// First validate that files exist and are readable
-	if err := ui.ValidateFiles(appConfig); err != nil {
-		fmt.Print(ui.ErrorBox(err))
-		os.Exit(1)
+	if err = ui.ValidateFiles(appConfig, logOut); err != nil {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, err), appConfig.JSON)
 	}
 
 	/**
 	 * Run the steps to get the menmonics
 	 */
 	// var vaultsDataFiles []VaultsDataFile = make([]VaultsDataFile, 0, len(appConfig.Filenames))
-	f := ui.NewMnemonicsForm(appConfig)
-	vaultsDataFiles, err := f.Run()
+	var vaultsDataFiles *[]ui.VaultsDataFile
+	switch {
+	case appConfig.MnemonicsFile != "":
+		vaultsDataFiles, err = ui.ReadMnemonicsFile(appConfig.MnemonicsFile, appConfig.Filenames)
+	case appConfig.MnemonicsStdin:
+		vaultsDataFiles, err = ui.ReadMnemonics(os.Stdin, appConfig.Filenames)
+	default:
+		vaultsDataFiles, err = ui.NewMnemonicsForm(appConfig, promptOut).Run()
+	}
 	if err != nil {
 		// if err := f.Run(&vaultsDataFiles); err != nil {
-		fmt.Println(ui.ErrorBox(err))
-		os.Exit(1)
+		exitWithError(err, appConfig.JSON)
 	}
 	if vaultsDataFiles == nil {
-		fmt.Println("No vaults data files were selected.")
+		fmt.Fprintln(logOut, "No vaults data files were selected.")
+		os.Exit(0)
+	}
+
+	if healthCheck {
+		var report *recovery.HealthReport
+		interrupted.run(func(ctx context.Context) {
+			report, err = recovery.CheckHealth(ctx, *vaultsDataFiles, recoveryOptions(appConfig, ""))
+		})
+		if err != nil {
+			exitWithError(err, appConfig.JSON)
+		}
+		if appConfig.JSON {
+			if err = writeJSON(report); err != nil {
+				exitWithError(err, appConfig.JSON)
+			}
+		} else {
+			fmt.Fprint(dataOut, renderHealth(report))
+		}
+		if !report.Pass {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// only analyse the reshare generations; no keys are recovered
+	if appConfig.ListGenerations {
+		vaults, err := recovery.ListGenerations(*vaultsDataFiles)
+		if err != nil {
+			exitWithError(err, appConfig.JSON)
+		}
+		fmt.Fprint(dataOut, renderGenerations(vaults))
 		os.Exit(0)
 	}
 
 	/**
 	 * Retrieve vaults information and select a vault
 	 */
-	_, _, _, vaultsFormInfo, err := runTool(*vaultsDataFiles, nil, nonceOverride, quorumOverride, exportKSFile, passwordForKS)
+	var vaultsFormInfo []ui.VaultPickerItem
+	var warnings []recovery.Warning
+	interrupted.run(func(ctx context.Context) {
+		vaultsFormInfo, warnings, err = recovery.ListVaults(ctx, *vaultsDataFiles, recoveryOptions(appConfig, ""))
+	})
+	printWarnings(logOut, warnings)
 	if err != nil {
-		fmt.Printf("Failed to run tool to retrieve vault information: %s\n", err)
-		os.Exit(1)
+		exitWithError(fmt.Errorf("failed to run tool to retrieve vault information: %w", err), appConfig.JSON)
+	}
+	if vaultsFormInfo = filterVaults(vaultsFormInfo, appConfig.Filter); len(vaultsFormInfo) == 0 {
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("no vault name in the files contains `%s`", appConfig.Filter)), appConfig.JSON)
 	}
 
-	var selectedVaultId string
-	// If the vault ID is not provided, run the vault picker form
-	if *vaultID == "" {
-		selectedVaultId, err = ui.RunVaultPickerForm(vaultsFormInfo)
+	// a spreadsheet of the vaults for an audit, instead of a recovery
+	if appConfig.ListCSV != "" {
+		csvData, err := vaultListCSV(vaultsFormInfo)
+		if err == nil {
+			if err = checkOverwrite(appConfig.ListCSV, appConfig.Force); err == nil {
+				err = writeFileAtomic(appConfig.ListCSV, csvData, 0o644)
+			}
+		}
 		if err != nil {
-			fmt.Printf("Failed to run form: %s\n", err)
-			os.Exit(1)
+			exitWithError(fmt.Errorf("⚠ could not write the -list-csv file: %s", strings.TrimPrefix(err.Error(), "⚠ ")), appConfig.JSON)
 		}
-	} else {
-		// Use the vault ID provided by CLI argument
-		selectedVaultId = *vaultID
+		fmt.Fprintf(logOut, "Wrote the list of %d vault(s) to: %s.\n", len(vaultsFormInfo), appConfig.ListCSV)
+		os.Exit(0)
+	}
+
+	// a read-only overview of the vaults, without the vault picker
+	if *listVaults && !appConfig.JSON {
+		fmt.Fprint(dataOut, renderVaultTable(vaultsFormInfo, appConfig.Plain))
+		os.Exit(0)
 	}
 
-	var selectedVault ui.VaultPickerItem
-	// Get the selected vault from the vaults form data
-	for _, vault := range vaultsFormInfo {
-		if vault.VaultID == selectedVaultId {
-			selectedVault = vault
-			break
+	// there is no vault picker in -json mode, so without a vault id the vaults are listed instead
+	if appConfig.JSON && !vaultChosen && !*allVaults {
+		if err = writeJSON(newVaultListJSON(vaultsFormInfo)); err != nil {
+			exitWithError(err, appConfig.JSON)
 		}
+		os.Exit(0)
 	}
-	if selectedVault.VaultID == "" {
-		fmt.Println(ui.ErrorBox(fmt.Errorf("vault with ID %s not found", selectedVaultId)))
-		os.Exit(1)
+
+	selectedVaults := vaultsFormInfo
+	if !*allVaults {
+		if *vaultName != "" {
+			byName, err := resolveVaultName(vaultsFormInfo, *vaultName)
+			if err != nil {
+				exitWithError(recovery.WithKind(recovery.ErrInvalidInput, err), appConfig.JSON)
+			}
+			if len(vaultIDs) == 0 {
+				vaultIDs = append(vaultIDs, byName.VaultID)
+			} else if byID, err := resolveVault(vaultsFormInfo, vaultIDs[0]); err == nil && byID.VaultID != byName.VaultID {
+				exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-vault-id `%s` is vault %s, but -vault-name `%s` is vault %s", vaultIDs[0],
+					describeVaults([]ui.VaultPickerItem{byID}), *vaultName, describeVaults([]ui.VaultPickerItem{byName}))), appConfig.JSON)
+			}
+		}
+		// If the vault ID is not provided, run the vault picker form
+		if len(vaultIDs) == 0 {
+			selectedVaultId, err := ui.RunVaultPickerForm(vaultsFormInfo, promptOut)
+			if err != nil {
+				exitWithError(fmt.Errorf("failed to run form: %s", err), appConfig.JSON)
+			}
+			vaultIDs = append(vaultIDs, selectedVaultId)
+		}
+		// Get the selected vaults from the vaults form data; the CLI arguments may also be a prefix, name or number
+		if selectedVaults, err = selectVaults(vaultsFormInfo, vaultIDs); err != nil {
+			exitWithError(recovery.WithKind(recovery.ErrInvalidInput, err), appConfig.JSON)
+		}
+	}
+	if len(selectedVaults) > 1 {
+		switch {
+		case appConfig.ShowShares:
+			exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-show-shares is only supported for a single vault")), appConfig.JSON)
+		case appConfig.VerifyAgainst != "":
+			exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-verify-against is only supported when recovering a single vault")), appConfig.JSON)
+		case appConfig.ExpectedAddress != "":
+			exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-expected-address is only supported when recovering a single vault")), appConfig.JSON)
+		case appConfig.QRFile != "" || appConfig.QRPrivateFile != "":
+			exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-qr-file and -qr-private-file are only supported when recovering a single vault")), appConfig.JSON)
+		case exportSet && appConfig.OutputDir == "":
+			exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("-export names a single file; use -output-dir to export the wallet v3 files of several vaults")), appConfig.JSON)
+		}
+		// the default -export would be written over by each vault
+		if appConfig.OutputDir == "" {
+			appConfig.ExportKSFile = ""
+		}
+	}
+
+	// a diagnostic of which parties' shares are in the files, before the sensitive reconstruction is attempted
+	if appConfig.ShowShares {
+		var shares *recovery.VaultShares
+		interrupted.run(func(ctx context.Context) {
+			shares, err = recovery.ListShares(ctx, *vaultsDataFiles, recoveryOptions(appConfig, selectedVaults[0].VaultID))
+		})
+		if err != nil {
+			exitWithError(err, appConfig.JSON)
+		}
+		if appConfig.JSON {
+			err = writeJSON(newShareListJSON(shares))
+		} else {
+			fmt.Fprint(dataOut, renderShareTable(shares, appConfig.Plain))
+			fmt.Fprint(logOut, "\n"+shareSummary(shares))
+		}
+		if err != nil {
+			exitWithError(err, appConfig.JSON)
+		}
+		os.Exit(0)
+	}
+
+	// the names in -output-dir are only known once the vaults are chosen, which is still before any key is reconstructed
+	if exportsKeystore && appConfig.OutputDir != "" {
+		for _, vault := range selectedVaults {
+			if err = checkOverwrite(filepath.Join(appConfig.OutputDir, walletFileName(vault)), appConfig.Force); err != nil {
+				exitWithError(err, appConfig.JSON)
+			}
+		}
+	}
+
+	// ask for the wallet v3 file password before the keys are shown; on a terminal only, as it is read without echo
+	exportTo := appConfig.ExportKSFile
+	if appConfig.OutputDir != "" {
+		exportTo = appConfig.OutputDir
+	}
+	if appConfig.PasswordForKS == "" && (exportSet || appConfig.OutputDir != "") && exportTo != "" && !appConfig.VerifyOnly && ui.StdinIsTerminal() {
+		if appConfig.PasswordForKS, err = ui.PromptPassword(os.Stdin, promptOut, exportTo); err != nil {
+			exitWithError(err, appConfig.JSON)
+		}
+	}
+	if appConfig.VerifyPassword == "" {
+		appConfig.VerifyPassword = appConfig.PasswordForKS
 	}
 
 	/**
-	 * Run the recovery for the chosen vault
+	 * Run the recovery for the chosen vaults
 	 */
-	fmt.Println(
+	recovered := make([]recoveryJSON, 0, len(selectedVaults))
+	reported := make([]reportVault, 0, len(selectedVaults))
+	for _, vault := range selectedVaults {
+		out, report := recoverAndOutput(appConfig, interrupted, *vaultsDataFiles, vault, scryptN, scryptP)
+		recovered, reported = append(recovered, out), append(reported, report)
+	}
+	if appConfig.Report != "" {
+		if err = writeReport(appConfig, reported); err != nil {
+			exitWithError(err, appConfig.JSON)
+		}
+		fmt.Fprintf(logOut, "Wrote the recovery report to: %s.\n", appConfig.Report)
+	}
+	if appConfig.JSON {
+		var out any = recoveredListJSON{Recovered: recovered}
+		if len(recovered) == 1 {
+			out = recovered[0]
+		}
+		if err = writeJSON(out); err != nil {
+			exitWithError(err, appConfig.JSON)
+		}
+	}
+}
+
+// recoverAndOutput recovers a vault and outputs its keys, or only its addresses in -verify mode, and exports its wallet v3 file.
+// In -json mode, the vault is returned for the JSON output instead. Its public data is returned for the -report too.
+// The keys are wiped on return, and on a failure the tool exits.
+func recoverAndOutput(appConfig config.AppConfig, interrupted *interrupts, vaultsDataFiles []ui.VaultsDataFile, selectedVault ui.VaultPickerItem,
+	scryptN, scryptP int) (recoveryJSON, reportVault) {
+
+	fmt.Fprintln(logOut,
 		lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("RECOVERING VAULT \"%s\" WITH ID %s\n", selectedVault.Name, selectedVault.VaultID)),
 	)
 
-	address, ecSK, edSK, _, err := runTool(*vaultsDataFiles, &selectedVault.VaultID, nonceOverride, quorumOverride, exportKSFile, passwordForKS)
+	// on Ctrl-C from here on, the recovered keys are wiped before the tool exits
+	var result *recovery.Result
+	var err error
+	interrupted.run(func(ctx context.Context) {
+		result, err = recovery.Recover(ctx, vaultsDataFiles, recoveryOptions(appConfig, selectedVault.VaultID))
+		interrupted.result.Store(result)
+	})
+	if result != nil {
+		printWarnings(logOut, result.Warnings)
+	}
+	if err := secmem.Err(); err != nil {
+		fmt.Fprintf(logOut, "⚠ -mlock: could not lock some memory (%s). Secrets may be swapped to disk; try raising the locked memory limit (ulimit -l).\n\n", err)
+	}
 	if err != nil {
-		fmt.Println(ui.ErrorBox(err))
-		os.Exit(1)
-		return
+		exitWithError(err, appConfig.JSON)
 	}
-	defer func() {
-		clear(ecSK)
-		clear(edSK)
-	}()
-	if ecSK == nil {
-		// only listing vaults
-		os.Exit(0)
-		return
+	defer result.Wipe()
+	ecSK := result.ECDSAKey
+	if flagName := p256Unsupported(appConfig); result.ECDSACurve == recovery.CurveP256 && flagName != "" {
+		result.Wipe()
+		exitWithError(recovery.WithKind(recovery.ErrInvalidInput, fmt.Errorf("⚠ vault `%s` is on the P-256 curve, and %s needs a secp256k1 key. No private keys were shown", result.VaultID, flagName)), appConfig.JSON)
+	}
+
+	// guard against producing a different key than a prior recovery, e.g. due to a wrong threshold
+	if appConfig.VerifyAgainst != "" {
+		matches, err := verifyAgainstKeystore(appConfig.VerifyAgainst, appConfig.VerifyPassword, ecSK)
+		if err != nil {
+			exitWithError(err, appConfig.JSON)
+		}
+		if !matches {
+			exitWithError(recovery.WithKind(recovery.ErrPubKeyMismatch, fmt.Errorf("⚠ MISMATCH: the recovered key does not match the key in wallet v3 file `%s`", appConfig.VerifyAgainst)), appConfig.JSON)
+		}
+		fmt.Fprintf(logOut, "✓ MATCH: the recovered key matches the key in wallet v3 file `%s`.\n\n", appConfig.VerifyAgainst)
+	}
+
+	sections := recoveredSections(result, appConfig.Network, appConfig.BTCAddressType, appConfig.WIFOnly)
+	if appConfig.AddressesOnly {
+		if sections, err = addressSections(result, appConfig.Network, appConfig.WIFOnly, !appConfig.UncompressedWIF); err != nil {
+			exitWithError(err, appConfig.JSON)
+		}
+	}
+	sections = selectSections(sections, appConfig.OnlyChains)
+
+	var phrase string
+	if appConfig.AsMnemonic && !appConfig.VerifyOnly {
+		if phrase, err = keyMnemonic(ecSK); err != nil {
+			exitWithError(err, appConfig.JSON)
+		}
+		sections = append(sections, keyMnemonicSection(phrase))
+	}
+
+	// the signature proves control of the key without revealing it, so it is shown in -verify mode too
+	var signed *signedMessage
+	if appConfig.SignMessage != "" {
+		if signed, err = signMessage(ecSK, appConfig.SignMessage); err != nil {
+			exitWithError(err, appConfig.JSON)
+		}
+		if signed.Address != result.Address {
+			exitWithError(fmt.Errorf("⚠ the message signature recovers to `%s` instead of the vault's address `%s`", signed.Address, result.Address), appConfig.JSON)
+		}
+		sections = append(sections, signedMessageSection(signed))
+	}
+
+	// the -expected-address check follows, but the tool stops on a mismatch, so a report is only returned once it is passed
+	report := newReportVault(result, sections, appConfig)
+
+	// a dry run: only the public data is shown, and the keys are cleared on return
+	if appConfig.VerifyOnly {
+		mode := "-verify"
+		if appConfig.AddressesOnly {
+			mode = "-addresses-only"
+		}
+		fmt.Fprintf(logOut, "✓ Vault \"%s\" was recovered and matches its public key. No private keys are shown in %s mode.\n", selectedVault.Name, mode)
+		// in -json mode the addresses are part of the JSON output, so this copy is only for the log, and -quiet leaves it out
+		out := dataOut
+		if appConfig.JSON || appConfig.Quiet {
+			out = logOut
+		}
+		fmt.Fprint(out, renderRecoveredData(publicSections(sections), appConfig.Plain))
+		if appConfig.ExpectedAddress != "" {
+			if !hasAddress(sections, appConfig.ExpectedAddress) {
+				result.Wipe()
+				exitWithError(recovery.WithKind(recovery.ErrPubKeyMismatch, fmt.Errorf("⚠ MISMATCH: the recovered vault does not have the expected address `%s`", appConfig.ExpectedAddress)), appConfig.JSON)
+			}
+			fmt.Fprintf(logOut, "\n✓ MATCH: the recovered vault has the expected address `%s`.\n", appConfig.ExpectedAddress)
+		}
+		balances, balanceWarnings := printBalances(appConfig, result)
+		if appConfig.QR {
+			if err = printQRCodes(out, appConfig, result); err != nil {
+				exitWithError(err, appConfig.JSON)
+			}
+		}
+		qrFiles, qrWarnings := writeQRFiles(appConfig, result)
+		printWarnings(logOut, qrWarnings)
+		printWrittenQRFiles(logOut, qrFiles)
+		printClipboard(appConfig, interrupted, result)
+		verified := newRecoveryJSON(result, appConfig.Network, appConfig.WIFOnly, false, slices.Concat(result.Warnings, balanceWarnings, qrWarnings))
+		verified.selectChains(appConfig.OnlyChains)
+		verified.ExportedFiles = append(verified.ExportedFiles, qrFiles...)
+		verified.SignedMessage, verified.Balances = signed, balances
+		if appConfig.AddressesOnly {
+			verified.Addresses = newAddressesJSON(sections)
+		}
+		return verified, report
 	}
 
-	fmt.Printf("%s%s                %s\n", ui.AnsiCodes["darkGreenBG"], ui.AnsiCodes["bold"], ui.AnsiCodes["reset"])
-	fmt.Printf("%s%s    Success!    %s\n", ui.AnsiCodes["darkGreenBG"], ui.AnsiCodes["bold"], ui.AnsiCodes["reset"])
-	fmt.Printf("%s%s                %s\n", ui.AnsiCodes["darkGreenBG"], ui.AnsiCodes["bold"], ui.AnsiCodes["reset"])
+	// the keys of what may be the wrong vault, or a wrong threshold or nonce, are never shown or exported
+	if appConfig.ExpectedAddress != "" {
+		if !hasAddress(sections, appConfig.ExpectedAddress) {
+			result.Wipe()
+			exitWithError(recovery.WithKind(recovery.ErrPubKeyMismatch, fmt.Errorf("⚠ MISMATCH: the recovered vault does not have the expected address `%s`. No private keys were shown", appConfig.ExpectedAddress)), appConfig.JSON)
+		}
+		fmt.Fprintf(logOut, "✓ MATCH: the recovered vault has the expected address `%s`.\n\n", appConfig.ExpectedAddress)
+	}
 
-	fmt.Printf("\nYour vault has been recovered. Make sure this address matches your vault's Ethereum address.\n")
-	fmt.Printf("%s%s%s\n", ui.AnsiCodes["bold"], address, ui.AnsiCodes["reset"])
+	// the transaction is only signed once the vault is known to be the right one; the keys are output anyway, so a failure is not fatal
+	var sweep *sweepTx
+	var sweepWarnings []recovery.Warning
+	if appConfig.SweepTo != "" {
+		if sweep, err = buildSweep(appConfig, ecSK, result.Address); err != nil {
+			sweepWarnings = []recovery.Warning{{Kind: recovery.WarnSweepFailed, VaultID: result.VaultID,
+				Message: fmt.Sprintf("Could not build the -sweep-to transaction: %s.", err)}}
+			printWarnings(logOut, sweepWarnings)
+		} else {
+			sections = append(sections, sweepSection(sweep))
+		}
+	}
 
-	fmt.Printf("\nHere is your private key for Ethereum and Tron assets. Keep safe and do not share.\n")
-	fmt.Printf("Recovered ECDSA private key (for ETH/MetaMask, Tron/TronLink): %s%s%s\n",
-		ui.AnsiCodes["bold"], hex.EncodeToString(ecSK), ui.AnsiCodes["reset"])
+	// the keys are only output as JSON, without the styling and pauses meant for a person at the screen
+	if appConfig.JSON {
+		filename, exportWarnings := exportWalletFile(appConfig, selectedVault, result, scryptN, scryptP)
+		qrFiles, qrWarnings := writeQRFiles(appConfig, result)
+		exportWarnings = append(exportWarnings, qrWarnings...)
+		printWarnings(logOut, exportWarnings)
+		balances, balanceWarnings := printBalances(appConfig, result)
+		out := newRecoveryJSON(result, appConfig.Network, appConfig.WIFOnly, true, slices.Concat(result.Warnings, sweepWarnings, exportWarnings, balanceWarnings))
+		out.selectChains(appConfig.OnlyChains)
+		out.PrivateKeyMnemonic = phrase
+		out.SignedMessage, out.Balances, out.Sweep = signed, balances, sweep
+		if filename != "" {
+			out.ExportedFiles = append(out.ExportedFiles, filename)
+		}
+		out.ExportedFiles = append(out.ExportedFiles, qrFiles...)
+		return out, report
+	}
 
-	fmt.Printf("\nHere are your private keys for Bitcoin assets. Keep safe and do not share.\n")
-	fmt.Printf("Recovered testnet WIF (for BTC/Electrum Wallet): %s%s%s\n", ui.AnsiCodes["bold"],
-		wif.ToBitcoinWIF(ecSK, true, true), ui.AnsiCodes["reset"])
-	fmt.Printf("Recovered mainnet WIF (for BTC/Electrum Wallet): %s%s%s\n", ui.AnsiCodes["bold"],
-		wif.ToBitcoinWIF(ecSK, false, true), ui.AnsiCodes["reset"])
+	fmt.Fprint(logOut, successBox(appConfig.Plain))
+	fmt.Fprintf(logOut, "\nYour vault has been recovered. Keep these keys safe and do not share them.\n")
 
-	if edSK != nil {
-		fmt.Printf("\nHere is your private key for EDDSA based assets. Keep safe and do not share.\n")
-		fmt.Printf("Recovered EdDSA/Ed25519 private key (for XRPL, SOL, TAO, etc): %s%s%s\n",
-			ui.AnsiCodes["bold"], hex.EncodeToString(edSK), ui.AnsiCodes["reset"])
+	// pausing needs someone at the keyboard, so it is skipped when the tool is scripted
+	interactive := ui.IsInteractive()
+	if (appConfig.RevealDelay > 0 || appConfig.RevealClear || appConfig.RevealOnKey) && !interactive {
+		fmt.Fprintln(logOut, "⚠ -reveal-delay, -reveal-clear and -reveal-on-keypress are ignored as this is not an interactive terminal.")
+	}
+	// the addresses can be checked against the vault's before anyone can see the keys
+	masked := appConfig.RevealOnKey && interactive
+	if masked {
+		fmt.Fprint(dataOut, renderRecoveredData(maskedSections(sections), appConfig.Plain))
+	}
+	if (appConfig.RevealDelay > 0 || masked) && interactive {
+		if err = ui.WaitForReveal(os.Stdin, promptOut, time.Duration(appConfig.RevealDelay)*time.Second); err != nil {
+			exitWithError(err, appConfig.JSON)
+		}
+	}
+	fmt.Fprint(dataOut, renderRecoveredData(sections, appConfig.Plain))
 
-		// load the eddsa private key in edSK and output the public key
-		_, edPK, err2 := edwards.PrivKeyFromScalar(edSK)
-		if err2 != nil {
-			panic("ed25519: internal error: setting scalar failed")
+	if !appConfig.WIFOnly {
+		if result.EdDSAKey == nil {
+			fmt.Fprintln(logOut, "\nNo EdDSA/Ed25519 private key found for this older vault.")
+		}
+		fmt.Fprintf(logOut, "\nNote: Some wallet apps may require you to prefix hex strings with 0x to load the key.\n")
+	}
+	if appConfig.QR {
+		if err = printQRCodes(dataOut, appConfig, result); err != nil {
+			exitWithError(err, appConfig.JSON)
 		}
-		fmt.Printf("Recovered EdDSA/Ed25519 public key (for XRPL tool): %s%s%s\n",
-			ui.AnsiCodes["bold"], hex.EncodeToString(edPK.SerializeCompressed()), ui.AnsiCodes["reset"])
+	}
+	printBalances(appConfig, result)
+	printClipboard(appConfig, interrupted, result)
+	if appConfig.RevealClear && interactive {
+		if err = ui.WaitAndClearScreen(os.Stdin, promptOut); err != nil {
+			exitWithError(err, appConfig.JSON)
+		}
+	}
+	if masked && appConfig.RevealTimeout > 0 {
+		ui.ClearAfter(promptOut, time.Duration(appConfig.RevealTimeout)*time.Second)
+		fmt.Fprint(dataOut, renderRecoveredData(maskedSections(sections), appConfig.Plain))
+	}
 
-	} else {
-		fmt.Println("\nNo EdDSA/Ed25519 private key found for this older vault.")
+	// write out keystore file; the keys are already output, so failing here is not fatal
+	filename, exportWarnings := exportWalletFile(appConfig, selectedVault, result, scryptN, scryptP)
+	printWarnings(logOut, exportWarnings)
+	if filename != "" {
+		fmt.Fprintf(logOut, "\nWrote a MetaMask wallet v3 (for ECDSA key only) to: %s.\n\n", filename)
+	}
+	qrFiles, qrWarnings := writeQRFiles(appConfig, result)
+	printWarnings(logOut, qrWarnings)
+	printWrittenQRFiles(logOut, qrFiles)
+	return recoveryJSON{}, report
+}
+
+// recoveryOptions builds the options of the recovery package from the command line flags.
+func recoveryOptions(appConfig config.AppConfig, vaultID string) recovery.Options {
+	opts := recovery.NewOptions(vaultID)
+	opts.NonceOverride = appConfig.NonceOverride
+	opts.QuorumOverride = appConfig.QuorumOverride
+	opts.AutoThreshold = appConfig.AutoThreshold
+	opts.MinInflatedKB, opts.MaxInflatedKB = appConfig.MinInflatedKB, appConfig.MaxInflatedKB
+	opts.BTCAddressType = appConfig.BTCAddressType
+	opts.Bech32HRP = appConfig.Bech32HRP
+	opts.Coins = appConfig.Coins
+	opts.UncompressedWIF = appConfig.UncompressedWIF
+	opts.Verbose = appConfig.Verbose
+	opts.Progress = logOut
+	opts.ProgressStatus = ui.IsTerminal(logOut)
+	return opts
+}
+
+// exportWalletFile writes the wallet v3 file of the vault if one was requested, and returns its absolute path once written.
+// A missing password or a failed export is returned as a warning, as the keys have been output by then.
+func exportWalletFile(appConfig config.AppConfig, vault ui.VaultPickerItem, result *recovery.Result, scryptN, scryptP int) (string, []recovery.Warning) {
+	filename, vaultID := appConfig.ExportKSFile, vault.VaultID
+	if appConfig.OutputDir != "" {
+		filename = filepath.Join(appConfig.OutputDir, walletFileName(vault))
+	}
+	if filename == "" {
+		return "", nil
+	}
+	if result.ECDSACurve == recovery.CurveP256 {
+		return "", []recovery.Warning{{
+			Kind:    recovery.WarnKeystoreSkipped,
+			VaultID: vaultID,
+			Message: fmt.Sprintf("Vault `%s` is on the P-256 curve, and a wallet v3 file only holds a secp256k1 key. A wallet v3 file will not be created this time.", vaultID),
+		}}
+	}
+	if appConfig.PasswordForKS == "" {
+		return "", []recovery.Warning{{
+			Kind:    recovery.WarnKeystoreSkipped,
+			VaultID: vaultID,
+			Message: fmt.Sprintf("A password is required to export wallet v3 file `%s`; set -password-file or "+passwordEnvVar+", or pass -export to be asked for it. A wallet v3 file will not be created this time.", filename),
+		}}
+	}
+	// the file may be from a prior recovery, e.g. at the default -export path, so it is only replaced on request
+	if err := checkOverwrite(filename, appConfig.Force); err != nil {
+		return "", []recovery.Warning{{
+			Kind:    recovery.WarnKeystoreFailed,
+			VaultID: vaultID,
+			Message: strings.TrimPrefix(err.Error(), "⚠ ") + ". A wallet v3 file will not be created this time.",
+		}}
+	}
+	if err := exportKeystore(filename, appConfig.PasswordForKS, result.ECDSAKey, scryptN, scryptP); err != nil {
+		return "", []recovery.Warning{{Kind: recovery.WarnKeystoreFailed, VaultID: vaultID, Message: err.Error()}}
+	}
+	if abs, err := filepath.Abs(filename); err == nil {
+		filename = abs
+	}
+	return filename, nil
+}
+
+// printBalances looks up the balances of the recovered Ethereum address with -rpc, if set, and prints them to the log.
+// The lookup only warns on a failure, as the vault has been recovered by then.
+func printBalances(appConfig config.AppConfig, result *recovery.Result) ([]balanceJSON, []recovery.Warning) {
+	if appConfig.RPC == "" {
+		return nil, nil
+	}
+	if result.Address == "" {
+		warnings := []recovery.Warning{{Kind: recovery.WarnBalanceFailed, VaultID: result.VaultID,
+			Message: fmt.Sprintf("Vault `%s` has no Ethereum address, so -rpc has no balance to look up.", result.VaultID)}}
+		printWarnings(logOut, warnings)
+		return nil, warnings
+	}
+	balances, warnings := lookupBalances(appConfig.RPC, result.Address, appConfig.RPCTokens)
+	if len(balances) > 0 && !appConfig.JSON {
+		fmt.Fprint(logOut, "\n"+renderRecoveredData([]outputSection{balanceSection(appConfig.RPC, balances)}, appConfig.Plain))
+	}
+	printWarnings(logOut, warnings)
+	return balances, warnings
+}
+
+// printClipboard copies the -clipboard value, if set, and clears the clipboard again. The data is shown by then, so a failure is a warning.
+func printClipboard(appConfig config.AppConfig, interrupted *interrupts, result *recovery.Result) {
+	if !appConfig.Clipboard {
+		return
+	}
+	if err := copyToClipboard(promptOut, interrupted, appConfig, result); err != nil {
+		printWarnings(logOut, []recovery.Warning{{Kind: recovery.WarnClipboardFailed, VaultID: result.VaultID, Message: err.Error() + "."}})
+	}
+}
+
+// printWarnings renders the non-fatal warnings collected during the recovery.
+func printWarnings(out io.Writer, warnings []recovery.Warning) {
+	for _, w := range warnings {
+		fmt.Fprintf(out, "\n%s\n", recovery.Redact(w.String()))
+	}
+	if len(warnings) > 0 {
+		fmt.Fprintln(out)
 	}
-	fmt.Printf("\nNote: Some wallet apps may require you to prefix hex strings with 0x to load the key.\n")
 }
@@ -0,0 +1,464 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/config"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/ui"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tyler-smith/go-bip39"
+)
+
+type (
+	// outputField is a single labelled value of the recovered data block.
+	outputField struct {
+		Label  string
+		Value  string
+		Secret bool
+	}
+
+	// outputSection groups the recovered data for a chain or key type.
+	outputSection struct {
+		Title  string
+		Note   string
+		Fields []outputField
+	}
+)
+
+// Chains for -only-chains, besides the extra coins of the recovery package, e.g. recovery.CoinLitecoin
+const (
+	coinEthereum = "eth"
+	coinBitcoin  = "btc"
+	coinTron     = "tron"
+	coinSolana   = "sol"
+	coinCosmos   = "cosmos"
+)
+
+var (
+	// derivedCoins are the chains of -only-chains whose keys and addresses are only derived on request, as with -coin.
+	derivedCoins = []string{recovery.CoinLitecoin, recovery.CoinDogecoin, recovery.CoinXRP}
+
+	// selectableChains are the chains that -only-chains may name, in the order they are listed in.
+	selectableChains = append([]string{coinEthereum, coinBitcoin, coinTron, coinSolana, coinCosmos}, derivedCoins...)
+
+	// sectionCoins are the chains of the sections that -only-chains selects from. The other sections, e.g. the one of a P-256 key,
+	// are always shown.
+	sectionCoins = map[string]string{
+		"Ethereum":        coinEthereum,
+		"Tron":            coinTron,
+		"Bitcoin":         coinBitcoin,
+		"Cosmos":          coinCosmos,
+		"EdDSA / Ed25519": coinSolana,
+		"Litecoin":        recovery.CoinLitecoin,
+		"Dogecoin":        recovery.CoinDogecoin,
+		"XRP":             recovery.CoinXRP,
+	}
+)
+
+// validateOnlyChains checks that every chain of -only-chains is one that it may name.
+func validateOnlyChains(coins []string) error {
+	for _, coin := range coins {
+		if !slices.Contains(selectableChains, coin) {
+			return fmt.Errorf("unknown -only-chains `%s`, expected one of: %s", coin, strings.Join(selectableChains, ", "))
+		}
+	}
+	return nil
+}
+
+// selectSections keeps the sections of the given coins, or all of them if no coins are given.
+func selectSections(sections []outputSection, coins []string) []outputSection {
+	if len(coins) == 0 {
+		return sections
+	}
+	return slices.DeleteFunc(sections, func(section outputSection) bool {
+		coin, ok := sectionCoins[section.Title]
+		return ok && !slices.Contains(coins, coin)
+	})
+}
+
+// recoveredSections builds the recovered data block, grouped by chain.
+func recoveredSections(result *recovery.Result, network, btcAddressType string, wifOnly bool) []outputSection {
+	sections := make([]outputSection, 0, 4)
+	if result.ECDSACurve == recovery.CurveP256 {
+		sections = append(sections, outputSection{
+			Title: "ECDSA / P-256",
+			Note:  "This vault's ECDSA key is on the NIST P-256 curve (secp256r1), so it has no Ethereum, Tron or Bitcoin address.",
+			Fields: []outputField{
+				{Label: "Public key", Value: result.Chains.ECDSAPublicKey},
+				{Label: "Private key", Value: hex.EncodeToString(result.ECDSAKey), Secret: true},
+			},
+		})
+		return append(sections, eddsaSections(result, true)...)
+	}
+	if !wifOnly {
+		sections = append(sections,
+			outputSection{
+				Title: "Ethereum",
+				Note:  "Make sure this address matches your vault's Ethereum address. Import the private key into MetaMask.",
+				Fields: []outputField{
+					{Label: "Address", Value: result.Chains.Ethereum},
+					{Label: "Public key", Value: result.Chains.ECDSAPublicKey},
+					{Label: "Private key", Value: hex.EncodeToString(result.ECDSAKey), Secret: true},
+				},
+			},
+			outputSection{
+				Title: "Tron",
+				Note:  "Make sure this address matches your vault's Tron address. Import the private key into TronLink.",
+				Fields: []outputField{
+					{Label: "Address", Value: result.Chains.Tron},
+					{Label: "Private key", Value: hex.EncodeToString(result.ECDSAKey), Secret: true},
+				},
+			},
+		)
+	}
+	if !wifOnly && result.Chains.Cosmos != "" {
+		sections = append(sections, outputSection{
+			Title: "Cosmos",
+			Note:  "Make sure this address matches your vault's address on the chain. Import the private key into Keplr.",
+			Fields: []outputField{
+				{Label: "Address", Value: result.Chains.Cosmos},
+				{Label: "Private key", Value: hex.EncodeToString(result.ECDSAKey), Secret: true},
+			},
+		})
+	}
+	sections = append(sections, bitcoinSection(result.Chains, network, btcAddressType))
+	if !wifOnly && result.Chains.Litecoin.WIF != "" {
+		sections = append(sections, litecoinSection(result.Chains.Litecoin, true))
+	}
+	if !wifOnly && result.Chains.Dogecoin.WIF != "" {
+		sections = append(sections, dogecoinSection(result.Chains.Dogecoin, true))
+	}
+	if !wifOnly && result.Chains.XRP != "" {
+		sections = append(sections, outputSection{
+			Title: "XRP",
+			Note:  "Make sure this address matches your vault's XRP address. Import the private key into an XRPL wallet as a secp256k1 key.",
+			Fields: []outputField{
+				{Label: "Address", Value: result.Chains.XRP},
+				{Label: "Private key", Value: hex.EncodeToString(result.ECDSAKey), Secret: true},
+			},
+		})
+	}
+	if !wifOnly {
+		sections = append(sections, eddsaSections(result, true)...)
+	}
+	return sections
+}
+
+// eddsaSections builds the EdDSA section of the recovered data, with its private key if withKey is set, or none for an older vault.
+func eddsaSections(result *recovery.Result, withKey bool) []outputSection {
+	if result.EdDSAKey == nil {
+		return nil
+	}
+	if !withKey {
+		return []outputSection{{
+			Title: "EdDSA / Ed25519",
+			Fields: []outputField{
+				{Label: "Public key", Value: result.Chains.EdDSAPublicKey},
+				{Label: "Solana address", Value: result.Chains.Solana},
+			},
+		}}
+	}
+	return []outputSection{{
+		Title: "EdDSA / Ed25519",
+		Note:  "For XRPL, SOL, TAO, etc. Use the public key with the XRPL tool. Make sure the Solana address matches your vault's.",
+		Fields: []outputField{
+			{Label: "Private key", Value: hex.EncodeToString(result.EdDSAKey), Secret: true},
+			{Label: "Public key", Value: result.Chains.EdDSAPublicKey},
+			{Label: "Solana address", Value: result.Chains.Solana},
+		},
+	}}
+}
+
+// p256Unsupported names the first flag that was given which needs a secp256k1 key, and so can't be used for a vault on P-256.
+func p256Unsupported(appConfig config.AppConfig) string {
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"-wif-only", appConfig.WIFOnly},
+		{"-sign-message", appConfig.SignMessage != ""},
+		{"-verify-against", appConfig.VerifyAgainst != ""},
+		{"-qr", appConfig.QR},
+		{"-qr-file", appConfig.QRFile != ""},
+		{"-qr-private-file", appConfig.QRPrivateFile != ""},
+		{"-sweep-to", appConfig.SweepTo != ""},
+		{"-clipboard", appConfig.Clipboard},
+		{"-coin", len(appConfig.Coins) > 0},
+	} {
+		if f.set {
+			return f.name
+		}
+	}
+	return ""
+}
+
+// addressSections builds the -addresses-only table: every address of the vault, with the Bitcoin addresses of each type,
+// and no private keys. The Cosmos address is included when it was derived. The Bitcoin addresses are those of the WIFs,
+// so for an uncompressed WIF only the legacy address is listed, as the other types need the compressed public key.
+func addressSections(result *recovery.Result, network string, wifOnly, compressed bool) ([]outputSection, error) {
+	sections := make([]outputSection, 0, 5)
+	if result.ECDSACurve == recovery.CurveP256 {
+		sections = append(sections, outputSection{Title: "ECDSA / P-256", Fields: []outputField{{Label: "Public key", Value: result.Chains.ECDSAPublicKey}}})
+		return append(sections, eddsaSections(result, false)...), nil
+	}
+	if !wifOnly {
+		sections = append(sections,
+			outputSection{Title: "Ethereum", Fields: []outputField{{Label: "Address", Value: result.Chains.Ethereum}}},
+			outputSection{Title: "Tron", Fields: []outputField{{Label: "Address", Value: result.Chains.Tron}}},
+		)
+	}
+
+	bitcoin := outputSection{Title: "Bitcoin"}
+	addressTypes := []string{recovery.BTCAddressLegacy, recovery.BTCAddressP2SH, recovery.BTCAddressBech32}
+	if !compressed {
+		addressTypes = addressTypes[:1]
+	}
+	for _, addressType := range addressTypes {
+		chains, err := recovery.DeriveChains(result.ECDSAKey, nil, addressType, compressed)
+		if err != nil {
+			return nil, err
+		}
+		for _, btc := range bitcoinNetworks(chains, network) {
+			bitcoin.Fields = append(bitcoin.Fields, outputField{Label: btc.label + " address (" + addressType + ")", Value: btc.Address})
+		}
+	}
+	sections = append(sections, bitcoin)
+
+	if !wifOnly && result.Chains.Cosmos != "" {
+		sections = append(sections, outputSection{Title: "Cosmos", Fields: []outputField{{Label: "Address", Value: result.Chains.Cosmos}}})
+	}
+	if !wifOnly && result.Chains.Litecoin.WIF != "" {
+		sections = append(sections, litecoinSection(result.Chains.Litecoin, false))
+	}
+	if !wifOnly && result.Chains.Dogecoin.WIF != "" {
+		sections = append(sections, dogecoinSection(result.Chains.Dogecoin, false))
+	}
+	if !wifOnly && result.Chains.XRP != "" {
+		sections = append(sections, outputSection{Title: "XRP", Fields: []outputField{{Label: "Address", Value: result.Chains.XRP}}})
+	}
+	if !wifOnly {
+		sections = append(sections, eddsaSections(result, false)...)
+	}
+	return sections, nil
+}
+
+// keyMnemonic renders a 32-byte private key as the 24-word BIP39 phrase of which it is the entropy. This is a raw key backup,
+// not an HD wallet seed: a wallet that derives keys from the phrase ends up with different keys.
+func keyMnemonic(sk []byte) (string, error) {
+	if len(sk) > 32 {
+		return "", fmt.Errorf("could not render the private key as a BIP39 phrase: it is %d bytes, expected 32", len(sk))
+	}
+	entropy := make([]byte, 32)
+	defer clear(entropy)
+	copy(entropy[32-len(sk):], sk)
+	phrase, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return "", fmt.Errorf("could not render the private key as a BIP39 phrase: %v", err)
+	}
+	return phrase, nil
+}
+
+// keyMnemonicSection outputs the -as-mnemonic phrase of the Ethereum key.
+func keyMnemonicSection(phrase string) outputSection {
+	return outputSection{
+		Title: "Private key as BIP39 phrase",
+		Note: "⚠ This is the raw private key written as 24 words, NOT an HD wallet seed phrase. " +
+			"Only import it into a wallet that takes a phrase as raw key entropy; other wallets derive different keys and addresses from it.",
+		Fields: []outputField{{Label: "Phrase", Value: phrase, Secret: true}},
+	}
+}
+
+// signedMessageSection outputs a -sign-message signature with the address it recovers to.
+func signedMessageSection(signed *signedMessage) outputSection {
+	return outputSection{
+		Title: "Signed message",
+		Note:  "An Ethereum personal_sign signature. Anyone can check it recovers to the address, e.g. on etherscan.io/verifiedSignatures.",
+		Fields: []outputField{
+			{Label: "Message", Value: signed.Message},
+			{Label: "Signature", Value: signed.Signature},
+			{Label: "Address", Value: signed.Address},
+		},
+	}
+}
+
+// bitcoinSection outputs the address and the WIF for the given network, or for both networks if none is specified.
+func bitcoinSection(chains recovery.Chains, network, addressType string) outputSection {
+	section := outputSection{
+		Title: "Bitcoin",
+		Note:  "Make sure the address matches your vault's Bitcoin address. " + electrumImportHint(addressType),
+	}
+	for _, btc := range bitcoinNetworks(chains, network) {
+		section.Fields = append(section.Fields, outputField{Label: btc.label + " address", Value: btc.Address})
+		// the legacy address is shown next to the WIF whatever the address type, as Electrum shows it for a plain WIF
+		if btc.LegacyAddress != "" && btc.LegacyAddress != btc.Address {
+			section.Fields = append(section.Fields, outputField{Label: btc.label + " legacy", Value: btc.LegacyAddress})
+		}
+		section.Fields = append(section.Fields, outputField{Label: btc.label + " WIF", Value: btc.WIF, Secret: true})
+	}
+	return section
+}
+
+// labeledBitcoin is the address and WIF of a vault on one Bitcoin network, with the label of the network.
+type labeledBitcoin struct {
+	label string
+	recovery.Bitcoin
+}
+
+// bitcoinNetworks lists the Bitcoin addresses and WIFs of chains on the given network, or on both if none is given.
+func bitcoinNetworks(chains recovery.Chains, network string) []labeledBitcoin {
+	networks := make([]labeledBitcoin, 0, 2)
+	for _, btc := range []struct {
+		net string
+		recovery.Bitcoin
+	}{{networkTestnet, chains.BitcoinTestnet}, {networkMainnet, chains.BitcoinMainnet}} {
+		if network != "" && network != btc.net {
+			continue
+		}
+		networks = append(networks, labeledBitcoin{label: strings.ToUpper(btc.net[:1]) + btc.net[1:], Bitcoin: btc.Bitcoin})
+	}
+	return networks
+}
+
+// litecoinSection builds the -coin ltc section, with the WIF if withKey is set. An uncompressed WIF has no ltc1 address.
+func litecoinSection(ltc recovery.Litecoin, withKey bool) outputSection {
+	section := outputSection{Title: "Litecoin"}
+	if ltc.Address != "" {
+		section.Fields = append(section.Fields, outputField{Label: "Address", Value: ltc.Address})
+	}
+	section.Fields = append(section.Fields, outputField{Label: "Legacy address", Value: ltc.LegacyAddress})
+	if withKey {
+		section.Note = "Make sure one of these addresses matches your vault's Litecoin address. Import the WIF into Electrum-LTC."
+		section.Fields = append(section.Fields, outputField{Label: "WIF", Value: ltc.WIF, Secret: true})
+	}
+	return section
+}
+
+// dogecoinSection builds the -coin doge section, with the WIF if withKey is set.
+func dogecoinSection(doge recovery.Dogecoin, withKey bool) outputSection {
+	section := outputSection{Title: "Dogecoin", Fields: []outputField{{Label: "Address", Value: doge.Address}}}
+	if withKey {
+		section.Note = "Make sure this address matches your vault's Dogecoin address. Import the WIF into Dogecoin Core or a wallet that takes a WIF."
+		section.Fields = append(section.Fields, outputField{Label: "WIF", Value: doge.WIF, Secret: true})
+	}
+	return section
+}
+
+// electrumImportHint explains how to import the WIF into Electrum so that it derives the shown address type.
+func electrumImportHint(addressType string) string {
+	switch addressType {
+	case recovery.BTCAddressP2SH:
+		return "Import the WIF into Electrum Wallet prefixed with p2wpkh-p2sh: (e.g. p2wpkh-p2sh:K...)."
+	case recovery.BTCAddressBech32:
+		return "Import the WIF into Electrum Wallet prefixed with p2wpkh: (e.g. p2wpkh:K...)."
+	default:
+		return "Import the WIF into Electrum Wallet."
+	}
+}
+
+// publicSections keeps only the fields that are safe to show, such as addresses and public keys.
+// The notes are dropped too, as they explain how to import the private keys.
+func publicSections(sections []outputSection) []outputSection {
+	public := make([]outputSection, 0, len(sections))
+	for _, section := range sections {
+		fields := make([]outputField, 0, len(section.Fields))
+		for _, field := range section.Fields {
+			if !field.Secret {
+				fields = append(fields, field)
+			}
+		}
+		if len(fields) > 0 {
+			section.Fields, section.Note = fields, ""
+			public = append(public, section)
+		}
+	}
+	return public
+}
+
+// maskedSections hides the values of the secret fields, for the view that -reveal-on-keypress shows before and after the keys.
+func maskedSections(sections []outputSection) []outputSection {
+	masked := make([]outputSection, len(sections))
+	for i, section := range sections {
+		section.Fields = slices.Clone(section.Fields)
+		for j := range section.Fields {
+			if section.Fields[j].Secret {
+				section.Fields[j].Value = strings.Repeat("•", 16) + " (hidden)"
+			}
+		}
+		masked[i] = section
+	}
+	return masked
+}
+
+// hasAddress reports whether address is one of the public values of the recovered data.
+// Hex values are compared case-insensitively, so an Ethereum address matches with or without its checksum.
+func hasAddress(sections []outputSection, address string) bool {
+	address = strings.TrimSpace(address)
+	for _, section := range publicSections(sections) {
+		for _, field := range section.Fields {
+			if field.Value == address || (strings.HasPrefix(address, "0x") && strings.EqualFold(field.Value, address)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// renderRecoveredData renders the sections as an aligned key-value block with each value on its own line.
+// In plain mode no styling is applied, for minimal terminals.
+func renderRecoveredData(sections []outputSection, plain bool) string {
+	labelWidth := 0
+	for _, section := range sections {
+		for _, field := range section.Fields {
+			labelWidth = max(labelWidth, len(field.Label))
+		}
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Underline(true)
+	noteStyle := lipgloss.NewStyle().Faint(true)
+	labelStyle := lipgloss.NewStyle().PaddingLeft(2).Width(labelWidth + 5)
+	valueStyle := lipgloss.NewStyle().Bold(true)
+	secretStyle := valueStyle.Foreground(lipgloss.AdaptiveColor{Light: "#B8860B", Dark: "#FFD75F"})
+
+	var b strings.Builder
+	for _, section := range sections {
+		b.WriteString("\n")
+		if plain {
+			fmt.Fprintf(&b, "%s\n", strings.ToUpper(section.Title))
+			if section.Note != "" {
+				fmt.Fprintf(&b, "%s\n", section.Note)
+			}
+			for _, field := range section.Fields {
+				fmt.Fprintf(&b, "  %-*s  %s\n", labelWidth+1, field.Label+":", field.Value)
+			}
+			continue
+		}
+		b.WriteString(titleStyle.Render(strings.ToUpper(section.Title)) + "\n")
+		if section.Note != "" {
+			b.WriteString(noteStyle.Render(section.Note) + "\n")
+		}
+		for _, field := range section.Fields {
+			style := valueStyle
+			if field.Secret {
+				style = secretStyle
+			}
+			b.WriteString(labelStyle.Render(field.Label) + style.Render(field.Value) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// successBox renders the success banner shown once a vault has been recovered.
+func successBox(plain bool) string {
+	if plain {
+		return "Success!\n"
+	}
+	return ui.SuccessBox()
+}
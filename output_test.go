@@ -0,0 +1,307 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/config"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+	"github.com/stretchr/testify/assert"
+)
+
+// testResult builds a recovered vault from hex encoded keys. An empty edSK is an older vault without EdDSA shares.
+func testResult(t *testing.T, ecSK, edSK string) *recovery.Result {
+	t.Helper()
+	result := &recovery.Result{VaultID: "v1", Name: "A"}
+	result.ECDSAKey, _ = hex.DecodeString(ecSK)
+	if edSK != "" {
+		result.EdDSAKey, _ = hex.DecodeString(edSK)
+	}
+	chains, err := recovery.DeriveChains(result.ECDSAKey, result.EdDSAKey, recovery.BTCAddressBech32, true)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	result.Address, result.Chains = chains.Ethereum, chains
+	return result
+}
+
+func TestRenderRecoveredData_Plain(t *testing.T) {
+
+	sections := recoveredSections(testResult(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", ""), networkMainnet, recovery.BTCAddressBech32, false)
+	if !assert.Len(t, sections, 3) {
+		return
+	}
+	out := renderRecoveredData(sections, true)
+	if !assert.NotContains(t, out, "\033[") {
+		return
+	}
+	assert.Contains(t, out, "ETHEREUM\n")
+	assert.Contains(t, out, "  Address:          0xeFb4d67625fE88A4B7Cb854b75f6B1ef370550d1\n")
+	assert.Contains(t, out, "  Private key:      0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7\n")
+	assert.Contains(t, out, "  Mainnet address:  bc1q")
+	// the legacy address of the WIF is shown next to the address of the chosen type
+	assert.Contains(t, out, "  Mainnet legacy:   1Lj5YX3YMqboYFyAJVftNyid1844ZFxQ68\n")
+	assert.Contains(t, out, "  Mainnet WIF:      ")
+	assert.NotContains(t, out, "Testnet WIF")
+}
+
+func TestRecoveredSections_WIFOnly(t *testing.T) {
+
+	sections := recoveredSections(testResult(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44"), "", recovery.BTCAddressBech32, true)
+	if !assert.Len(t, sections, 1) {
+		return
+	}
+	assert.Equal(t, "Bitcoin", sections[0].Title)
+	assert.Len(t, sections[0].Fields, 6)
+}
+
+func TestRecoveredSections_EdDSA(t *testing.T) {
+
+	sections := recoveredSections(testResult(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44"), "", recovery.BTCAddressBech32, false)
+	if !assert.Len(t, sections, 4) {
+		return
+	}
+	assert.Equal(t, []outputField{
+		{Label: "Private key", Value: "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44", Secret: true},
+		{Label: "Public key", Value: "fdae759228c8a6fcd37a5c3dc20d23e6d058795e5724eafd2b658a97c0edf0d9"},
+		{Label: "Solana address", Value: "J5GSXo8C1CGCy6qZJE7JwN93xuZ7R4LWGfXta7R697ig"},
+	}, sections[3].Fields)
+}
+
+func TestRecoveredSections_Cosmos(t *testing.T) {
+	result := testResult(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", "")
+	result.Chains.Cosmos = "cosmos1w508d6qejxtdg4y5r3zarvary0c5xw7k6ah60c"
+
+	sections := recoveredSections(result, "", recovery.BTCAddressBech32, false)
+	if !assert.Len(t, sections, 4) {
+		return
+	}
+	assert.Equal(t, "Cosmos", sections[2].Title)
+	assert.Equal(t, "cosmos1w508d6qejxtdg4y5r3zarvary0c5xw7k6ah60c", sections[2].Fields[0].Value)
+
+	// the Cosmos address is not shown to a Bitcoin user
+	sections = recoveredSections(result, "", recovery.BTCAddressBech32, true)
+	assert.Len(t, sections, 1)
+}
+
+func TestRecoveredSections_Litecoin(t *testing.T) {
+	result := testResult(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", "")
+	result.Chains.Litecoin = recovery.Litecoin{Address: "ltc1q...", LegacyAddress: "L...", WIF: "T..."}
+
+	sections := recoveredSections(result, "", recovery.BTCAddressBech32, false)
+	if !assert.Len(t, sections, 4) {
+		return
+	}
+	assert.Equal(t, "Litecoin", sections[3].Title)
+	assert.Equal(t, []outputField{
+		{Label: "Address", Value: "ltc1q..."},
+		{Label: "Legacy address", Value: "L..."},
+		{Label: "WIF", Value: "T...", Secret: true},
+	}, sections[3].Fields)
+
+	// the addresses of an uncompressed WIF have no ltc1 one, and the table of addresses has no WIF
+	result.Chains.Litecoin.Address = ""
+	sections, err := addressSections(result, "", false, true)
+	if !assert.NoError(t, err) || !assert.Len(t, sections, 4) {
+		return
+	}
+	assert.Equal(t, []outputField{{Label: "Legacy address", Value: "L..."}}, sections[3].Fields)
+}
+
+func TestRecoveredSections_Dogecoin(t *testing.T) {
+	result := testResult(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", "")
+	result.Chains.Litecoin = recovery.Litecoin{LegacyAddress: "L...", WIF: "6..."}
+	result.Chains.Dogecoin = recovery.Dogecoin{Address: "D...", WIF: "Q..."}
+
+	sections := recoveredSections(result, "", recovery.BTCAddressBech32, false)
+	if !assert.Len(t, sections, 5) {
+		return
+	}
+	assert.Equal(t, "Dogecoin", sections[4].Title)
+	assert.Equal(t, []outputField{{Label: "Address", Value: "D..."}, {Label: "WIF", Value: "Q...", Secret: true}}, sections[4].Fields)
+
+	// not shown to a Bitcoin user
+	sections = recoveredSections(result, "", recovery.BTCAddressBech32, true)
+	assert.Len(t, sections, 1)
+}
+
+func TestRecoveredSections_XRP(t *testing.T) {
+	result := testResult(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", "")
+	result.Chains.XRP = "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"
+
+	sections := recoveredSections(result, "", recovery.BTCAddressBech32, false)
+	if !assert.Len(t, sections, 4) {
+		return
+	}
+	assert.Equal(t, "XRP", sections[3].Title)
+	assert.Equal(t, []outputField{
+		{Label: "Address", Value: "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"},
+		{Label: "Private key", Value: "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", Secret: true},
+	}, sections[3].Fields)
+	assert.True(t, hasAddress(sections, "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"))
+}
+
+func TestSelectSections(t *testing.T) {
+	result := testResult(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44")
+	titles := func(sections []outputSection) []string {
+		titles := make([]string, 0, len(sections))
+		for _, section := range sections {
+			titles = append(titles, section.Title)
+		}
+		return titles
+	}
+
+	sections := recoveredSections(result, "", recovery.BTCAddressBech32, false)
+	assert.Equal(t, []string{"Ethereum", "Tron", "Bitcoin", "EdDSA / Ed25519"}, titles(selectSections(sections, nil)))
+	sections = recoveredSections(result, "", recovery.BTCAddressBech32, false)
+	assert.Equal(t, []string{"Bitcoin", "EdDSA / Ed25519"}, titles(selectSections(sections, []string{coinSolana, coinBitcoin})))
+
+	// the key of a P-256 vault is shown whichever coins are selected
+	p256 := []outputSection{{Title: "ECDSA / P-256"}}
+	assert.Equal(t, []string{"ECDSA / P-256"}, titles(selectSections(p256, []string{coinBitcoin})))
+}
+
+func TestValidateOnlyChains(t *testing.T) {
+	assert.NoError(t, validateOnlyChains([]string{coinEthereum, recovery.CoinXRP}))
+	assert.EqualError(t, validateOnlyChains([]string{"btc", "bitcoin"}), "unknown -only-chains `bitcoin`, expected one of: eth, btc, tron, sol, cosmos, ltc, doge, xrp")
+}
+
+func TestRecoveredSections_P256(t *testing.T) {
+	result := &recovery.Result{VaultID: "v1", Name: "A", ECDSACurve: recovery.CurveP256}
+	result.ECDSAKey, _ = hex.DecodeString("c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721")
+	result.Chains.ECDSAPublicKey = "0360fed4ba255a9d31c961eb74c6356d68c049b8923b61fa6ce669622e60f29fb6"
+
+	sections := recoveredSections(result, "", recovery.BTCAddressBech32, false)
+	if !assert.Len(t, sections, 1) {
+		return
+	}
+	assert.Equal(t, "ECDSA / P-256", sections[0].Title)
+	assert.Equal(t, []outputField{
+		{Label: "Public key", Value: "0360fed4ba255a9d31c961eb74c6356d68c049b8923b61fa6ce669622e60f29fb6"},
+		{Label: "Private key", Value: "c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721", Secret: true},
+	}, sections[0].Fields)
+
+	// there are no Bitcoin addresses to derive
+	sections, err := addressSections(result, "", false, true)
+	if !assert.NoError(t, err) || !assert.Len(t, sections, 1) {
+		return
+	}
+	assert.Equal(t, []outputField{{Label: "Public key", Value: "0360fed4ba255a9d31c961eb74c6356d68c049b8923b61fa6ce669622e60f29fb6"}}, sections[0].Fields)
+}
+
+func TestP256Unsupported(t *testing.T) {
+	assert.Equal(t, "", p256Unsupported(config.AppConfig{AddressesOnly: true, VerifyOnly: true}))
+	assert.Equal(t, "-wif-only", p256Unsupported(config.AppConfig{WIFOnly: true}))
+	assert.Equal(t, "-sign-message", p256Unsupported(config.AppConfig{SignMessage: "hello"}))
+	assert.Equal(t, "-qr-file", p256Unsupported(config.AppConfig{QRFile: "vault.png"}))
+}
+
+func TestPublicSections(t *testing.T) {
+
+	sections := recoveredSections(testResult(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44"), "", recovery.BTCAddressBech32, false)
+	public := publicSections(sections)
+	if !assert.Len(t, public, 4) {
+		return
+	}
+	for _, section := range public {
+		for _, field := range section.Fields {
+			assert.False(t, field.Secret, field.Label)
+		}
+	}
+	out := renderRecoveredData(public, true)
+	assert.NotContains(t, out, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7")
+	assert.NotContains(t, out, "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44")
+	assert.NotContains(t, out, "WIF")
+
+	tests := []struct {
+		address  string
+		expected bool
+	}{
+		{"0xeFb4d67625fE88A4B7Cb854b75f6B1ef370550d1", true},
+		{"0xefb4d67625fe88a4b7cb854b75f6b1ef370550d1", true},
+		{"J5GSXo8C1CGCy6qZJE7JwN93xuZ7R4LWGfXta7R697ig", true},
+		{"j5gsxo8c1cgcy6qzje7jwn93xuz7r4lwgfxta7r697ig", false},
+		{"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", false},
+		// a private key is never matched
+		{"0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, hasAddress(sections, tt.address), tt.address)
+	}
+}
+
+func TestMaskedSections(t *testing.T) {
+	sections := recoveredSections(testResult(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44"), "", recovery.BTCAddressBech32, false)
+	out := renderRecoveredData(maskedSections(sections), true)
+	assert.NotContains(t, out, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7")
+	assert.NotContains(t, out, "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44")
+	assert.Contains(t, out, "0xeFb4d67625fE88A4B7Cb854b75f6B1ef370550d1")
+	assert.Contains(t, out, "Private key:      •••••••••••••••• (hidden)")
+
+	// the sections themselves keep the keys, for the reveal
+	assert.Contains(t, renderRecoveredData(sections, true), "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7")
+}
+
+func TestAddressSections(t *testing.T) {
+	result := testResult(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44")
+	result.Chains.Cosmos = "cosmos1mp06wfhdpcyhtswjkvzjkx0g3p8n32ckjfh059"
+
+	sections, err := addressSections(result, networkMainnet, false, true)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, sections, 5) {
+		return
+	}
+	assert.Equal(t, []outputField{
+		{Label: "Mainnet address (legacy)", Value: "1Lj5YX3YMqboYFyAJVftNyid1844ZFxQ68"},
+		{Label: "Mainnet address (p2sh)", Value: "3ERtaTcNeYHhcaNRXHggmMqMxtidxdmpx8"},
+		{Label: "Mainnet address (bech32)", Value: "bc1qmp06wfhdpcyhtswjkvzjkx0g3p8n32ckynfysg"},
+	}, sections[2].Fields)
+	assert.Equal(t, "Cosmos", sections[3].Title)
+	// no private key is part of the table
+	out := renderRecoveredData(sections, true)
+	assert.NotContains(t, out, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7")
+	assert.NotContains(t, out, "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44")
+	assert.NotContains(t, out, result.Chains.BitcoinMainnet.WIF)
+
+	// the address of an uncompressed WIF is that of the uncompressed public key, and there is no p2sh or bech32 one
+	sections, err = addressSections(result, networkMainnet, false, false)
+	if !assert.NoError(t, err) || !assert.Len(t, sections, 5) {
+		return
+	}
+	assert.Equal(t, []outputField{{Label: "Mainnet address (legacy)", Value: "1HvUzCUm68M7od61Vsj1dooWfxzTuvH6Bg"}}, sections[2].Fields)
+}
+
+func TestKeyMnemonic(t *testing.T) {
+	tests := []struct {
+		name     string
+		skHex    string
+		expected string
+	}{
+		// BIP39 test vectors of 256-bit entropy
+		{"Zero Entropy", "0000000000000000000000000000000000000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art"},
+		{"7f Entropy", "7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			"legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title"},
+		// a short scalar is left-padded to 32 bytes
+		{"Short Key", "00", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sk, _ := hex.DecodeString(tt.skHex)
+			phrase, err := keyMnemonic(sk)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expected, phrase)
+		})
+	}
+
+	_, err := keyMnemonic(make([]byte, 33))
+	assert.Error(t, err)
+}
@@ -0,0 +1,149 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/config"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/ui"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+	"github.com/skip2/go-qrcode"
+)
+
+// qrCode renders content as a QR code of Unicode half blocks, with two rows of modules per line, for a terminal.
+func qrCode(content string) (string, error) {
+	q, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return "", fmt.Errorf("could not render the QR code: %v", err)
+	}
+	return q.ToSmallString(false), nil
+}
+
+// qrFileSize is the width and height of a -qr-file PNG, in pixels.
+const qrFileSize = 512
+
+// qrFileValues picks the values of the -qr-file and -qr-private-file PNGs: the Ethereum address and private key,
+// or with -wif-only, the Bitcoin address and WIF of the -network, mainnet unless testnet was chosen.
+func qrFileValues(result *recovery.Result, network string, wifOnly bool) (address, key string) {
+	if !wifOnly {
+		return result.Chains.Ethereum, hex.EncodeToString(result.ECDSAKey)
+	}
+	if network == networkTestnet {
+		return result.Chains.BitcoinTestnet.Address, result.Chains.BitcoinTestnet.WIF
+	}
+	return result.Chains.BitcoinMainnet.Address, result.Chains.BitcoinMainnet.WIF
+}
+
+// writeQRFile writes content as a PNG QR code, only readable by the user like the wallet v3 file,
+// and returns its absolute path. An existing file is only replaced with force.
+func writeQRFile(filename, content string, force bool) (string, error) {
+	if err := checkOverwrite(filename, force); err != nil {
+		return "", err
+	}
+	png, err := qrcode.Encode(content, qrcode.Medium, qrFileSize)
+	if err != nil {
+		return "", fmt.Errorf("could not render the QR code: %v", err)
+	}
+	if err = writeFileAtomic(filename, png, keystoreFileMode); err != nil {
+		return "", fmt.Errorf("could not write the QR code file `%s`: %v", filename, err)
+	}
+	if abs, err := filepath.Abs(filename); err == nil {
+		filename = abs
+	}
+	return filename, nil
+}
+
+// writeQRFiles writes the -qr-file and -qr-private-file PNGs that were asked for, and returns the names of those written.
+// The keys have been output by then, so a failure is returned as a warning.
+func writeQRFiles(appConfig config.AppConfig, result *recovery.Result) ([]string, []recovery.Warning) {
+	address, key := qrFileValues(result, appConfig.Network, appConfig.WIFOnly)
+	files := []struct{ filename, content string }{{appConfig.QRFile, address}}
+	if !appConfig.VerifyOnly {
+		files = append(files, struct{ filename, content string }{appConfig.QRPrivateFile, key})
+	}
+	var written []string
+	var warnings []recovery.Warning
+	for _, file := range files {
+		if file.filename == "" {
+			continue
+		}
+		filename, err := writeQRFile(file.filename, file.content, appConfig.Force)
+		if err != nil {
+			warnings = append(warnings, recovery.Warning{
+				Kind:    recovery.WarnQRFileFailed,
+				VaultID: result.VaultID,
+				Message: strings.TrimPrefix(err.Error(), "⚠ ") + ". The QR code file will not be created this time.",
+			})
+			continue
+		}
+		written = append(written, filename)
+	}
+	return written, warnings
+}
+
+func printWrittenQRFiles(out io.Writer, files []string) {
+	for _, filename := range files {
+		fmt.Fprintf(out, "\nWrote a QR code to: %s.\n", filename)
+	}
+}
+
+// qrFields picks the values to show as -qr codes: the Ethereum address, or the Bitcoin addresses with -wif-only,
+// and with withKeys, the private key and the WIFs.
+func qrFields(result *recovery.Result, network string, wifOnly, withKeys bool) []outputField {
+	fields := make([]outputField, 0, 4)
+	if !wifOnly {
+		fields = append(fields, outputField{Label: "Ethereum address", Value: result.Chains.Ethereum})
+		if withKeys {
+			fields = append(fields, outputField{Label: "Ethereum private key", Value: hex.EncodeToString(result.ECDSAKey), Secret: true})
+		}
+	}
+	for _, btc := range []struct {
+		net string
+		recovery.Bitcoin
+	}{{networkTestnet, result.Chains.BitcoinTestnet}, {networkMainnet, result.Chains.BitcoinMainnet}} {
+		if network != "" && network != btc.net {
+			continue
+		}
+		label := "Bitcoin " + btc.net
+		if wifOnly {
+			fields = append(fields, outputField{Label: label + " address", Value: btc.Address})
+		}
+		if withKeys {
+			fields = append(fields, outputField{Label: label + " WIF", Value: btc.WIF, Secret: true})
+		}
+	}
+	return fields
+}
+
+// printQRCodes outputs the -qr codes of the recovered vault. The private key codes of -qr-private are only shown
+// once confirmed, which needs an interactive terminal.
+func printQRCodes(out io.Writer, appConfig config.AppConfig, result *recovery.Result) error {
+	withKeys := false
+	if appConfig.QRPrivate && !appConfig.VerifyOnly {
+		if !ui.IsInteractive() {
+			fmt.Fprintln(logOut, "\n⚠ -qr-private needs an interactive terminal to confirm; the private key QR codes are not shown.")
+		} else {
+			confirmed, err := ui.Confirm(os.Stdin, promptOut, "Show the private keys as QR codes? Anyone who can see or record your screen can import them.")
+			if err != nil {
+				return err
+			}
+			withKeys = confirmed
+		}
+	}
+	for _, field := range qrFields(result, appConfig.Network, appConfig.WIFOnly, withKeys) {
+		code, err := qrCode(field.Value)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "\n%s\n%s", strings.ToUpper(field.Label), code)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQRFields(t *testing.T) {
+	result := testResult(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", "")
+
+	// only the address unless the private keys were confirmed
+	fields := qrFields(result, "", false, false)
+	assert.Equal(t, []outputField{{Label: "Ethereum address", Value: "0xeFb4d67625fE88A4B7Cb854b75f6B1ef370550d1"}}, fields)
+
+	fields = qrFields(result, networkMainnet, false, true)
+	if !assert.Len(t, fields, 3) {
+		return
+	}
+	assert.Equal(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", fields[1].Value)
+	assert.Equal(t, result.Chains.BitcoinMainnet.WIF, fields[2].Value)
+
+	fields = qrFields(result, networkTestnet, true, false)
+	assert.Equal(t, []outputField{{Label: "Bitcoin testnet address", Value: result.Chains.BitcoinTestnet.Address}}, fields)
+}
+
+func TestQRCode(t *testing.T) {
+	code, err := qrCode("0xeFb4d67625fE88A4B7Cb854b75f6B1ef370550d1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	// two rows of modules per line, of half and full blocks
+	assert.Contains(t, code, "█")
+	assert.Greater(t, len(code), 100)
+}
+
+func TestWriteQRFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "address.png")
+
+	written, err := writeQRFile(filename, "0xeFb4d67625fE88A4B7Cb854b75f6B1ef370550d1", false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, filepath.IsAbs(written))
+	png, err := os.ReadFile(filename)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "\x89PNG", string(png[:4]))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filename)
+		if assert.NoError(t, err) {
+			assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		}
+	}
+
+	// an existing file is only replaced with force
+	_, err = writeQRFile(filename, "0xeFb4d67625fE88A4B7Cb854b75f6B1ef370550d1", false)
+	assert.Error(t, err)
+	_, err = writeQRFile(filename, "0xeFb4d67625fE88A4B7Cb854b75f6B1ef370550d1", true)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/bech32"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/wif"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/ripemd160"
+	"golang.org/x/crypto/sha3"
+)
+
+// tronAddressVersion prefixes the 20-byte account of a Tron address, on mainnet and the testnets alike.
+const tronAddressVersion = 0x41
+
+// toTronAddress encodes the account of a secp256k1 public key as a Tron address.
+// The account is derived like an Ethereum one, then prefixed with 0x41 and base58check encoded.
+func toTronAddress(pub *secp256k1.PublicKey) string {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(pub.SerializeUncompressed()[1:])
+	sum := hash.Sum(nil)
+	return wif.Base58CheckEncode(tronAddressVersion, sum[len(sum)-20:])
+}
+
+// CosmosHRP is the human readable part of Cosmos Hub addresses. Other Cosmos SDK chains use their own, e.g. osmo or celestia.
+const CosmosHRP = "cosmos"
+
+// toCosmosAddress bech32-encodes the Cosmos SDK account address of a secp256k1 public key, RIPEMD160(SHA256(compressed key)),
+// with the human readable part of the chain.
+func toCosmosAddress(pub *secp256k1.PublicKey, hrp string) string {
+	return bech32.Encode(hrp, hash160(pub.SerializeCompressed()))
+}
+
+// xrpAccountVersion prefixes the 20-byte account ID of an XRP Ledger classic address.
+const xrpAccountVersion = 0x00
+
+// toXRPAddress encodes the account ID of a secp256k1 public key, RIPEMD160(SHA256(compressed key)), as an XRP Ledger
+// classic address (r...), which is base58check encoded with Ripple's alphabet.
+func toXRPAddress(pub *secp256k1.PublicKey) string {
+	return wif.RippleBase58CheckEncode(xrpAccountVersion, hash160(pub.SerializeCompressed()))
+}
+
+// toSolanaAddress encodes a 32-byte Ed25519 public key as a Solana address, which is the key itself in base58.
+func toSolanaAddress(edPK []byte) (string, error) {
+	if len(edPK) != 32 {
+		return "", fmt.Errorf("ed25519 public key is %d bytes, expected 32", len(edPK))
+	}
+	return wif.Base58Encode(edPK), nil
+}
+
+// Bitcoin address types for Options.BTCAddressType
+const (
+	BTCAddressLegacy = "legacy"
+	BTCAddressP2SH   = "p2sh"
+	BTCAddressBech32 = "bech32"
+)
+
+// Bitcoin address versions and human readable parts
+const (
+	btcP2PKHMainnet = 0x00
+	btcP2PKHTestnet = 0x6f
+	btcP2SHMainnet  = 0x05
+	btcP2SHTestnet  = 0xc4
+	btcHRPMainnet   = "bc"
+	btcHRPTestnet   = "tb"
+)
+
+// toBitcoinAddress derives the address of the given type for a secp256k1 public key, in its compressed or uncompressed form.
+// p2sh is a P2WPKH address nested in P2SH, and bech32 a native P2WPKH address; both need the compressed key.
+func toBitcoinAddress(pub *secp256k1.PublicKey, addressType string, testNet, compressed bool) (string, error) {
+	if !compressed {
+		if addressType != BTCAddressLegacy {
+			return "", fmt.Errorf("a %s address needs a compressed public key; use the %s address type with an uncompressed WIF", addressType, BTCAddressLegacy)
+		}
+		if testNet {
+			return wif.Base58CheckEncode(btcP2PKHTestnet, hash160(pub.SerializeUncompressed())), nil
+		}
+		return wif.Base58CheckEncode(btcP2PKHMainnet, hash160(pub.SerializeUncompressed())), nil
+	}
+	pkHash := hash160(pub.SerializeCompressed())
+	switch addressType {
+	case BTCAddressLegacy:
+		if testNet {
+			return wif.Base58CheckEncode(btcP2PKHTestnet, pkHash), nil
+		}
+		return wif.Base58CheckEncode(btcP2PKHMainnet, pkHash), nil
+	case BTCAddressP2SH:
+		// the redeem script is the P2WPKH witness program: OP_0 <20-byte key hash>
+		scriptHash := hash160(append([]byte{0x00, 0x14}, pkHash...))
+		if testNet {
+			return wif.Base58CheckEncode(btcP2SHTestnet, scriptHash), nil
+		}
+		return wif.Base58CheckEncode(btcP2SHMainnet, scriptHash), nil
+	case BTCAddressBech32:
+		if testNet {
+			return bech32.EncodeSegwitAddress(btcHRPTestnet, 0, pkHash)
+		}
+		return bech32.EncodeSegwitAddress(btcHRPMainnet, 0, pkHash)
+	default:
+		return "", fmt.Errorf("unknown -btc-address-type `%s`, expected %s, %s or %s", addressType, BTCAddressLegacy, BTCAddressP2SH, BTCAddressBech32)
+	}
+}
+
+func hash160(b []byte) []byte {
+	sha := sha256.Sum256(b)
+	h := ripemd160.New()
+	h.Write(sha[:])
+	return h.Sum(nil)
+}
@@ -0,0 +1,130 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToSolanaAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		pkHex    string
+		expected string
+		wantErr  bool
+	}{
+		// RFC 8032 test 1 public key
+		{"RFC 8032", "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a", "FVen3X669xLzsi6N2V91DoiyzHzg1uAgqiT8jZ9nS96Z", false},
+		// the Solana system program
+		{"All Zeros", "0000000000000000000000000000000000000000000000000000000000000000", "11111111111111111111111111111111", false},
+		{"Leading Zeros", "0000" + "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", "11tJ93RwaVfE1PEMxd5rpZZuPtLCwbEaDCrNBhAy8Cv", false},
+		{"Wrong Length", "d75a980182b10ab7", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pk, _ := hex.DecodeString(tt.pkHex)
+			address, err := toSolanaAddress(pk)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expected, address)
+		})
+	}
+}
+
+func TestToXRPAddress(t *testing.T) {
+	// the genesis account of the XRP Ledger, from the address encoding example of the XRP Ledger docs
+	pk, _ := hex.DecodeString("0330E7FC9D56BB25D6893BA3F317AE5BCF33B3291BD63DB32654A313222F7FD020")
+	pub, err := secp256k1.ParsePubKey(pk)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh", toXRPAddress(pub))
+}
+
+func TestToTronAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		skHex    string
+		expected string
+	}{
+		{"Private Key 1", "0000000000000000000000000000000000000000000000000000000000000001", "TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC"},
+		// the Nile and Shasta testnets use the mainnet version byte, so the testnet address of a key is its mainnet one
+		{"Private Key 1 Testnet", "0000000000000000000000000000000000000000000000000000000000000001", "TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC"},
+		// the fixture vault with Ethereum address 0x620Ac72121234f1b313BD4e8b78C81323502679A
+		{"Fixture Vault", "4cc05b1d3216da8ef91729744159019b25ea1ed5932e387199f1de6ff6667ac2", "TJuc8iWUhBSRv8BUwpGutAQszJS6BDnjCu"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sk, _ := hex.DecodeString(tt.skHex)
+			assert.Equal(t, tt.expected, toTronAddress(secp256k1.PrivKeyFromBytes(sk).PubKey()))
+		})
+	}
+}
+
+func TestToBitcoinAddress(t *testing.T) {
+	// the public key of private key 1, i.e. the generator point
+	sk, _ := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000001")
+	pub := secp256k1.PrivKeyFromBytes(sk).PubKey()
+
+	tests := []struct {
+		addressType string
+		testNet     bool
+		compressed  bool
+		expected    string
+	}{
+		{BTCAddressLegacy, false, true, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"},
+		{BTCAddressLegacy, true, true, "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r"},
+		{BTCAddressLegacy, false, false, "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm"},
+		{BTCAddressLegacy, true, false, "mtoKs9V381UAhUia3d7Vb9GNak8Qvmcsme"},
+		{BTCAddressP2SH, false, true, "3JvL6Ymt8MVWiCNHC7oWU6nLeHNJKLZGLN"},
+		{BTCAddressP2SH, true, true, "2NAUYAHhujozruyzpsFRP63mbrdaU5wnEpN"},
+		// BIP 173 test vectors
+		{BTCAddressBech32, false, true, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		{BTCAddressBech32, true, true, "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			address, err := toBitcoinAddress(pub, tt.addressType, tt.testNet, tt.compressed)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expected, address)
+		})
+	}
+
+	_, err := toBitcoinAddress(pub, "taproot", false, true)
+	assert.Error(t, err)
+	// segwit addresses need the compressed key
+	_, err = toBitcoinAddress(pub, BTCAddressBech32, false, false)
+	assert.Error(t, err)
+}
+
+func TestToCosmosAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		skHex    string
+		hrp      string
+		expected string
+	}{
+		// the account bytes are the key hash of the BIP173 P2WPKH vector bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4
+		{"Cosmos Hub", "0000000000000000000000000000000000000000000000000000000000000001", CosmosHRP, "cosmos1w508d6qejxtdg4y5r3zarvary0c5xw7k6ah60c"},
+		{"Osmosis", "0000000000000000000000000000000000000000000000000000000000000001", "osmo", "osmo1w508d6qejxtdg4y5r3zarvary0c5xw7kjxy2e2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sk, _ := hex.DecodeString(tt.skHex)
+			assert.Equal(t, tt.expected, toCosmosAddress(secp256k1.PrivKeyFromBytes(sk).PubKey(), tt.hrp))
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/secmem"
+	ecdsa_keygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+	eddsa_keygen "github.com/binance-chain/tss-lib/eddsa/keygen"
+)
+
+type (
+	// shareCache keeps the decoded shares of the runTool attempts of one recovery, e.g. one attempt per reshare nonce,
+	// so that each share is inflated and decoded only once. It holds share secrets, so its owner wipes it once done.
+	// A nil cache caches nothing.
+	shareCache struct {
+		mu     sync.Mutex
+		shares map[shareKey]any
+	}
+
+	// shareKey identifies an encoded share by its ShareID, which a V2 share carries in its prefix, and the SHA-256 of
+	// the encoded share, as a share may keep its ID across reshares while its data changes. The ID is empty for a legacy share.
+	shareKey struct {
+		shareID string
+		digest  [sha256.Size]byte
+	}
+)
+
+func newShareCache() *shareCache {
+	return &shareCache{shares: make(map[shareKey]any, 16)}
+}
+
+func newShareKey(strShare string) shareKey {
+	key := shareKey{digest: sha256.Sum256([]byte(strShare))}
+	if v2Share, ok := strings.CutPrefix(strShare, v2MagicPrefix); ok {
+		key.shareID, _, _ = strings.Cut(v2Share, "_")
+	}
+	return key
+}
+
+// cachedShare returns a copy of the cached share, which the caller may wipe, or false if the share was not decoded yet.
+func cachedShare[T SaveData](c *shareCache, key shareKey) (*T, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	share, ok := c.shares[key].(*T)
+	if !ok {
+		return nil, false
+	}
+	return copyShare(share), true
+}
+
+// cacheShare caches a copy of a decoded share, so that the caller keeps the share itself and may wipe it.
+func cacheShare[T SaveData](c *shareCache, key shareKey, share *T) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.shares[key] = copyShare(share)
+}
+
+// wipe overwrites the Xi value of every cached share and empties the cache.
+func (c *shareCache) wipe() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, share := range c.shares {
+		wipeShareSecret(share)
+		delete(c.shares, key)
+	}
+}
+
+// copyShare copies a share with its own Xi value, so that wiping the copy leaves the share intact. The other fields are
+// shared, as they are public or only read.
+func copyShare[T SaveData](share *T) *T {
+	c := *share
+	switch sd := any(&c).(type) {
+	case *ecdsa_keygen.LocalPartySaveData:
+		sd.Xi = copyInt(sd.Xi)
+	case *eddsa_keygen.LocalPartySaveData:
+		sd.Xi = copyInt(sd.Xi)
+	}
+	return &c
+}
+
+// copyInt copies a secret number, locked into RAM like the original when -mlock is enabled. A nil number stays nil.
+func copyInt(i *big.Int) *big.Int {
+	if i == nil {
+		return nil
+	}
+	c := new(big.Int).Set(i)
+	secmem.LockInt(c)
+	return c
+}
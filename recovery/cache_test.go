@@ -0,0 +1,55 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"context"
+	"testing"
+
+	ecdsa_keygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareCache_ReusedAcrossRuns(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_single.json", Mnemonics: mmNewSingle},
+	}
+	vaultID := "phrot42ltzawmn7nrm7mqvl5"
+	opts := NewOptions(vaultID)
+	opts.shares = newShareCache()
+
+	address, _, _, _, _, err := runTool(context.Background(), files, &vaultID, &opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// runTool wipes its own shares, but not the cached copies
+	if !assert.NotEmpty(t, opts.shares.shares) {
+		return
+	}
+	for _, share := range opts.shares.shares {
+		if sd, ok := share.(*ecdsa_keygen.LocalPartySaveData); ok {
+			assert.NotZero(t, sd.Xi.Sign())
+		}
+	}
+
+	// the second run takes every share from the cache, even with the bounds that no share passes
+	cached := len(opts.shares.shares)
+	opts.MinInflatedKB = DefaultMaxInflatedKB
+	again, _, _, _, _, err := runTool(context.Background(), files, &vaultID, &opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, address, again)
+	assert.Len(t, opts.shares.shares, cached)
+
+	opts.shares.wipe()
+	assert.Empty(t, opts.shares.shares)
+}
+
+func TestNewShareKey(t *testing.T) {
+	assert.Equal(t, "1234", newShareKey(v2MagicPrefix+"1234_eJw=").shareID)
+	assert.Empty(t, newShareKey(`{"Xi":1}`).shareID)
+	assert.NotEqual(t, newShareKey(v2MagicPrefix+"1234_eJw=").digest, newShareKey(v2MagicPrefix+"1234_eJy=").digest)
+}
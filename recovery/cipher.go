@@ -0,0 +1,65 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// cipherKeySizes maps the cipher of a vault to its AES key size in bytes. The key is the entropy of the file's phrase,
+// so 12, 18 and 24 word phrases give AES-128, AES-192 and AES-256 keys.
+var cipherKeySizes = map[string]int{
+	"aes-128-gcm": 16,
+	"aes-192-gcm": 24,
+	"aes-256-gcm": 32,
+}
+
+// phraseWordsForKey is the number of BIP39 words whose entropy is a key of the given size: 3 words per 4 bytes.
+func phraseWordsForKey(size int) int {
+	return size * 3 / 4
+}
+
+// phraseKey derives the AES key of a backup file from its phrase. BIP39 also allows 15 and 21 word phrases,
+// but their entropy is not an AES key, so they are rejected here rather than by the cipher.
+func phraseKey(file VaultsDataFile) ([]byte, error) {
+	aesKey, err := bip39.EntropyFromMnemonic(file.Mnemonics)
+	if err != nil {
+		return nil, WithKind(ErrWrongMnemonic, fmt.Errorf("⚠ failed to generate key from mnemonic, are your words correct? %s", err))
+	}
+	switch len(aesKey) {
+	case 16, 24, 32:
+		return aesKey, nil
+	default:
+		clear(aesKey)
+		return nil, WithKind(ErrWrongMnemonic, fmt.Errorf("⚠ the phrase for `%s` has %d words, but the phrase of a backup file has 12, 18 or 24 words", file.File, len(strings.Fields(file.Mnemonics))))
+	}
+}
+
+// PhraseWords returns the number of words of the phrase that decrypts the backup file, from the cipher of its vaults,
+// or 0 if it can't be told, e.g. as the file can't be read or its vaults do not name a known cipher.
+func PhraseWords(file string) int {
+	words := 0
+	err := readBackup(file, func(_ string, resharesMap CipheredVaultMap) error {
+		for _, cipheredVault := range resharesMap {
+			size, ok := cipherKeySizes[strings.ToLower(cipheredVault.Cipher)]
+			if !ok || (words > 0 && words != phraseWordsForKey(size)) {
+				return errUnknownPhraseWords
+			}
+			words = phraseWordsForKey(size)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0
+	}
+	return words
+}
+
+// errUnknownPhraseWords stops PhraseWords once the vaults of a file do not tell the number of words of its phrase.
+var errUnknownPhraseWords = errors.New("the vaults do not tell the number of words of the phrase")
@@ -0,0 +1,80 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/bech32"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/wif"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// Coins for Options.Coins, whose keys and addresses are only derived when asked for
+const (
+	CoinLitecoin = "ltc"
+	CoinDogecoin = "doge"
+	CoinXRP      = "xrp"
+)
+
+// extraCoins are the coins that Options.Coins may name, in the order they are listed in.
+var extraCoins = []string{CoinLitecoin, CoinDogecoin, CoinXRP}
+
+// ValidateCoins checks that every coin is one that Options.Coins may name.
+func ValidateCoins(coins []string) error {
+	for _, coin := range coins {
+		if !slices.Contains(extraCoins, coin) {
+			return WithKind(ErrInvalidInput, fmt.Errorf("⚠ unknown coin `%s`, expected one of: %s", coin, strings.Join(extraCoins, ", ")))
+		}
+	}
+	return nil
+}
+
+// Litecoin address version and human readable part, on mainnet
+const (
+	ltcP2PKH = 0x30
+	ltcHRP   = "ltc"
+)
+
+// deriveLitecoin derives the WIF and addresses of a vault on Litecoin from its ECDSA key, which Litecoin shares with Bitcoin.
+// They are for the compressed public key unless compressed is false, in which case there is no segwit address.
+func deriveLitecoin(ecSK []byte, compressed bool) (Litecoin, error) {
+	pub := secp256k1.PrivKeyFromBytes(ecSK).PubKey()
+	var ltc Litecoin
+	var err error
+	if ltc.WIF, err = wif.ToWIF(ecSK, wif.Litecoin, compressed); err != nil {
+		return Litecoin{}, err
+	}
+	if !compressed {
+		ltc.LegacyAddress = wif.Base58CheckEncode(ltcP2PKH, hash160(pub.SerializeUncompressed()))
+		return ltc, nil
+	}
+	pkHash := hash160(pub.SerializeCompressed())
+	ltc.LegacyAddress = wif.Base58CheckEncode(ltcP2PKH, pkHash)
+	if ltc.Address, err = bech32.EncodeSegwitAddress(ltcHRP, 0, pkHash); err != nil {
+		return Litecoin{}, err
+	}
+	return ltc, nil
+}
+
+// dogeP2PKH is the Dogecoin address version, on mainnet
+const dogeP2PKH = 0x1e
+
+// deriveDogecoin derives the WIF and address of a vault on Dogecoin from its ECDSA key, for the compressed public key
+// unless compressed is false. Dogecoin has no segwit, so its only address is a P2PKH (D) one.
+func deriveDogecoin(ecSK []byte, compressed bool) (Dogecoin, error) {
+	pub := secp256k1.PrivKeyFromBytes(ecSK).PubKey()
+	wifStr, err := wif.ToWIF(ecSK, wif.Dogecoin, compressed)
+	if err != nil {
+		return Dogecoin{}, err
+	}
+	pubKey := pub.SerializeCompressed()
+	if !compressed {
+		pubKey = pub.SerializeUncompressed()
+	}
+	return Dogecoin{Address: wif.Base58CheckEncode(dogeP2PKH, hash160(pubKey)), WIF: wifStr}, nil
+}
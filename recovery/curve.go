@@ -0,0 +1,87 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"crypto/elliptic"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	ecdsa_keygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+	"github.com/binance-chain/tss-lib/tss"
+)
+
+// ECDSA curves of a vault, as named in its shares. Most vaults are on secp256k1; some are on NIST P-256 (secp256r1).
+const (
+	CurveSecp256k1 = string(tss.Secp256k1)
+	CurveP256      = string(tss.Nist256p1)
+)
+
+// ecdsaCurveAliases are the names that the algorithm of a vault's curve may give its ECDSA curve, e.g. "ECDSA-P256".
+var ecdsaCurveAliases = map[string]string{
+	"SECP256K1":  CurveSecp256k1,
+	"P256":       CurveP256,
+	"SECP256R1":  CurveP256,
+	"NIST256P1":  CurveP256,
+	"PRIME256V1": CurveP256,
+}
+
+// curveAlgorithm reads the algorithm of one of a vault's curves (ClearVaultCurve.Algorithm): "ECDSA" or "EDDSA", and for ECDSA,
+// the curve it names, if any, e.g. CurveP256 for "ECDSA-P256" or "secp256r1". A plain "ECDSA" leaves the curve to the shares.
+// Any other algorithm returns an empty kind.
+func curveAlgorithm(algorithm string) (kind, ecdsaCurve string) {
+	name := strings.NewReplacer("-", "", "_", "", " ", "", "/", "").Replace(strings.ToUpper(algorithm))
+	switch name {
+	case "ECDSA":
+		return "ECDSA", ""
+	case "EDDSA", "ED25519", "EDDSAED25519":
+		return "EDDSA", ""
+	}
+	if curve, ok := ecdsaCurveAliases[strings.TrimPrefix(name, "ECDSA")]; ok {
+		return "ECDSA", curve
+	}
+	return "", ""
+}
+
+// ecdsaCurveName names the curve of a vault's ECDSA public key, which must be one of the curves that a vault is recovered on.
+func ecdsaCurveName(curve elliptic.Curve) (string, error) {
+	switch {
+	case curve == nil:
+		return "", WithKind(ErrBadFormat, fmt.Errorf("⚠ the ECDSA public key of the shares names no curve"))
+	case curve.Params().Name == elliptic.P256().Params().Name:
+		return CurveP256, nil
+	case curve.Params().N.Cmp(tss.S256().Params().N) == 0:
+		return CurveSecp256k1, nil
+	}
+	return "", fmt.Errorf("⚠ the ECDSA shares are on the %s curve, which this tool can't recover", curve.Params().Name)
+}
+
+// ecdsaCurve returns the curve of a vault's ECDSA shares, from the public key of share 0, as every share records its curve.
+// named is the curve that the algorithm of the vault's curve names, if any, which the shares must be on. Without shares, it is secp256k1.
+func ecdsaCurve(shares []*ecdsa_keygen.LocalPartySaveData, named string) (elliptic.Curve, error) {
+	if len(shares) == 0 {
+		return tss.S256(), nil
+	}
+	if shares[0].ECDSAPub == nil {
+		return nil, WithKind(ErrBadFormat, fmt.Errorf("⚠ share %s holds no ECDSA public key; the backup may be corrupt", shares[0].ShareID))
+	}
+	curve := shares[0].ECDSAPub.Curve()
+	name, err := ecdsaCurveName(curve)
+	if err != nil {
+		return nil, err
+	}
+	if named != "" && named != name {
+		return nil, WithKind(ErrBadFormat, fmt.Errorf("⚠ the vault's curve algorithm names the %s curve, but its ECDSA shares are on %s; the backup may be corrupt", named, name))
+	}
+	return curve, nil
+}
+
+// p256PublicKey is the compressed SEC1 public key of a P-256 private key, hex encoded.
+func p256PublicKey(sk []byte) string {
+	curve := elliptic.P256()
+	x, y := curve.ScalarBaseMult(sk)
+	return hex.EncodeToString(elliptic.MarshalCompressed(curve, x, y))
+}
@@ -0,0 +1,148 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"crypto/elliptic"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/binance-chain/tss-lib/crypto"
+	"github.com/binance-chain/tss-lib/crypto/vss"
+	ecdsa_keygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+	"github.com/binance-chain/tss-lib/tss"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurveAlgorithm(t *testing.T) {
+	tests := []struct {
+		algorithm, kind, curve string
+	}{
+		{"ECDSA", "ECDSA", ""},
+		{"ecdsa", "ECDSA", ""},
+		{"EDDSA", "EDDSA", ""},
+		{"Ed25519", "EDDSA", ""},
+		{"ECDSA-P256", "ECDSA", CurveP256},
+		{"ECDSA_secp256r1", "ECDSA", CurveP256},
+		{"nist256p1", "ECDSA", CurveP256},
+		{"ECDSA/secp256k1", "ECDSA", CurveSecp256k1},
+		{"RSA", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			kind, curve := curveAlgorithm(tt.algorithm)
+			assert.Equal(t, tt.kind, kind)
+			assert.Equal(t, tt.curve, curve)
+		})
+	}
+}
+
+// p256Shares splits a P-256 key into 3 shares with a threshold of 2, as the save data of a vault on P-256 holds them:
+// with the vault's public key, which is recorded with the name of its curve.
+func p256Shares(t *testing.T, secret *big.Int) []*ecdsa_keygen.LocalPartySaveData {
+	t.Helper()
+	curve := elliptic.P256()
+	_, shares, err := vss.Create(curve, 1, secret, []*big.Int{big.NewInt(11), big.NewInt(22), big.NewInt(33)})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	pubJSON, err := json.Marshal(crypto.ScalarBaseMult(curve, secret))
+	if !assert.NoError(t, err) || !assert.Contains(t, string(pubJSON), `"nist256p1"`) {
+		t.FailNow()
+	}
+	saveData := make([]*ecdsa_keygen.LocalPartySaveData, 0, len(shares))
+	for _, share := range shares {
+		sd := new(ecdsa_keygen.LocalPartySaveData)
+		sd.ShareID, sd.Xi = share.ID, share.Share
+		if !assert.NoError(t, json.Unmarshal(pubJSON, &sd.ECDSAPub)) {
+			t.FailNow()
+		}
+		saveData = append(saveData, sd)
+	}
+	return saveData
+}
+
+func TestReconstructKeys_P256(t *testing.T) {
+	sk, _ := hex.DecodeString("c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721")
+	shares := p256Shares(t, new(big.Int).SetBytes(sk))
+
+	curve, err := ecdsaCurve(shares, "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	name, err := ecdsaCurveName(curve)
+	if !assert.NoError(t, err) || !assert.Equal(t, CurveP256, name) {
+		return
+	}
+
+	ecdsaSK, eddsaSK, pk, err := reconstructKeys(curve, shares[1:], nil, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, sk, ecdsaSK)
+	assert.Nil(t, eddsaSK)
+	assert.Equal(t, shares[0].ECDSAPub.X(), pk.X)
+	assert.Equal(t, shares[0].ECDSAPub.Y(), pk.Y)
+	// the public key of the RFC 6979 A.2.5 P-256 test key
+	assert.Equal(t, "0360fed4ba255a9d31c961eb74c6356d68c049b8923b61fa6ce669622e60f29fb6", p256PublicKey(ecdsaSK))
+}
+
+func TestReconstructKeys_P256_WrongCurve(t *testing.T) {
+	shares := p256Shares(t, big.NewInt(123456789))
+
+	// reconstructed in the secp256k1 group, the key does not match the P-256 public key of the shares
+	_, _, _, err := reconstructKeys(tss.S256(), shares, nil, 2)
+	assert.ErrorContains(t, err, "did not match the expected share 0 public key")
+
+	_, err = ecdsaCurve(shares, CurveSecp256k1)
+	assert.ErrorContains(t, err, "names the secp256k1 curve, but its ECDSA shares are on nist256p1")
+	_, err = ecdsaCurve(shares, CurveP256)
+	assert.NoError(t, err)
+}
+
+func TestReconstructKey(t *testing.T) {
+	secret := big.NewInt(123456789)
+	shares := p256Shares(t, secret)
+	xis := make([]string, len(shares))
+	for i, share := range shares {
+		xis[i] = share.Xi.String()
+	}
+
+	tests := []struct {
+		name      string
+		shares    []*ecdsa_keygen.LocalPartySaveData
+		threshold int
+		wantErr   string
+	}{
+		{"All Shares", shares, 2, ""},
+		{"Last Two Shares", shares[1:], 2, ""},
+		{"Threshold Too Low", shares[:1], 1, "did not match the expected share 0 public key"},
+		{"Not Enough Shares", shares[:2], 3, "not enough shares to reconstruct the ECDSA key (need 3, have 2)"},
+		{"No Threshold", shares, 0, "need 0, have 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sk, pk, err := ReconstructKey(tt.shares, tt.threshold, elliptic.P256())
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Nil(t, sk)
+				assert.Nil(t, pk)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, secret, sk)
+			assert.Equal(t, elliptic.P256(), pk.Curve)
+			assert.Equal(t, shares[0].ECDSAPub.X(), pk.X)
+		})
+	}
+	// the shares can be reused for another attempt
+	for i, share := range shares {
+		assert.Equal(t, xis[i], share.Xi.String())
+	}
+}
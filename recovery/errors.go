@@ -0,0 +1,42 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import "errors"
+
+// The kinds of the errors of a recovery, for errors.Is. An error of one of these kinds keeps its own message.
+var (
+	// ErrInvalidInput is an error of the input of a recovery, e.g. a backup file that can't be read, a missing vault id
+	// or a reshare nonce that the files hold no shares at.
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrWrongMnemonic is an error of a phrase that is not a valid phrase of a backup file, or that does not decrypt it.
+	ErrWrongMnemonic = errors.New("wrong phrase")
+	// ErrInsufficientShares is an error of a vault that has fewer shares in the files than its threshold.
+	ErrInsufficientShares = errors.New("not enough shares")
+	// ErrPubKeyMismatch is an error of a key that does not match the public key of the vault's shares, e.g. as the threshold
+	// is wrong, or of shares that disagree on the vault's public key or are of different reshares.
+	ErrPubKeyMismatch = errors.New("public key mismatch")
+	// ErrBadFormat is an error of a backup file, vault or share whose data is malformed or corrupt.
+	ErrBadFormat = errors.New("malformed backup data")
+)
+
+// kindError is an error of one of the kinds above. errors.Is finds both its kind and the error it wraps.
+type kindError struct {
+	err, kind error
+}
+
+// WithKind marks err as an error of the given kind, e.g. ErrInvalidInput, keeping its message. The tool uses it for its own
+// errors of these kinds, e.g. of an invalid flag.
+func WithKind(kind, err error) error {
+	return &kindError{err: err, kind: kind}
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.err, e.kind}
+}
@@ -0,0 +1,58 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTool_ErrorKinds(t *testing.T) {
+	malformed := filepath.Join(t.TempDir(), "malformed.json")
+	if err := os.WriteFile(malformed, []byte(`{"vaults": []}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	bvn := VaultsDataFile{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn}
+	x2q := VaultsDataFile{File: "../test-files/new_x2q.json", Mnemonics: mmNewX2q}
+	u44 := VaultsDataFile{File: "../test-files/new_u44.json", Mnemonics: mmNewU44}
+
+	tests := []struct {
+		name      string
+		files     []VaultsDataFile
+		threshold int
+		kind      error
+	}{
+		{"Missing File", []VaultsDataFile{{File: "../test-files/missing.json", Mnemonics: mmNewBvn}}, 0, ErrInvalidInput},
+		{"Wrong Phrase", []VaultsDataFile{bvn, {File: x2q.File, Mnemonics: mmNewU44}, u44}, 0, ErrWrongMnemonic},
+		{"Invalid Phrase", []VaultsDataFile{{File: bvn.File, Mnemonics: "abandon abandon"}}, 0, ErrWrongMnemonic},
+		{"Not Enough Shares", []VaultsDataFile{bvn, x2q}, 0, ErrInsufficientShares},
+		{"Wrong Threshold", []VaultsDataFile{bvn, x2q}, 2, ErrPubKeyMismatch},
+		{"Malformed File", []VaultsDataFile{{File: malformed, Mnemonics: mmNewBvn}}, 0, ErrBadFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vaultID := "yz5x2a7zhwwt7r0lv4gklqns"
+			opts := NewOptions(vaultID)
+			opts.QuorumOverride = tt.threshold
+			_, _, _, _, _, err := runTool(context.Background(), tt.files, &vaultID, &opts)
+			if !assert.ErrorIs(t, err, tt.kind) {
+				return
+			}
+			// the kind survives a wrap
+			assert.ErrorIs(t, fmt.Errorf("wrapped: %w", err), tt.kind)
+			for _, other := range []error{ErrInvalidInput, ErrWrongMnemonic, ErrInsufficientShares, ErrPubKeyMismatch, ErrBadFormat} {
+				if other != tt.kind {
+					assert.False(t, errors.Is(err, other), other.Error())
+				}
+			}
+		})
+	}
+}
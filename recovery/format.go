@@ -0,0 +1,203 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/data"
+)
+
+// readBackup decodes a backup file vault by vault, calling visit with the reshares of each vault as soon as it is decoded.
+// The file is read as a stream, so that only one vault of a large backup file is held in memory at a time, besides what visit keeps.
+// An error of visit stops the decoding and is returned as is. A file that does not decode, or that has no vaults at all,
+// is explained from its content.
+func readBackup(file string, visit func(vaultID string, reshares CipheredVaultMap) error) error {
+	reader, err := data.OpenBackupFile(file)
+	if err != nil {
+		return WithKind(ErrInvalidInput, fmt.Errorf("⚠ file to read from file(%s): %s", file, err))
+	}
+	defer reader.Close()
+	stream := &streamReader{reader: reader}
+
+	var visitErr error
+	found, err := decodeVaults(json.NewDecoder(stream), func(vaultID string, reshares CipheredVaultMap) bool {
+		visitErr = visit(vaultID, reshares)
+		return visitErr == nil
+	})
+	switch {
+	case visitErr != nil:
+		return visitErr
+	case stream.err != nil:
+		return WithKind(ErrInvalidInput, fmt.Errorf("⚠ file to read from file(%s): %s", file, stream.err))
+	case err != nil || !found:
+		// a file that is not a backup is usually small, so it is read again as a whole to tell what it is
+		content, readErr := data.ReadBackupFile(file)
+		if readErr != nil {
+			return WithKind(ErrInvalidInput, fmt.Errorf("⚠ file to read from file(%s): %s", file, readErr))
+		}
+		return backupFormatError(file, content, err)
+	}
+	return nil
+}
+
+// streamReader keeps the error of reading a backup file, which the JSON decoder returns as if it were an error of the JSON.
+type streamReader struct {
+	reader io.Reader
+	err    error
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	n, err := s.reader.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		s.err = err
+	}
+	return n, err
+}
+
+// decodeVaults decodes the top-level object of a backup file token by token, and its `vaults` field one vault at a time,
+// calling visit with each vault until it returns false. It reports whether the file has any vaults, as json.Unmarshal
+// into SavedData would, i.e. with the field name matched ignoring case and a null field counted as none.
+func decodeVaults(dec *json.Decoder, visit func(vaultID string, reshares CipheredVaultMap) bool) (found bool, err error) {
+	if err = expectDelim(dec, '{'); err != nil {
+		return false, err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return false, err
+		}
+		if name, _ := key.(string); !strings.EqualFold(name, "vaults") {
+			if err = dec.Decode(new(json.RawMessage)); err != nil {
+				return false, err
+			}
+			continue
+		}
+		tok, err := dec.Token()
+		switch {
+		case err != nil:
+			return false, err
+		case tok == nil:
+			found = false
+			continue
+		case tok != json.Delim('{'):
+			return false, fmt.Errorf("the `vaults` field is %v, not an object", tok)
+		}
+		found = true
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return false, err
+			}
+			reshares := make(CipheredVaultMap, 1)
+			if err = dec.Decode(&reshares); err != nil {
+				return false, err
+			}
+			if !visit(tok.(string), reshares) {
+				return found, nil
+			}
+		}
+		if err = expectDelim(dec, '}'); err != nil {
+			return false, err
+		}
+	}
+	if err = expectDelim(dec, '}'); err != nil {
+		return false, err
+	}
+	// json.Unmarshal rejects anything after the top-level object as well
+	if _, err = dec.Token(); !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("the file has more data after its JSON object")
+	}
+	return found, nil
+}
+
+// expectDelim reads the next token, which must be the delimiter delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("expected %v, found %v", delim, tok)
+	}
+	return nil
+}
+
+// backupFormatError explains why a backup file could not be decoded, from a look at its top-level JSON.
+func backupFormatError(file string, content []byte, err error) error {
+	return WithKind(ErrBadFormat, fmt.Errorf("⚠ unable to read `%s` as a backup file: %s (code: 1)", file, sniffBackupFormat(content, err)))
+}
+
+// sniffBackupFormat tells what the content of a file that is not a valid backup looks like, and what to do about it.
+// err is the error of decoding the content as SavedData, if it did not decode.
+func sniffBackupFormat(content []byte, err error) string {
+	detail := ""
+	if err != nil {
+		detail = fmt.Sprintf(" (%s)", err)
+	}
+	content = bytes.TrimSpace(content)
+	if len(content) == 0 {
+		return "the file is empty"
+	}
+	if !json.Valid(content) {
+		if truncatedJSON(content) {
+			return "its JSON ends early, so the file is likely truncated; copy it again from where it was stored"
+		}
+		return fmt.Sprintf("the file is not valid JSON%s; it may be corrupt, or not a backup file at all", detail)
+	}
+	top := make(map[string]json.RawMessage)
+	if json.Unmarshal(content, &top) != nil {
+		return "the file holds JSON, but not a JSON object as a backup file does"
+	}
+	has := func(keys ...string) bool {
+		for _, key := range keys {
+			if _, ok := top[key]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case has("crypto", "Crypto") && has("version"):
+		return "this is a wallet v3 (keystore) file, e.g. one exported by this tool, not a vault backup file"
+	case !has("vaults") && has("keyring", "kdf", "userId", "timestamp"):
+		return "it has no `vaults` field, which holds the encrypted vaults; the file may have been edited or cut short"
+	case !has("vaults"):
+		return "it has none of the fields of a vault backup file (`vaults`, `keyring`, `kdf`); check that this is the backup file and not another JSON file"
+	}
+
+	vaults := make(map[string]map[string]json.RawMessage)
+	if json.Unmarshal(top["vaults"], &vaults) != nil {
+		return fmt.Sprintf("its `vaults` field is not a map of vault ids to reshares%s; the file may have been edited", detail)
+	}
+	for vID, reshares := range vaults {
+		if _, ok := reshares["ciphertext"]; ok {
+			return fmt.Sprintf("vault `%s` is stored without a reshare nonce, an older layout that this version of the tool does not read", vID)
+		}
+		for nonce := range reshares {
+			if _, err := strconv.Atoi(nonce); err != nil {
+				return fmt.Sprintf("vault `%s` has a reshare `%s` that is not a reshare nonce; the file may have been edited", vID, nonce)
+			}
+		}
+	}
+	return fmt.Sprintf("its vaults are not in the expected layout%s; the file may have been edited", detail)
+}
+
+// truncatedJSON tells whether invalid JSON is only invalid as it ends early, i.e. it has no syntax error up to its end.
+func truncatedJSON(content []byte) bool {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	for {
+		if _, err := dec.Token(); err != nil {
+			return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+		}
+	}
+}
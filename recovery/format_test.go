@@ -0,0 +1,111 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSniffBackupFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"Empty", " \n", "the file is empty"},
+		{"Truncated", `{"vaults": {"v1": {"0": {"ciphertext": "ab`, "likely truncated"},
+		{"Truncated Between Values", "{\"vaults\": {\"v1\": {\"0\": {\"ciphertext\": \"ab\",\n", "likely truncated"},
+		{"Not JSON", "vaults=1", "not valid JSON"},
+		{"Not An Object", `["a"]`, "not a JSON object"},
+		{"Wallet File", `{"address": "ab", "crypto": {}, "id": "x", "version": 3}`, "wallet v3 (keystore) file"},
+		{"No Vaults", `{"timestamp": 1, "keyring": {}, "kdf": "x"}`, "no `vaults` field"},
+		{"Other JSON", `{"file1.json": "abandon"}`, "none of the fields of a vault backup file"},
+		{"Vaults Not A Map", `{"vaults": ["v1"]}`, "not a map of vault ids to reshares"},
+		{"No Reshare Nonce", `{"vaults": {"v1": {"ciphertext": "ab", "cipher": "aes-256-gcm"}}}`, "vault `v1` is stored without a reshare nonce"},
+		{"Bad Reshare Nonce", `{"vaults": {"v1": {"latest": {"ciphertext": "ab"}}}}`, "reshare `latest` that is not a reshare nonce"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a JSON object without vaults decodes, but is no backup either
+			err := json.Unmarshal([]byte(tt.content), new(SavedData))
+			assert.Contains(t, sniffBackupFormat([]byte(tt.content), err), tt.expected)
+		})
+	}
+}
+
+func TestListVaults_NotABackup(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "wallet.json")
+	if !assert.NoError(t, os.WriteFile(file, []byte(`{"address": "ab", "crypto": {}, "version": 3}`), 0o600)) {
+		return
+	}
+	_, _, err := ListVaults(context.Background(), []VaultsDataFile{{File: file, Mnemonics: mmNewSingle}}, NewOptions(""))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unable to read `"+file+"` as a backup file: this is a wallet v3 (keystore) file")
+	}
+}
+
+func TestDecodeVaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		vaults   []string
+		found    bool
+		expected string
+	}{
+		{"Vaults", `{"keyring": {"a": [1, 2]}, "vaults": {"v1": {"0": {"cipher": "aes-256-gcm"}}, "v2": {"1": {}, "2": {}}}, "kdf": "x"}`, []string{"v1", "v2"}, true, ""},
+		{"Field Name Ignores Case", `{"Vaults": {"v1": {}}}`, []string{"v1"}, true, ""},
+		{"No Vaults", `{"keyring": {}}`, nil, false, ""},
+		{"Null Vaults", `{"vaults": null}`, nil, false, ""},
+		{"Vaults Not A Map", `{"vaults": ["v1"]}`, nil, false, "not an object"},
+		{"Bad Reshare Nonce", `{"vaults": {"v1": {"latest": {}}}}`, nil, false, "cannot unmarshal number latest"},
+		{"Not An Object", `["a"]`, nil, false, "expected {"},
+		{"Truncated", `{"vaults": {"v1": {"0": {"ciphertext": "ab`, nil, false, "unexpected EOF"},
+		{"Trailing Data", `{"vaults": {}} {}`, nil, false, "more data after its JSON object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var vaults []string
+			found, err := decodeVaults(json.NewDecoder(strings.NewReader(tt.content)), func(vaultID string, _ CipheredVaultMap) bool {
+				vaults = append(vaults, vaultID)
+				return true
+			})
+			if tt.expected != "" {
+				assert.ErrorContains(t, err, tt.expected)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.found, found)
+				assert.Equal(t, tt.vaults, vaults)
+			}
+		})
+	}
+}
+
+func TestReadBackup_StopsOnVisitError(t *testing.T) {
+	stop := errors.New("stop")
+	visited := 0
+	err := readBackup("../test-files/new_single.json.gz", func(string, CipheredVaultMap) error {
+		visited++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, visited)
+
+	// a file that is not a backup is still explained from its content
+	file := filepath.Join(t.TempDir(), "wallet.json")
+	if !assert.NoError(t, os.WriteFile(file, []byte(`{"address": "ab", "crypto": {}, "version": 3}`), 0o600)) {
+		return
+	}
+	err = readBackup(file, func(string, CipheredVaultMap) error { return nil })
+	assert.ErrorContains(t, err, "this is a wallet v3 (keystore) file")
+}
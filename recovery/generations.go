@@ -0,0 +1,116 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"sort"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/secmem"
+)
+
+type (
+	// VaultGeneration is the share count of a vault at one reshare nonce, across all supplied files.
+	VaultGeneration struct {
+		Nonce     int
+		Shares    int
+		Threshold int
+	}
+
+	// VaultGenerations lists the reshare generations found for a vault, newest first.
+	VaultGenerations struct {
+		VaultID     string
+		Name        string
+		Generations []VaultGeneration
+		// MixedNonces is set when the files disagree on the latest nonce of the vault,
+		// in which case the nonce has to be given explicitly to avoid mixing generations.
+		MixedNonces bool
+	}
+)
+
+// Recoverable returns the newest generation that has enough shares to recover the vault on its own.
+func (v VaultGenerations) Recoverable() (VaultGeneration, bool) {
+	for _, gen := range v.Generations {
+		if gen.Shares >= gen.Threshold {
+			return gen, true
+		}
+	}
+	return VaultGeneration{}, false
+}
+
+// ListGenerations decrypts every reshare generation of every vault in the files and counts the shares per generation.
+// The shares are not inflated and no keys are reconstructed.
+func ListGenerations(vaultsDataFile []VaultsDataFile) ([]VaultGenerations, error) {
+	byVault := make(map[string]*VaultGenerations, len(vaultsDataFile)*16)
+	byNonce := make(map[string]map[int]*VaultGeneration, len(vaultsDataFile)*16)
+	lastNonces := make(map[string]int, len(vaultsDataFile)*16)
+
+	for _, file := range vaultsDataFile {
+		// phrase -> key
+		aesKey32, err := phraseKey(file)
+		if err != nil {
+			return nil, err
+		}
+		secmem.Lock(aesKey32)
+
+		// the vaults are decrypted as they are read, so that only one vault of the file is held at a time
+		err = readBackup(file.File, func(vID string, resharesMap CipheredVaultMap) error {
+			lastReshareNonce := -1
+			for nonce, cipheredVault := range resharesMap {
+				lastReshareNonce = max(lastReshareNonce, nonce)
+				clearVault, err := decryptVault(aesKey32, vID, file.File, cipheredVault, nil)
+				if err != nil {
+					return err
+				}
+				sharesECDSA := clearVault.SharesLegacy
+				if sharesECDSA == nil {
+					for _, curve := range clearVault.Curves {
+						if kind, _ := curveAlgorithm(curve.Algorithm); kind == "ECDSA" {
+							sharesECDSA = curve.Shares
+						}
+					}
+				}
+
+				if _, ok := byVault[vID]; !ok {
+					byVault[vID] = &VaultGenerations{VaultID: vID, Name: clearVault.Name}
+					byNonce[vID] = make(map[int]*VaultGeneration)
+				}
+				gen, ok := byNonce[vID][nonce]
+				if !ok {
+					gen = &VaultGeneration{Nonce: nonce, Threshold: clearVault.Quroum}
+					byNonce[vID][nonce] = gen
+				}
+				gen.Shares += len(sharesECDSA)
+			}
+			if glbLastReShareNonce, ok := lastNonces[vID]; ok && glbLastReShareNonce != lastReshareNonce {
+				byVault[vID].MixedNonces = true
+			}
+			lastNonces[vID] = lastReshareNonce
+			return nil
+		})
+		clear(aesKey32)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	vaultIDs := make([]string, 0, len(byVault))
+	for vID := range byVault {
+		vaultIDs = append(vaultIDs, vID)
+	}
+	sort.Strings(vaultIDs)
+
+	vaults := make([]VaultGenerations, 0, len(vaultIDs))
+	for _, vID := range vaultIDs {
+		vault := byVault[vID]
+		for _, gen := range byNonce[vID] {
+			vault.Generations = append(vault.Generations, *gen)
+		}
+		sort.Slice(vault.Generations, func(i, j int) bool {
+			return vault.Generations[i].Nonce > vault.Generations[j].Nonce
+		})
+		vaults = append(vaults, *vault)
+	}
+	return vaults, nil
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListGenerations_New_V2(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn},
+		{File: "../test-files/new_x2q.json", Mnemonics: mmNewX2q},
+		{File: "../test-files/new_u44.json", Mnemonics: mmNewU44},
+	}
+	vaults, err := ListGenerations(files)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, vaults, 14) {
+		return
+	}
+	byID := make(map[string]VaultGenerations, len(vaults))
+	for _, vault := range vaults {
+		byID[vault.VaultID] = vault
+	}
+
+	// the files disagree on the latest nonce of this vault
+	mixed := byID["e0wspn90rz8vnngv0kdklaog"]
+	assert.True(t, mixed.MixedNonces)
+	assert.Equal(t, []VaultGeneration{{Nonce: 1, Shares: 2, Threshold: 2}, {Nonce: 0, Shares: 2, Threshold: 2}}, mixed.Generations)
+
+	lqns := byID["yz5x2a7zhwwt7r0lv4gklqns"]
+	assert.False(t, lqns.MixedNonces)
+	gen, ok := lqns.Recoverable()
+	assert.True(t, ok)
+	assert.Equal(t, VaultGeneration{Nonce: 4, Shares: 3, Threshold: 3}, gen)
+
+	_, ok = byID["nbpxb6hmupk1ygcl53jf9zg5"].Recoverable()
+	assert.False(t, ok)
+}
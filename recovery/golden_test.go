@@ -0,0 +1,115 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// updateGolden rewrites the golden file from the recovered values: go test ./recovery -run TestRecover_Golden -update
+var updateGolden = flag.Bool("update", false, "rewrite test-files/golden.json from the recovered values")
+
+const goldenFile = "../test-files/golden.json"
+
+// goldenMnemonics are the phrases of the fixture files named in the golden file.
+var goldenMnemonics = map[string]string{
+	"i.json":          mmI,
+	"l.json":          mmL,
+	"m.json":          mmM,
+	"v2.json":         mmV2,
+	"new_bvn.json":    mmNewBvn,
+	"new_x2q.json":    mmNewX2q,
+	"new_u44.json":    mmNewU44,
+	"new_single.json": mmNewSingle,
+}
+
+// goldenRecovery is a known vault of the fixture files, with the keys and addresses it must recover to.
+type goldenRecovery struct {
+	Name            string   `json:"name"`
+	VaultID         string   `json:"vaultId"`
+	Files           []string `json:"files"`
+	EthereumAddress string   `json:"ethereumAddress"`
+	TronAddress     string   `json:"tronAddress"`
+	PrivateKey      string   `json:"privateKey"`
+	ECDSAPublicKey  string   `json:"ecdsaPublicKey"`
+	MainnetAddress  string   `json:"mainnetAddress"`
+	MainnetWIF      string   `json:"mainnetWif"`
+	TestnetAddress  string   `json:"testnetAddress"`
+	TestnetWIF      string   `json:"testnetWif"`
+	EdDSAPrivateKey string   `json:"eddsaPrivateKey,omitempty"`
+	EdDSAPublicKey  string   `json:"eddsaPublicKey,omitempty"`
+	SolanaAddress   string   `json:"solanaAddress,omitempty"`
+}
+
+// TestRecover_Golden recovers every vault of the golden file end to end and compares all of its keys and addresses,
+// so that a change anywhere from the decryption to the address encodings shows up as a diff against known values.
+func TestRecover_Golden(t *testing.T) {
+	content, err := os.ReadFile(goldenFile)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var golden []goldenRecovery
+	if !assert.NoError(t, json.Unmarshal(content, &golden)) || !assert.NotEmpty(t, golden) {
+		return
+	}
+
+	for i, want := range golden {
+		t.Run(want.Name, func(t *testing.T) {
+			files := make([]VaultsDataFile, 0, len(want.Files))
+			for _, file := range want.Files {
+				mnemonic, ok := goldenMnemonics[file]
+				if !assert.True(t, ok, "no phrase for fixture %s", file) {
+					return
+				}
+				files = append(files, VaultsDataFile{File: filepath.Join("../test-files", file), Mnemonics: mnemonic})
+			}
+			result, err := Recover(context.Background(), files, NewOptions(want.VaultID))
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer result.Wipe()
+
+			got := goldenRecovery{
+				Name:            want.Name,
+				VaultID:         result.VaultID,
+				Files:           want.Files,
+				EthereumAddress: result.Address,
+				TronAddress:     result.Chains.Tron,
+				PrivateKey:      hex.EncodeToString(result.ECDSAKey),
+				ECDSAPublicKey:  result.Chains.ECDSAPublicKey,
+				MainnetAddress:  result.Chains.BitcoinMainnet.Address,
+				MainnetWIF:      result.Chains.BitcoinMainnet.WIF,
+				TestnetAddress:  result.Chains.BitcoinTestnet.Address,
+				TestnetWIF:      result.Chains.BitcoinTestnet.WIF,
+				EdDSAPublicKey:  result.Chains.EdDSAPublicKey,
+				SolanaAddress:   result.Chains.Solana,
+			}
+			if result.EdDSAKey != nil {
+				got.EdDSAPrivateKey = hex.EncodeToString(result.EdDSAKey)
+			}
+			if *updateGolden {
+				golden[i] = got
+				return
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+
+	if *updateGolden {
+		out, err := json.MarshalIndent(golden, "", "  ")
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NoError(t, os.WriteFile(goldenFile, append(out, '\n'), 0o644))
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type (
+	// VaultHealth is the recoverability of one vault in a backup set.
+	VaultHealth struct {
+		VaultID     string `json:"vaultId"`
+		Name        string `json:"name"`
+		Recoverable bool   `json:"recoverable"`
+		Nonce       int    `json:"nonce"`
+		Shares      int    `json:"shares"`
+		Threshold   int    `json:"threshold"`
+		Problem     string `json:"problem,omitempty"`
+	}
+
+	// HealthReport summarises the recoverability of every vault in a backup set.
+	HealthReport struct {
+		Pass   bool          `json:"pass"`
+		Total  int           `json:"total"`
+		AtRisk int           `json:"atRisk"`
+		Vaults []VaultHealth `json:"vaults"`
+	}
+)
+
+// CheckHealth reports for every vault whether it can be recovered at its latest reshare nonce.
+// Each vault with enough shares is recovered to prove it, but the keys are discarded and never shown.
+func CheckHealth(ctx context.Context, vaultsDataFile []VaultsDataFile, opts Options) (*HealthReport, error) {
+	vaults, err := ListGenerations(vaultsDataFile)
+	if err != nil {
+		return nil, err
+	}
+	opts.QuorumOverride, opts.Progress = 0, nil
+
+	report := &HealthReport{Total: len(vaults), Vaults: make([]VaultHealth, 0, len(vaults))}
+	for _, vault := range vaults {
+		latest := vault.Generations[0]
+		health := VaultHealth{
+			VaultID:   vault.VaultID,
+			Name:      vault.Name,
+			Nonce:     latest.Nonce,
+			Shares:    latest.Shares,
+			Threshold: latest.Threshold,
+		}
+		if latest.Shares < latest.Threshold {
+			health.Problem = fmt.Sprintf("not enough shares at the latest nonce %d (need %d, have %d)", latest.Nonce, latest.Threshold, latest.Shares)
+			if gen, ok := vault.Recoverable(); ok {
+				health.Problem += fmt.Sprintf("; the older nonce %d has enough shares", gen.Nonce)
+			}
+		} else {
+			// pin the nonce so that files holding an older generation are not mixed in
+			opts.NonceOverride, opts.probeNonce = latest.Nonce, true
+			_, ecSK, edSK, _, _, err := runTool(ctx, vaultsDataFile, &vault.VaultID, &opts)
+			clear(ecSK)
+			clear(edSK)
+			if err := cancelled(ctx); err != nil {
+				return nil, err
+			}
+			if err != nil {
+				health.Problem = Redact(strings.TrimPrefix(err.Error(), "⚠ "))
+			} else {
+				health.Recoverable = true
+			}
+		}
+		if !health.Recoverable {
+			report.AtRisk++
+		}
+		report.Vaults = append(report.Vaults, health)
+	}
+	report.Pass = report.AtRisk == 0
+	return report, nil
+}
@@ -0,0 +1,58 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckHealth_New_V2(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn},
+		{File: "../test-files/new_x2q.json", Mnemonics: mmNewX2q},
+		{File: "../test-files/new_u44.json", Mnemonics: mmNewU44},
+	}
+	report, err := CheckHealth(context.Background(), files, NewOptions(""))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, report.Pass)
+	assert.Equal(t, 14, report.Total)
+	if !assert.Equal(t, 3, report.AtRisk) {
+		return
+	}
+	atRisk := make([]string, 0, report.AtRisk)
+	for _, vault := range report.Vaults {
+		if !vault.Recoverable {
+			atRisk = append(atRisk, vault.VaultID)
+			assert.Contains(t, vault.Problem, "not enough shares")
+		}
+	}
+	assert.Equal(t, []string{"bfc8uksrk5zuxihufj4m8dkt", "ejrye15wiew2201f3fahho8k", "nbpxb6hmupk1ygcl53jf9zg5"}, atRisk)
+
+	out, err := json.Marshal(report)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(out), `"pass":false,"total":14,"atRisk":3`)
+}
+
+func TestCheckHealth_NewSingle_V2(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_single.json", Mnemonics: mmNewSingle},
+	}
+	report, err := CheckHealth(context.Background(), files, NewOptions(""))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, report.Pass)
+	assert.Equal(t, []VaultHealth{{
+		VaultID: "phrot42ltzawmn7nrm7mqvl5", Name: "EdDSA Export Tool Test Luke", Recoverable: true, Nonce: 0, Shares: 2, Threshold: 2,
+	}}, report.Vaults)
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// recoverVault recovers a vault with runTool. When no nonce override was given and the shares do not reconstruct the vault's
+// public key, e.g. because the files disagree on the latest reshare nonce, each other reshare nonce of the vault is tried
+// in turn from the highest down. runTool validates every attempt against the share 0 public key, so the first nonce that
+// succeeds is the right one. Any other error, e.g. of a wrong phrase, is returned as is, as no nonce would fix it.
+func recoverVault(ctx context.Context, vaultsDataFile []VaultsDataFile, vaultID string, opts Options) (
+	address string, ecdsaSK, eddsaSK []byte, vault VaultSummary, warnings []Warning, welp error) {
+
+	// the retries decode the same shares where the nonces share a file, so they are decoded once
+	if opts.NonceOverride < 0 {
+		opts.shares = newShareCache()
+		defer opts.shares.wipe()
+	}
+	var vaults []VaultSummary
+	address, ecdsaSK, eddsaSK, vaults, warnings, welp = runTool(ctx, vaultsDataFile, &vaultID, &opts)
+	vault = findVault(vaults, vaultID)
+	if welp == nil || opts.NonceOverride > -1 || ctx.Err() != nil || !errors.Is(welp, ErrPubKeyMismatch) {
+		return
+	}
+	// files that agree on the latest nonce were just recovered at it, so only the older nonces are left to try
+	triedNonce := -1
+	if !slices.ContainsFunc(warnings, func(w Warning) bool { return w.Kind == WarnNonceMismatch && w.VaultID == vaultID }) {
+		triedNonce = vault.LastReShareNonce
+	}
+
+	// a failed public key check still returns the mismatched keys
+	clear(ecdsaSK)
+	clear(eddsaSK)
+	ecdsaSK, eddsaSK = nil, nil
+
+	byGeneration, err := ListGenerations(vaultsDataFile)
+	if err != nil {
+		return
+	}
+	var generations []VaultGeneration
+	for _, vault := range byGeneration {
+		if vault.VaultID == vaultID {
+			generations = vault.Generations
+		}
+	}
+	opts.Progress = nil
+	for _, gen := range generations {
+		if gen.Nonce == triedNonce {
+			continue
+		}
+		opts.NonceOverride, opts.probeNonce = gen.Nonce, true
+		genAddress, genECDSASK, genEdDSASK, genVaults, genWarnings, err := runTool(ctx, vaultsDataFile, &vaultID, &opts)
+		if err != nil {
+			clear(genECDSASK)
+			clear(genEdDSASK)
+			if welp = cancelled(ctx); welp != nil {
+				return
+			}
+			continue
+		}
+		// the warnings are those of the attempt that succeeded, without the nonce override one, which does not apply to a detected nonce
+		kept := make([]Warning, 0, len(genWarnings)+1)
+		for _, w := range genWarnings {
+			if w.Kind != WarnNonceMismatch && w.Kind != WarnNonceOverride {
+				kept = append(kept, w)
+			}
+		}
+		kept = append(kept, Warning{
+			Kind:    WarnNonceDetected,
+			VaultID: vaultID,
+			Message: fmt.Sprintf("Auto-detected reshare nonce %d for vault `%s`: its shares reconstruct the vault's public key.", gen.Nonce, vaultID),
+		})
+		return genAddress, genECDSASK, genEdDSASK, findVault(genVaults, vaultID), kept, nil
+	}
+	return
+}
+
+func findVault(vaults []VaultSummary, vaultID string) VaultSummary {
+	for _, vault := range vaults {
+		if vault.VaultID == vaultID {
+			return vault
+		}
+	}
+	return VaultSummary{}
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverVault_DetectsNonce(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn},
+		{File: "../test-files/new_x2q.json", Mnemonics: mmNewX2q},
+		{File: "../test-files/new_u44.json", Mnemonics: mmNewU44},
+	}
+	// the files disagree on the latest nonce of this vault, so the default recovery mixes generations
+	vaultID := "e0wspn90rz8vnngv0kdklaog"
+	opts := Options{NonceOverride: -1, MinInflatedKB: DefaultMinInflatedKB, MaxInflatedKB: DefaultMaxInflatedKB}
+	_, _, _, _, _, err := runTool(context.Background(), files, &vaultID, &opts)
+	if !assert.Error(t, err) {
+		return
+	}
+
+	address, ecSK, _, vault, warnings, err := recoverVault(context.Background(), files, vaultID, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "0xe3bF51A04355e16843283d8f6A19f6d01A3f8886", address)
+	assert.Equal(t, "UpgradeTest3", vault.Name)
+	assert.Len(t, ecSK, 32)
+	if !assert.Len(t, warnings, 1) {
+		return
+	}
+	assert.Equal(t, WarnNonceDetected, warnings[0].Kind)
+	assert.Contains(t, warnings[0].Message, "Auto-detected reshare nonce 1")
+}
+
+func TestRecoverVault_DetectedNonceWarnings(t *testing.T) {
+	// the first attempt fails before the -threshold is checked against the files, so only the attempt that succeeds warns of it
+	files := []VaultsDataFile{
+		{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn},
+		{File: "../test-files/new_x2q.json", Mnemonics: mmNewX2q},
+		{File: "../test-files/new_u44.json", Mnemonics: mmNewU44},
+	}
+	vaultID := "e0wspn90rz8vnngv0kdklaog"
+	opts := NewOptions(vaultID)
+	opts.QuorumOverride = 1
+	_, _, _, _, warnings, err := recoverVault(context.Background(), files, vaultID, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	kinds := make([]WarningKind, 0, len(warnings))
+	for _, w := range warnings {
+		kinds = append(kinds, w.Kind)
+	}
+	assert.Equal(t, []WarningKind{WarnQuorumOverride, WarnThresholdMismatch, WarnNonceDetected}, kinds)
+
+	// a wrong phrase is not retried at the other nonces
+	files[0].Mnemonics = mmNewX2q
+	_, _, _, _, _, err = recoverVault(context.Background(), files, vaultID, opts)
+	assert.ErrorIs(t, err, ErrWrongMnemonic)
+}
+
+func TestRunTool_StrictNonce(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn},
+		{File: "../test-files/new_u44.json", Mnemonics: mmNewU44},
+		{File: "../test-files/new_x2q.json", Mnemonics: mmNewX2q},
+	}
+	vaultID := "e0wspn90rz8vnngv0kdklaog"
+	opts := NewOptions(vaultID)
+	opts.NonceOverride = 1
+
+	// new_bvn.json only holds the older generation of the vault, and new_x2q.json none of it
+	_, _, _, _, _, err := runTool(context.Background(), files, &vaultID, &opts)
+	if !assert.Error(t, err) {
+		return
+	}
+	assert.Contains(t, err.Error(), "-nonce 1: file `../test-files/new_bvn.json` holds no shares of vault `e0wspn90rz8vnngv0kdklaog` at that reshare nonce, only at reshare nonce(s) 0")
+	_, _, _, _, _, err = runTool(context.Background(), files[1:], &vaultID, &opts)
+	if !assert.Error(t, err) {
+		return
+	}
+	assert.Contains(t, err.Error(), "file `../test-files/new_x2q.json` holds no shares of vault `e0wspn90rz8vnngv0kdklaog` at that reshare nonce, nor at any other")
+
+	address, ecSK, _, _, warnings, err := runTool(context.Background(), files[1:2], &vaultID, &opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "0xe3bF51A04355e16843283d8f6A19f6d01A3f8886", address)
+	assert.Len(t, ecSK, 32)
+	if !assert.Len(t, warnings, 1) {
+		return
+	}
+	assert.Equal(t, WarnNonceOverride, warnings[0].Kind)
+	assert.Contains(t, warnings[0].Message, "\n⚠ `../test-files/new_u44.json`: 2 share(s) at reshare nonce 1.")
+
+	// the nonces that are tried to detect the nonce of a vault skip the files without shares at them
+	opts.probeNonce = true
+	_, _, _, _, _, err = runTool(context.Background(), files, &vaultID, &opts)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// clearLine returns the cursor to the start of the line and clears it.
+const clearLine = "\r\033[K"
+
+// progressWriter serializes the progress output of the workers that decrypt the vaults in parallel.
+// With status set, it also keeps a line with the count of the shares decoded so far below that output, redrawn in place,
+// so that a recovery of a vault with many or large shares does not look stuck. The status line is only for a terminal.
+type progressWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	status bool
+	// done and total count the shares decoded and found so far; total grows as the vaults are decrypted
+	done, total int
+	drawn       bool
+}
+
+// newProgressWriter wraps w, which may be nil to turn the output off; a nil *progressWriter is a no-op.
+func newProgressWriter(w io.Writer, status bool) *progressWriter {
+	if w == nil {
+		return nil
+	}
+	return &progressWriter{w: w, status: status}
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.erase()
+	n, err := p.w.Write(b)
+	p.draw()
+	return n, err
+}
+
+// addShares adds the shares of a decrypted vault to the total.
+func (p *progressWriter) addShares(n int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total += n
+	p.draw()
+}
+
+// shareDone counts a decoded share.
+func (p *progressWriter) shareDone() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	p.draw()
+}
+
+// finish erases the status line and stops drawing it, so that the output that follows starts on a clean line.
+func (p *progressWriter) finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.erase()
+	p.status = false
+}
+
+func (p *progressWriter) draw() {
+	if !p.status || p.total == 0 {
+		return
+	}
+	fmt.Fprintf(p.w, "%sDecoding shares: %d/%d (%d%%)", clearLine, p.done, p.total, p.done*100/p.total)
+	p.drawn = true
+}
+
+func (p *progressWriter) erase() {
+	if p.drawn {
+		fmt.Fprint(p.w, clearLine)
+		p.drawn = false
+	}
+}
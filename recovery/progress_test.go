@@ -0,0 +1,77 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressWriter_Status(t *testing.T) {
+	var out bytes.Buffer
+	p := newProgressWriter(&out, true)
+	p.addShares(2)
+	fmt.Fprintln(p, "Processing V2 share 1.")
+	p.shareDone()
+	p.shareDone()
+	p.finish()
+	fmt.Fprintln(p)
+
+	assert.Equal(t, clearLine+"Decoding shares: 0/2 (0%)"+
+		clearLine+"Processing V2 share 1.\n"+clearLine+"Decoding shares: 0/2 (0%)"+
+		clearLine+"Decoding shares: 1/2 (50%)"+
+		clearLine+"Decoding shares: 2/2 (100%)"+
+		clearLine+"\n", out.String())
+}
+
+func TestProgressWriter_NoStatus(t *testing.T) {
+	var out bytes.Buffer
+	p := newProgressWriter(&out, false)
+	p.addShares(2)
+	fmt.Fprintln(p, "Processing V2 share 1.")
+	p.shareDone()
+	p.finish()
+
+	// without a terminal, e.g. when the output is piped, only the progress lines are written
+	assert.Equal(t, "Processing V2 share 1.\n", out.String())
+}
+
+func TestProgressWriter_Nil(t *testing.T) {
+	p := newProgressWriter(nil, true)
+	if !assert.Nil(t, p) {
+		return
+	}
+	assert.NotPanics(t, func() {
+		p.addShares(1)
+		p.shareDone()
+		p.finish()
+	})
+}
+
+func TestRecover_ProgressStatus(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn},
+		{File: "../test-files/new_x2q.json", Mnemonics: mmNewX2q},
+		{File: "../test-files/new_u44.json", Mnemonics: mmNewU44},
+	}
+	var progress bytes.Buffer
+	opts := NewOptions("yz5x2a7zhwwt7r0lv4gklqns")
+	opts.Progress, opts.ProgressStatus = &progress, true
+
+	result, err := Recover(context.Background(), files, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer result.Wipe()
+	// three files with an ECDSA and an EdDSA share each
+	assert.Contains(t, progress.String(), "Decoding shares: 6/6 (100%)")
+	// the status line is erased before the output that follows the recovery
+	assert.True(t, strings.HasSuffix(progress.String(), clearLine+"\n"), progress.String())
+}
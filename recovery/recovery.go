@@ -0,0 +1,299 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+// Package recovery reconstructs the private keys of io.vault vaults from the TSS backup files of their parties.
+// It does no communication with any external host or service.
+package recovery
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/bech32"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/wif"
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// Default bounds for the inflated size of a V2 share, in KB
+const (
+	DefaultMinInflatedKB = 0.25
+	DefaultMaxInflatedKB = 16 * 1024
+)
+
+type (
+	// VaultsDataFile is a backup file with the phrase that decrypts it.
+	VaultsDataFile struct {
+		File      string
+		Mnemonics string
+	}
+
+	// VaultSummary describes a vault found in the backup files.
+	VaultSummary struct {
+		VaultID          string
+		Name             string
+		Quorum           int
+		LastReShareNonce int
+		NumberOfShares   int
+		// ReShareNonces are the reshare nonces of the vault found across the files, in ascending order.
+		ReShareNonces []int
+		// Files are the backup files that hold shares of the vault, in the order they were given.
+		Files []string
+		// Parties is the number of parties of the vault, or 0 if the shares do not record it.
+		Parties int
+		// Threshold is the number of shares that the keys were reconstructed with, once the vault is recovered, e.g. with -threshold
+		// or -auto-threshold; it is 0 in a vault list.
+		Threshold int
+		// ECDSACurve is the curve of the vault's ECDSA key, CurveSecp256k1 or CurveP256, once the vault is recovered.
+		ECDSACurve string
+	}
+
+	// Options configure a recovery. Start from NewOptions, as the zero value pins the reshare nonce to 0
+	// and rejects every V2 share.
+	Options struct {
+		// VaultID is the id of the vault to recover.
+		VaultID string
+		// NonceOverride is the reshare nonce to recover the vault at, or -1 for the latest one.
+		// With -1, the other nonces of the vault are tried if the recovery fails at the latest one. Otherwise, every file must hold
+		// shares of the vault at that nonce, and the shares that each file holds at it are reported in the nonce override warning.
+		NonceOverride int
+		// QuorumOverride replaces the threshold stored in the backups when set above 0.
+		QuorumOverride int
+		// AutoThreshold tries every threshold up to the number of shares if the stored threshold fails.
+		AutoThreshold bool
+		// MinInflatedKB and MaxInflatedKB bound the size of an inflated V2 share.
+		MinInflatedKB, MaxInflatedKB float64
+		// Bech32HRP is the human readable part of the Cosmos SDK address in the result, e.g. CosmosHRP. No address is derived if empty.
+		Bech32HRP string
+		// BTCAddressType is the type of the Bitcoin addresses in the result: BTCAddressLegacy, BTCAddressP2SH or BTCAddressBech32.
+		BTCAddressType string
+		// Coins are the extra coins to derive the WIFs and addresses of: CoinLitecoin, CoinDogecoin or CoinXRP. See ValidateCoins.
+		Coins []string
+		// UncompressedWIF derives the WIFs and legacy Bitcoin addresses of the result from the uncompressed public key,
+		// for older wallets. It needs BTCAddressType to be BTCAddressLegacy.
+		UncompressedWIF bool
+		// Verbose reports extra details of the decoding to Progress.
+		Verbose bool
+		// Progress receives the progress output of a recovery, if set.
+		Progress io.Writer
+		// ProgressStatus adds a line with the count of the shares decoded so far to Progress, redrawn in place.
+		// Set it only when Progress is a terminal, as the line is drawn with control characters.
+		ProgressStatus bool
+
+		// probeNonce marks a NonceOverride that the package tries itself, e.g. to detect the nonce of a vault:
+		// the files without shares at that nonce are skipped then, rather than rejected.
+		probeNonce bool
+		// shares caches the shares decoded by one runTool call for the next ones, e.g. those that detect the nonce of a vault.
+		shares *shareCache
+	}
+
+	// Bitcoin is the address and WIF of a vault on one Bitcoin network. Address is of the type of Options.BTCAddressType,
+	// and LegacyAddress is the P2PKH address of the WIF, for a check against a wallet such as Electrum whatever the type.
+	Bitcoin struct {
+		Address       string
+		LegacyAddress string
+		WIF           string
+	}
+
+	// Litecoin is the WIF and addresses of a vault on Litecoin mainnet. Address is its native segwit (ltc1) address,
+	// which an uncompressed WIF has none of, and LegacyAddress its P2PKH (L) address.
+	Litecoin struct {
+		Address       string
+		LegacyAddress string
+		WIF           string
+	}
+
+	// Dogecoin is the WIF and P2PKH (D) address of a vault on Dogecoin mainnet.
+	Dogecoin struct {
+		Address string
+		WIF     string
+	}
+
+	// Chains are the addresses and keys of a vault per chain. For a vault on P-256, only ECDSAPublicKey and the EdDSA chains are set,
+	// as the secp256k1 chains have no address for a P-256 key.
+	Chains struct {
+		// ECDSAPublicKey is the compressed public key of the vault's ECDSA key, hex encoded: 33 bytes on secp256k1 or P-256.
+		ECDSAPublicKey string
+		Ethereum       string
+		Tron           string
+		// Cosmos is empty unless Options.Bech32HRP is set.
+		Cosmos         string
+		BitcoinMainnet Bitcoin
+		BitcoinTestnet Bitcoin
+		// Litecoin is empty unless Options.Coins has CoinLitecoin.
+		Litecoin Litecoin
+		// Dogecoin is empty unless Options.Coins has CoinDogecoin.
+		Dogecoin Dogecoin
+		// XRP is the XRP Ledger classic address of the ECDSA key, empty unless Options.Coins has CoinXRP.
+		XRP string
+		// EdDSAPublicKey and Solana are empty for an older vault without EdDSA shares.
+		EdDSAPublicKey string
+		Solana         string
+	}
+
+	// Result is a recovered vault. Its keys should be wiped with Wipe once they are no longer needed.
+	Result struct {
+		VaultID string
+		Name    string
+		// ReShareNonces are the reshare nonces of the vault found across the files, in ascending order.
+		ReShareNonces []int
+		// Nonce is the reshare nonce that the vault was recovered at.
+		Nonce int
+		// Quorum is the threshold stored in the backups, and Threshold the number of shares that the keys were reconstructed with.
+		Quorum, Threshold int
+		// Shares is the number of distinct shares of the vault found in the files.
+		Shares int
+		// ECDSACurve is the curve of ECDSAKey: CurveSecp256k1, or CurveP256 for a vault on P-256.
+		ECDSACurve string
+		// Address is the checksummed Ethereum address of the vault, or empty for a vault on P-256.
+		Address  string
+		ECDSAKey []byte
+		// EdDSAKey is the Ed25519 scalar of the vault, or nil for an older vault without EdDSA shares.
+		EdDSAKey []byte
+		Chains   Chains
+		Warnings []Warning
+	}
+)
+
+// NewOptions returns the options for recovering the given vault at its latest reshare nonce, with the default share size bounds.
+func NewOptions(vaultID string) Options {
+	return Options{
+		VaultID:        vaultID,
+		NonceOverride:  -1,
+		MinInflatedKB:  DefaultMinInflatedKB,
+		MaxInflatedKB:  DefaultMaxInflatedKB,
+		BTCAddressType: BTCAddressBech32,
+	}
+}
+
+// ListVaults decrypts the backup files and lists the vaults found in them, sorted by id. No keys are reconstructed.
+func ListVaults(ctx context.Context, vaultsDataFile []VaultsDataFile, opts Options) ([]VaultSummary, []Warning, error) {
+	_, _, _, vaults, warnings, err := runTool(ctx, vaultsDataFile, nil, &opts)
+	return vaults, warnings, err
+}
+
+// Recover reconstructs the keys of the vault given by opts.VaultID and checks them against the vault's public keys.
+// If the recovery fails, the result still carries the warnings collected on the way, as they may explain the failure.
+// Once ctx is done, the recovery stops at its next step with an error wrapping ctx.Err(), and the shares decoded so far are wiped.
+func Recover(ctx context.Context, vaultsDataFile []VaultsDataFile, opts Options) (*Result, error) {
+	if opts.VaultID == "" {
+		return nil, WithKind(ErrInvalidInput, fmt.Errorf("⚠ no vault id given"))
+	}
+	if opts.UncompressedWIF && opts.BTCAddressType != BTCAddressLegacy {
+		return nil, WithKind(ErrInvalidInput, fmt.Errorf("⚠ an uncompressed WIF only has a %s Bitcoin address, not a %s one", BTCAddressLegacy, opts.BTCAddressType))
+	}
+	if opts.Bech32HRP != "" {
+		if err := bech32.ValidateHRP(opts.Bech32HRP); err != nil {
+			return nil, WithKind(ErrInvalidInput, fmt.Errorf("⚠ invalid bech32 prefix: %v", err))
+		}
+	}
+	if err := ValidateCoins(opts.Coins); err != nil {
+		return nil, err
+	}
+	result := &Result{VaultID: opts.VaultID}
+
+	var (
+		vault VaultSummary
+		err   error
+	)
+	result.Address, result.ECDSAKey, result.EdDSAKey, vault, result.Warnings, err = recoverVault(ctx, vaultsDataFile, opts.VaultID, opts)
+	result.Name, result.ReShareNonces = vault.Name, vault.ReShareNonces
+	result.Nonce, result.Quorum, result.Threshold, result.Shares = vault.LastReShareNonce, vault.Quorum, vault.Threshold, vault.NumberOfShares
+	result.ECDSACurve = vault.ECDSACurve
+	if err != nil {
+		return result, err
+	}
+	if result.ECDSACurve == CurveP256 {
+		result.Chains, err = deriveP256Chains(result.ECDSAKey, result.EdDSAKey)
+	} else {
+		result.Chains, err = DeriveChains(result.ECDSAKey, result.EdDSAKey, opts.BTCAddressType, !opts.UncompressedWIF)
+	}
+	if err != nil {
+		result.Wipe()
+		return result, err
+	}
+	if opts.Bech32HRP != "" && result.ECDSACurve != CurveP256 {
+		result.Chains.Cosmos = toCosmosAddress(secp256k1.PrivKeyFromBytes(result.ECDSAKey).PubKey(), opts.Bech32HRP)
+	}
+	if slices.Contains(opts.Coins, CoinLitecoin) && result.ECDSACurve != CurveP256 {
+		if result.Chains.Litecoin, err = deriveLitecoin(result.ECDSAKey, !opts.UncompressedWIF); err != nil {
+			result.Wipe()
+			return result, err
+		}
+	}
+	if slices.Contains(opts.Coins, CoinXRP) && result.ECDSACurve != CurveP256 {
+		result.Chains.XRP = toXRPAddress(secp256k1.PrivKeyFromBytes(result.ECDSAKey).PubKey())
+	}
+	if slices.Contains(opts.Coins, CoinDogecoin) && result.ECDSACurve != CurveP256 {
+		if result.Chains.Dogecoin, err = deriveDogecoin(result.ECDSAKey, !opts.UncompressedWIF); err != nil {
+			result.Wipe()
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// DeriveChains derives the addresses and WIFs of a vault from its keys. edSK may be nil for an older vault.
+// The Bitcoin WIFs and addresses are for the compressed public key, unless compressedWIF is false.
+func DeriveChains(ecSK, edSK []byte, btcAddressType string, compressedWIF bool) (Chains, error) {
+	var chains Chains
+	pub := secp256k1.PrivKeyFromBytes(ecSK).PubKey()
+	_, address, err := getTSSPubKeyForEthereum(pub.X(), pub.Y())
+	if err != nil {
+		return Chains{}, err
+	}
+	chains.ECDSAPublicKey = hex.EncodeToString(pub.SerializeCompressed())
+	chains.Ethereum, chains.Tron = address, toTronAddress(pub)
+	for _, btc := range []struct {
+		testNet bool
+		out     *Bitcoin
+	}{{false, &chains.BitcoinMainnet}, {true, &chains.BitcoinTestnet}} {
+		if btc.out.Address, err = toBitcoinAddress(pub, btcAddressType, btc.testNet, compressedWIF); err != nil {
+			return Chains{}, err
+		}
+		if btc.out.LegacyAddress, err = toBitcoinAddress(pub, BTCAddressLegacy, btc.testNet, compressedWIF); err != nil {
+			return Chains{}, err
+		}
+		if btc.out.WIF, err = wif.ToBitcoinWIF(ecSK, btc.testNet, compressedWIF); err != nil {
+			return Chains{}, err
+		}
+	}
+
+	if err = deriveEdDSAChains(&chains, edSK); err != nil {
+		return Chains{}, err
+	}
+	return chains, nil
+}
+
+// deriveP256Chains derives the public keys of a vault on P-256 from its keys. edSK may be nil for an older vault.
+func deriveP256Chains(ecSK, edSK []byte) (Chains, error) {
+	chains := Chains{ECDSAPublicKey: p256PublicKey(ecSK)}
+	if err := deriveEdDSAChains(&chains, edSK); err != nil {
+		return Chains{}, err
+	}
+	return chains, nil
+}
+
+// deriveEdDSAChains derives the EdDSA public key and Solana address of a vault into chains, unless edSK is nil.
+func deriveEdDSAChains(chains *Chains, edSK []byte) error {
+	if edSK == nil {
+		return nil
+	}
+	_, edPK, err := edwards.PrivKeyFromScalar(edSK)
+	if err != nil {
+		return fmt.Errorf("ed25519: internal error: setting scalar failed: %v", err)
+	}
+	chains.EdDSAPublicKey = hex.EncodeToString(edPK.SerializeCompressed())
+	chains.Solana, err = toSolanaAddress(edPK.SerializeCompressed())
+	return err
+}
+
+// Wipe overwrites the keys of the result with zeros.
+func (r *Result) Wipe() {
+	clear(r.ECDSAKey)
+	clear(r.EdDSAKey)
+}
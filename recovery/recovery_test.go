@@ -0,0 +1,161 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	ecdsa_keygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover_Progress(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_single.json", Mnemonics: mmNewSingle},
+	}
+	var progress bytes.Buffer
+	opts := NewOptions("phrot42ltzawmn7nrm7mqvl5")
+	opts.Progress = &progress
+
+	result, err := Recover(context.Background(), files, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer result.Wipe()
+	assert.Equal(t, "EdDSA Export Tool Test Luke", result.Name)
+	assert.NotEmpty(t, result.ReShareNonces)
+	assert.Equal(t, "0xeFb4d67625fE88A4B7Cb854b75f6B1ef370550d1", result.Chains.Ethereum)
+	assert.Equal(t, "J5GSXo8C1CGCy6qZJE7JwN93xuZ7R4LWGfXta7R697ig", result.Chains.Solana)
+	// the progress goes to the given writer only, and never carries the keys
+	assert.Contains(t, progress.String(), "Processing V2 share")
+	assert.NotContains(t, progress.String(), "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7")
+}
+
+func TestRecover_Cancelled(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_single.json", Mnemonics: mmNewSingle},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := Recover(ctx, files, NewOptions("phrot42ltzawmn7nrm7mqvl5"))
+	if !assert.ErrorIs(t, err, context.Canceled) {
+		return
+	}
+	assert.Nil(t, result.ECDSAKey)
+	assert.Nil(t, result.EdDSAKey)
+}
+
+func TestInflateSharesForCurve_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	shares, _, err := inflateSharesForCurve[ecdsa_keygen.LocalPartySaveData](ctx, []string{"{}"}, inflateBounds{max: 1024}, nil, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, shares)
+}
+
+func TestRecover_InvalidBech32HRP(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_single.json", Mnemonics: mmNewSingle},
+	}
+	opts := NewOptions("phrot42ltzawmn7nrm7mqvl5")
+	opts.Bech32HRP = "Cosmos"
+
+	result, err := Recover(context.Background(), files, opts)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "mixes upper and lower case")
+	}
+	assert.Nil(t, result)
+}
+
+func TestRecover_Summary(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn},
+		{File: "../test-files/new_x2q.json", Mnemonics: mmNewX2q},
+		{File: "../test-files/new_u44.json", Mnemonics: mmNewU44},
+	}
+	result, err := Recover(context.Background(), files, NewOptions("yz5x2a7zhwwt7r0lv4gklqns"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer result.Wipe()
+	assert.Equal(t, 4, result.Nonce)
+	assert.Equal(t, 3, result.Quorum)
+	assert.Equal(t, 3, result.Threshold)
+	assert.Equal(t, 3, result.Shares)
+}
+
+func TestDeriveLitecoin(t *testing.T) {
+	// the key 1, whose compressed public key hashes to 751e76e8199196d454941c45d1b3a323f1433bd6
+	key := make([]byte, 32)
+	key[31] = 1
+
+	ltc, err := deriveLitecoin(key, true)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "T33ydQRKp4FCW5LCLLUB7deioUMoveiwekdwUwyfRDeGZm76aUjV", ltc.WIF)
+	assert.Equal(t, "LVuDpNCSSj6pQ7t9Pv6d6sUkLKoqDEVUnJ", ltc.LegacyAddress)
+	assert.Equal(t, "ltc1qw508d6qejxtdg4y5r3zarvary0c5xw7kgmn4n9", ltc.Address)
+
+	// an uncompressed WIF has no segwit address
+	ltc, err = deriveLitecoin(key, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "6u823ozcyt2rjPH8Z2ErsSXJB5PPQwK7VVTwwN4mxLBFrao69XQ", ltc.WIF)
+	assert.Empty(t, ltc.Address)
+}
+
+func TestDeriveDogecoin(t *testing.T) {
+	key := make([]byte, 32)
+	key[31] = 1
+
+	doge, err := deriveDogecoin(key, true)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "QNcdLVw8fHkixm6NNyN6nVwxKek4u7qrioRbQmjxac5TVoTtZuot", doge.WIF)
+	assert.Equal(t, "DFpN6QqFfUm3gKNaxN6tNcab1FArL9cZLE", doge.Address)
+
+	doge, err = deriveDogecoin(key, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "6J8csdv3eDrnJcpSEb4shfjMh2JTiG9MKzC1Yfge4Y4GyUsjdM6", doge.WIF)
+	assert.Equal(t, "DJRU7MLhcPwCTNRZ4e8gJzDebtG1H5M7pc", doge.Address)
+}
+
+func TestRecover_Coins(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_single.json", Mnemonics: mmNewSingle},
+	}
+	opts := NewOptions("phrot42ltzawmn7nrm7mqvl5")
+	result, err := Recover(context.Background(), files, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Empty(t, result.Chains.Litecoin)
+	assert.Empty(t, result.Chains.Dogecoin)
+
+	opts.Coins = []string{CoinLitecoin, CoinDogecoin, CoinXRP}
+	result, err = Recover(context.Background(), files, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Regexp(t, "^ltc1q", result.Chains.Litecoin.Address)
+	assert.Regexp(t, "^L", result.Chains.Litecoin.LegacyAddress)
+	assert.Regexp(t, "^D", result.Chains.Dogecoin.Address)
+	assert.Regexp(t, "^r", result.Chains.XRP)
+
+	opts.Coins = []string{"xmr"}
+	_, err = Recover(context.Background(), files, opts)
+	if assert.ErrorIs(t, err, ErrInvalidInput) {
+		assert.Contains(t, err.Error(), "unknown coin `xmr`, expected one of: ltc, doge, xrp")
+	}
+}
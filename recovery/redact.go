@@ -0,0 +1,87 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// redacted replaces a secret in the output.
+const redacted = "[redacted]"
+
+var (
+	// numberPattern matches a run of decimal or hex digits that is long enough to be key material, with or without 0x.
+	numberPattern = regexp.MustCompile(`(?i)\b(?:0x)?[0-9a-f]{16,}\b`)
+
+	// secretFingerprints holds the SHA-256 of every share Xi and recovered key seen by this process, so that Redact finds them
+	// in any encoding without keeping a copy of the secrets themselves, which are wiped once used.
+	secretFingerprints sync.Map
+)
+
+// registerSecret adds the value of a secret to the ones that Redact removes. A nil or zero value is skipped.
+func registerSecret(secret []byte) {
+	n := new(big.Int).SetBytes(secret)
+	defer n.SetInt64(0)
+	registerSecretInt(n)
+}
+
+// registerSecretInt adds a secret number to the ones that Redact removes. A nil or zero value is skipped.
+func registerSecretInt(secret *big.Int) {
+	if secret == nil || secret.Sign() == 0 {
+		return
+	}
+	secretFingerprints.Store(fingerprint(secret), struct{}{})
+}
+
+// fingerprint hashes the minimal big-endian bytes of n, so that the decimal and hex forms of a number share one fingerprint.
+func fingerprint(n *big.Int) [32]byte {
+	b := n.Bytes()
+	defer clear(b)
+	return sha256.Sum256(b)
+}
+
+// isSecret reports whether digits, in the given base, are the value of a registered secret.
+func isSecret(digits string, base int) bool {
+	n, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return false
+	}
+	defer n.SetInt64(0)
+	_, found := secretFingerprints.Load(fingerprint(n))
+	return found
+}
+
+// Redact removes key material from a message before it is printed or logged: every share Xi and recovered key seen by
+// this process, in decimal or in hex. Other numbers, e.g. share ids, addresses and file hashes, are left alone, as they
+// say which share, vault or file a message is about.
+func Redact(msg string) string {
+	return numberPattern.ReplaceAllStringFunc(msg, func(number string) string {
+		digits := number
+		if len(digits) > 2 && strings.EqualFold(digits[:2], "0x") {
+			digits = digits[2:]
+		}
+		if isSecret(digits, 16) || (strings.Trim(digits, "0123456789") == "" && isSecret(digits, 10)) {
+			return redacted
+		}
+		return number
+	})
+}
+
+// jsonDecodeError describes an error of json.Unmarshal on decrypted data without quoting the data. A syntax or type error
+// only names an offset or a field, but the error of a value's own decoder, e.g. of a *big.Int, quotes the value, which may be a share's Xi.
+func jsonDecodeError(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return err.Error()
+	}
+	return "a value could not be decoded"
+}
@@ -0,0 +1,102 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	secret, _ := new(big.Int).SetString("0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", 16)
+	registerSecretInt(secret)
+
+	tests := []struct {
+		name, msg, expected string
+	}{
+		{"Hex", "key 0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7.", "key [redacted]."},
+		{"Prefixed Upper Case Hex", "key 0x0A8376F6CB75D7E4197D35D2F7254F60F08827D5604589EA57843C3F754983B7", "key [redacted]"},
+		{"Unpadded Hex", "key a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", "key [redacted]"},
+		{"Decimal", "Xi `" + secret.String() + "`", "Xi `[redacted]`"},
+		{"Quoted In JSON", `cannot unmarshal "\"` + secret.String() + `\""`, `cannot unmarshal "\"[redacted]\""`},
+		{"Other Numbers", "share 123456789012345678901234567890 of 0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf",
+			"share 123456789012345678901234567890 of 0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},
+		{"File Hash", "hash 5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03", "hash 5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Redact(tt.msg))
+		})
+	}
+}
+
+func TestJSONDecodeError(t *testing.T) {
+	var share struct{ Xi *big.Int }
+	err := json.Unmarshal([]byte(`{"Xi":"1234567890123456789"}`), &share)
+	if assert.ErrorContains(t, err, "1234567890123456789") {
+		assert.Equal(t, "a value could not be decoded", jsonDecodeError(err))
+	}
+	err = json.Unmarshal([]byte(`{"Xi":[1234567890123456789]}`), &share)
+	if assert.Error(t, err) {
+		assert.NotContains(t, jsonDecodeError(err), "1234567890123456789")
+	}
+	err = json.Unmarshal([]byte(`{"Xi":12x}`), &share)
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), jsonDecodeError(err))
+	}
+}
+
+func TestTool_SecretsNotInErrors(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/i.json", Mnemonics: mmI},
+		{File: "../test-files/l.json", Mnemonics: mmL},
+	}
+	vaultID := "clujhtm9d0013wc3xso1b2m0k"
+
+	// the shares of a successful recovery and its key are redacted from then on
+	opts := NewOptions(vaultID)
+	_, ecSK, _, _, _, err := runTool(context.Background(), files, &vaultID, &opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "key [redacted]", Redact("key "+hex.EncodeToString(ecSK)))
+
+	// a share whose Xi is a quoted string fails to decode with an error that would quote the Xi
+	xis := make([]string, 0, 8)
+	quoteXi := func(clearVault map[string]json.RawMessage) {
+		var shares []string
+		if err := json.Unmarshal(clearVault["shares"], &shares); err != nil {
+			t.Fatal(err)
+		}
+		share := make(map[string]json.RawMessage)
+		if err := json.Unmarshal([]byte(shares[0]), &share); err != nil {
+			t.Fatal(err)
+		}
+		xis = append(xis, string(share["Xi"]))
+		share["Xi"] = json.RawMessage(`"` + string(share["Xi"]) + `"`)
+		raw, _ := json.Marshal(share)
+		shares[0] = string(raw)
+		clearVault["shares"], _ = json.Marshal(shares)
+	}
+	files[0].File = reencryptBackupFile(t, files[0].File, mmI, quoteXi)
+	_, _, _, _, _, err = runTool(context.Background(), files, &vaultID, &opts)
+	if !assert.ErrorContains(t, err, "share 1 of the vault is not in the expected share format (a value could not be decoded)") {
+		return
+	}
+	assert.NotEmpty(t, xis)
+	for _, xi := range xis {
+		n, ok := new(big.Int).SetString(xi, 10)
+		if assert.True(t, ok, xi) {
+			assert.NotContains(t, err.Error(), xi)
+			assert.NotContains(t, strings.ToLower(err.Error()), n.Text(16))
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/secmem"
+)
+
+type (
+	// ShareInfo describes one share of a vault found in the files, without its secret.
+	ShareInfo struct {
+		File  string
+		Nonce int
+		// Curve is CurveECDSA or CurveEdDSA.
+		Curve   string
+		ShareID string
+		// PartyIndex is the 1-based position of the share among the vault's parties, and Parties their count; both are 0 if the share does not record them.
+		PartyIndex int
+		Parties    int
+		// Duplicate is set when a share with the same ID and curve was found in an earlier file.
+		Duplicate bool
+	}
+
+	// VaultShares lists the shares of a vault found in the files. Skipped describes the corrupt V2 shares that could not be decoded,
+	// with the file each is in.
+	VaultShares struct {
+		VaultID   string
+		Name      string
+		Threshold int
+		Shares    []ShareInfo
+		Skipped   []string
+	}
+)
+
+// Curves for ShareInfo.Curve
+const (
+	CurveECDSA = "ECDSA"
+	CurveEdDSA = "EdDSA"
+)
+
+// Distinct counts the shares of the given curve that are not duplicates, which is what counts towards the threshold.
+func (v VaultShares) Distinct(curve string) int {
+	n := 0
+	for _, share := range v.Shares {
+		if share.Curve == curve && !share.Duplicate {
+			n++
+		}
+	}
+	return n
+}
+
+// ListShares decodes the shares of the vault given by opts.VaultID in every file, at the latest reshare nonce of each file or at opts.NonceOverride,
+// and describes them. No key is reconstructed, and the share secrets are wiped as soon as they are decoded.
+func ListShares(ctx context.Context, vaultsDataFile []VaultsDataFile, opts Options) (*VaultShares, error) {
+	if opts.VaultID == "" {
+		return nil, WithKind(ErrInvalidInput, fmt.Errorf("⚠ no vault id given"))
+	}
+	bounds := inflateBounds{min: kbToBytes(opts.MinInflatedKB), max: kbToBytes(opts.MaxInflatedKB)}
+	vault := &VaultShares{VaultID: opts.VaultID}
+	seen := make(map[string][]string, 2)
+
+	for _, file := range vaultsDataFile {
+		if err := cancelled(ctx); err != nil {
+			return nil, err
+		}
+		var resharesMap CipheredVaultMap
+		err := readBackup(file.File, func(vID string, reshares CipheredVaultMap) error {
+			if vID == opts.VaultID {
+				resharesMap = reshares
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if resharesMap == nil {
+			continue
+		}
+		nonce := opts.NonceOverride
+		if nonce < 0 {
+			for n := range resharesMap {
+				nonce = max(nonce, n)
+			}
+		}
+		cipheredVault, ok := resharesMap[nonce]
+		if !ok {
+			continue
+		}
+
+		// phrase -> key
+		aesKey32, err := phraseKey(file)
+		if err != nil {
+			return nil, err
+		}
+		secmem.Lock(aesKey32)
+		job := vaultJob{aesKey32: aesKey32, vaultID: opts.VaultID, file: file.File, nonce: nonce, cipheredVault: cipheredVault}
+		result := decryptVaultJob(ctx, job, bounds, nil, newProgressWriter(opts.Progress, false), nil)
+		clear(aesKey32)
+		for _, share := range result.sharesECDSA {
+			vault.Shares = append(vault.Shares, newShareInfo(file.File, nonce, CurveECDSA, share.ShareID, share.Ks, seen))
+		}
+		for _, share := range result.sharesEDDSA {
+			vault.Shares = append(vault.Shares, newShareInfo(file.File, nonce, CurveEdDSA, share.ShareID, share.Ks, seen))
+		}
+		for _, skipped := range result.skippedShares {
+			vault.Skipped = append(vault.Skipped, fmt.Sprintf("`%s`: %s", file.File, skipped))
+		}
+		wipeShareSecrets(VaultAllSharesECDSA{opts.VaultID: result.sharesECDSA}, VaultAllSharesEdDSA{opts.VaultID: result.sharesEDDSA})
+		if result.err != nil {
+			return nil, result.err
+		}
+		if vault.Name == "" {
+			vault.Name, vault.Threshold = result.vault.Name, result.vault.Quroum
+		}
+	}
+	if len(vault.Shares) == 0 && len(vault.Skipped) == 0 {
+		return nil, WithKind(ErrInvalidInput, fmt.Errorf("⚠ provided files do not contain data for vault `%s` with the expected reshare nonce", opts.VaultID))
+	}
+	return vault, nil
+}
+
+// newShareInfo describes a share and records its ID in seen, per curve, to flag the shares found in more than one file.
+func newShareInfo(file string, nonce int, curve string, shareID *big.Int, ks []*big.Int, seen map[string][]string) ShareInfo {
+	info := ShareInfo{File: file, Nonce: nonce, Curve: curve}
+	if shareID == nil {
+		return info
+	}
+	info.ShareID = shareID.String()
+	info.Duplicate = slices.Contains(seen[curve], info.ShareID)
+	seen[curve] = append(seen[curve], info.ShareID)
+	for i, k := range ks {
+		if k != nil && k.Cmp(shareID) == 0 {
+			info.PartyIndex, info.Parties = i+1, len(ks)
+		}
+	}
+	return info
+}
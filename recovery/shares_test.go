@@ -0,0 +1,53 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListShares(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn},
+		{File: "../test-files/new_x2q.json", Mnemonics: mmNewX2q},
+		{File: "../test-files/new_u44.json", Mnemonics: mmNewU44},
+		// the same file twice holds the same shares twice
+		{File: "../test-files/new_u44.json", Mnemonics: mmNewU44},
+	}
+	vault, err := ListShares(context.Background(), files, NewOptions("yz5x2a7zhwwt7r0lv4gklqns"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "testNewVault111", vault.Name)
+	assert.Equal(t, 3, vault.Threshold)
+	if !assert.Len(t, vault.Shares, 8) {
+		return
+	}
+	assert.Equal(t, ShareInfo{File: "../test-files/new_bvn.json", Nonce: 4, Curve: CurveECDSA, ShareID: "52117527412301", PartyIndex: 1, Parties: 3}, vault.Shares[0])
+	assert.Equal(t, CurveEdDSA, vault.Shares[1].Curve)
+	assert.True(t, vault.Shares[6].Duplicate)
+	assert.True(t, vault.Shares[7].Duplicate)
+	assert.Equal(t, 3, vault.Distinct(CurveECDSA))
+}
+
+func TestListShares_Errors(t *testing.T) {
+	files := []VaultsDataFile{{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn}}
+
+	_, err := ListShares(context.Background(), files, NewOptions(""))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "no vault id given")
+	}
+	_, err = ListShares(context.Background(), files, NewOptions("nope"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "do not contain data for vault `nope`")
+	}
+	opts := NewOptions("yz5x2a7zhwwt7r0lv4gklqns")
+	opts.NonceOverride = 99
+	_, err = ListShares(context.Background(), files, opts)
+	assert.Error(t, err)
+}
@@ -0,0 +1,1120 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/data"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/secmem"
+	"github.com/binance-chain/tss-lib/crypto"
+	"github.com/binance-chain/tss-lib/crypto/vss"
+	ecdsa_keygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+	eddsa_keygen "github.com/binance-chain/tss-lib/eddsa/keygen"
+	"github.com/binance-chain/tss-lib/tss"
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/ethereum/go-ethereum/common"
+	errors2 "github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+)
+
+// seedPhraseHint is shown when a valid BIP39 phrase fails to decrypt a file; users sometimes enter a wallet seed phrase instead.
+const seedPhraseHint = "The phrase you entered is a valid BIP39 phrase, but it did not decrypt this file. " +
+	"It must be the decryption phrase provided by io.finnet for this backup file, not a wallet seed phrase. " +
+	"Check that you are using the right phrase for the right file"
+
+// runTool decrypts the backup files and lists their vaults, or recovers the keys of vaultID if one is given.
+// It stops between the steps once ctx is done, wiping the shares decoded so far.
+func runTool(ctx context.Context, vaultsDataFile []VaultsDataFile, vaultID *string, opts *Options) (
+	address string, ecdsaSK, eddsaSK []byte, orderedVaults []VaultSummary, warnings []Warning, welp error) {
+
+	justListingVaults := vaultID == nil || *vaultID == ""
+
+	// nil options mean no overrides, the default share size bounds and no progress output
+	var progress *progressWriter
+	var verboseLog io.Writer
+	var cache *shareCache
+	nonceOverride, quorumOverride, autoThreshold := -1, 0, false
+	// an explicit nonce override needs every file to hold shares of the vault at that nonce
+	strictNonce := false
+	bounds := inflateBounds{min: kbToBytes(DefaultMinInflatedKB), max: kbToBytes(DefaultMaxInflatedKB)}
+	if opts != nil {
+		nonceOverride, quorumOverride, autoThreshold = opts.NonceOverride, opts.QuorumOverride, opts.AutoThreshold
+		strictNonce = nonceOverride > -1 && !opts.probeNonce && !justListingVaults
+		bounds = inflateBounds{min: kbToBytes(opts.MinInflatedKB), max: kbToBytes(opts.MaxInflatedKB)}
+		cache = opts.shares
+		// the vaults are decrypted in parallel, so the writes to the progress output are serialized
+		out := newProgressWriter(opts.Progress, opts.ProgressStatus && !justListingVaults)
+		// progress output is only shown when recovering
+		if !justListingVaults {
+			progress = out
+		}
+		if opts.Verbose && out != nil {
+			verboseLog = out
+		}
+	}
+	defer progress.finish()
+
+	// the overrides only apply when recovering a vault
+	if !justListingVaults && nonceOverride > -1 {
+		warnings = append(warnings, Warning{
+			Kind:    WarnNonceOverride,
+			VaultID: *vaultID,
+			Message: fmt.Sprintf("Using reshare nonce override: %d. Be sure to set the threshold of the vault at this reshare point with -threshold, or recovery will produce incorrect data.", nonceOverride),
+		})
+	}
+	if !justListingVaults && quorumOverride > 0 {
+		warnings = append(warnings, Warning{
+			Kind:    WarnQuorumOverride,
+			VaultID: *vaultID,
+			Message: fmt.Sprintf("Using vault quorum override: %d.", quorumOverride),
+		})
+	}
+
+	// Internal & returned data structures
+	clearVaults := make(ClearVaultMap, len(vaultsDataFile)*16)
+	vaultAllSharesECDSA := make(VaultAllSharesECDSA, len(vaultsDataFile)*16) // headroom
+	vaultAllSharesEDDSA := make(VaultAllSharesEdDSA, len(vaultsDataFile)*16)
+	vaultHasEDDSA := make(map[string]bool, len(vaultsDataFile)*16)
+	vaultLastNonces := make(map[string]int, len(vaultsDataFile)*16)
+	vaultNonces := make(map[string][]int, len(vaultsDataFile)*16)
+	mismatchedNonces := make([]string, 0, 1)
+	// the share secrets are no longer needed once this returns, whether or not the recovery succeeded
+	defer wipeShareSecrets(vaultAllSharesECDSA, vaultAllSharesEDDSA)
+
+	// // Do the main routine
+	// the vaults to decrypt are picked from the files in order, then decrypted and inflated in parallel
+	jobs := make([]vaultJob, 0, len(vaultsDataFile)*16)
+	for _, file := range vaultsDataFile {
+		if welp = cancelled(ctx); welp != nil {
+			return
+		}
+		// the vaults are picked as the file is read, and only the picked reshare of each is kept, so that a large file
+		// is never held in memory as a whole
+		fileJobs := len(jobs)
+		var fileReshares CipheredVaultMap
+		err := readBackup(file.File, func(vID string, resharesMap CipheredVaultMap) error {
+			// only look at the vault we're interested in, if one was supplied
+			if !justListingVaults && vID != *vaultID {
+				return nil
+			}
+			fileReshares = resharesMap
+
+			// take the highest reshareNonce we have saved (best effort)
+			lastReshareNonce := -1
+			for nonce := range resharesMap {
+				if !slices.Contains(vaultNonces[vID], nonce) {
+					vaultNonces[vID] = append(vaultNonces[vID], nonce)
+				}
+				// support the -nonce flag to override the last reshare nonce we use
+				if !justListingVaults && nonceOverride > -1 && nonceOverride != nonce {
+					continue
+				}
+				if nonce > lastReshareNonce {
+					lastReshareNonce = nonce
+				}
+			}
+			if lastReshareNonce == -1 {
+				//welp = fmt.Errorf("⚠ no share data found for vault `%s` in save file", vID)
+				return nil // not a show stopper
+			}
+			if glbLastReShareNonce, ok := vaultLastNonces[vID]; ok && glbLastReShareNonce != lastReshareNonce && !slices.Contains(mismatchedNonces, vID) {
+				mismatchedNonces = append(mismatchedNonces, vID)
+			}
+			vaultLastNonces[vID] = lastReshareNonce
+			jobs = append(jobs, vaultJob{vaultID: vID, file: file.File, nonce: lastReshareNonce, cipheredVault: resharesMap[lastReshareNonce]})
+			return nil
+		})
+		if err != nil {
+			welp = err
+			return
+		}
+
+		// phrase -> key
+		aesKey32, err := phraseKey(file)
+		if err != nil {
+			welp = err
+			return
+		}
+		secmem.Lock(aesKey32)
+		defer clear(aesKey32)
+		for i := fileJobs; i < len(jobs); i++ {
+			jobs[i].aesKey32 = aesKey32
+		}
+
+		if strictNonce {
+			if _, ok := fileReshares[nonceOverride]; !ok {
+				welp = noSharesAtNonceError(file.File, *vaultID, nonceOverride, fileReshares)
+				return
+			}
+		}
+	}
+	for vID := range vaultNonces {
+		slices.Sort(vaultNonces[vID])
+	}
+	// the advice names the nonces that are in the files, which are only all known once every file is read
+	for _, vID := range mismatchedNonces {
+		nonces := vaultNonces[vID]
+		msg := fmt.Sprintf("Non matching reshare nonce for vault `%s`. You may have to specify prior reshare config with -nonce and -threshold when recovering that vault.", vID)
+		msg += fmt.Sprintf("\n⚠ The files hold the reshare nonces %s of that vault.", formatNonces(nonces))
+		if len(nonces) > 1 {
+			msg += fmt.Sprintf("\n⚠ If you have problems recovering that vault, you could try: -vault-id %s -nonce %d -threshold x. Replace x with previous vault threshold.", vID, nonces[len(nonces)-2])
+		}
+		msg += "\n⚠ Run with -list-generations to see which reshare generation has enough shares."
+		warnings = append(warnings, Warning{Kind: WarnNonceMismatch, VaultID: vID, Message: msg})
+	}
+
+	// the results are merged in the order of the jobs, so that the shares and the vault list do not depend on the scheduling.
+	// The shares of a failed job are merged too, so that they are wiped on return.
+	vaultFiles := make(map[string][]string, len(jobs))
+	nonceShares := make([]string, 0, len(jobs))
+	// every file stores the threshold of the vault, so the files of a single reshare agree on it
+	storedQuorums := make(map[int][]string, 1)
+	corruptShares := make(map[string]int)
+	for i, result := range decryptVaults(ctx, jobs, bounds, cache, progress, verboseLog) {
+		vID := result.vaultID
+		if len(result.skippedShares) > 0 {
+			corruptShares[vID] += len(result.skippedShares)
+			msg := fmt.Sprintf("Skipped %d corrupt share(s) of vault `%s` in file `%s`, which may be damaged; the shares of the other files may still reach the threshold:",
+				len(result.skippedShares), vID, jobs[i].file)
+			for _, skipped := range result.skippedShares {
+				msg += "\n⚠ " + skipped + "."
+			}
+			warnings = append(warnings, Warning{Kind: WarnCorruptShares, VaultID: vID, Message: msg})
+		}
+		if strictNonce && result.err == nil {
+			if len(result.sharesECDSA) == 0 && len(result.skippedShares) == 0 && welp == nil {
+				welp = WithKind(ErrInvalidInput, fmt.Errorf("⚠ -nonce %d: the reshare of vault `%s` at that nonce in file `%s` holds no shares. Remove the file, as it does not add to the recovery",
+					nonceOverride, vID, jobs[i].file))
+			}
+			nonceShares = append(nonceShares, fmt.Sprintf("\n⚠ `%s`: %d share(s) at reshare nonce %d.", jobs[i].file, len(result.sharesECDSA), nonceOverride))
+		}
+		if len(result.sharesECDSA) > 0 && !slices.Contains(vaultFiles[vID], jobs[i].file) {
+			vaultFiles[vID] = append(vaultFiles[vID], jobs[i].file)
+		}
+		if result.err != nil && welp == nil {
+			welp = result.err
+		}
+		if result.vault != nil {
+			clearVaults[vID] = result.vault
+			if !justListingVaults && result.vault.Quroum > 0 && !slices.Contains(storedQuorums[result.vault.Quroum], jobs[i].file) {
+				storedQuorums[result.vault.Quroum] = append(storedQuorums[result.vault.Quroum], jobs[i].file)
+			}
+		}
+		if result.sharesECDSA != nil {
+			vaultAllSharesECDSA[vID] = append(vaultAllSharesECDSA[vID], result.sharesECDSA...)
+		}
+		if result.sharesEDDSA != nil {
+			vaultAllSharesEDDSA[vID] = append(vaultAllSharesEDDSA[vID], result.sharesEDDSA...)
+			vaultHasEDDSA[vID] = true
+		}
+	}
+	if welp != nil {
+		return
+	}
+	for i := range warnings {
+		if warnings[i].Kind == WarnNonceOverride {
+			warnings[i].Message += strings.Join(nonceShares, "")
+		}
+	}
+
+	// a partly corrupt share may lack its public key, so it can't be checked against the vault's, and is left out
+	keylessShares := make(map[string]int)
+	for vID := range vaultAllSharesECDSA {
+		var keylessECDSA, keylessEDDSA []string
+		vaultAllSharesECDSA[vID], keylessECDSA = dropKeylessShares(vaultAllSharesECDSA[vID], func(sd *ecdsa_keygen.LocalPartySaveData) (*big.Int, *big.Int, *crypto.ECPoint) {
+			return sd.ShareID, sd.Xi, sd.ECDSAPub
+		})
+		if shares, ok := vaultAllSharesEDDSA[vID]; ok {
+			vaultAllSharesEDDSA[vID], keylessEDDSA = dropKeylessShares(shares, func(sd *eddsa_keygen.LocalPartySaveData) (*big.Int, *big.Int, *crypto.ECPoint) {
+				return sd.ShareID, sd.Xi, sd.EDDSAPub
+			})
+		}
+		if len(keylessECDSA) > 0 || len(keylessEDDSA) > 0 {
+			keylessShares[vID] = len(keylessECDSA) + len(keylessEDDSA)
+			msg := fmt.Sprintf("Skipped share(s) of vault `%s` that carry no valid public key, so the backup may be corrupt:", vID)
+			if len(keylessECDSA) > 0 {
+				msg += fmt.Sprintf(" ECDSA share(s) %s", strings.Join(keylessECDSA, ", "))
+			}
+			if len(keylessEDDSA) > 0 {
+				if len(keylessECDSA) > 0 {
+					msg += " and"
+				}
+				msg += fmt.Sprintf(" EdDSA share(s) %s", strings.Join(keylessEDDSA, ", "))
+			}
+			warnings = append(warnings, Warning{Kind: WarnKeylessShares, VaultID: vID, Message: msg + "."})
+		}
+	}
+
+	// overlapping files may hold the same party's share more than once, and interpolation needs distinct share IDs
+	for vID := range vaultAllSharesECDSA {
+		var droppedECDSA, droppedEDDSA int
+		vaultAllSharesECDSA[vID], droppedECDSA = dedupeShares(vaultAllSharesECDSA[vID], func(sd *ecdsa_keygen.LocalPartySaveData) (*big.Int, *big.Int) {
+			return sd.ShareID, sd.Xi
+		})
+		if shares, ok := vaultAllSharesEDDSA[vID]; ok {
+			vaultAllSharesEDDSA[vID], droppedEDDSA = dedupeShares(shares, func(sd *eddsa_keygen.LocalPartySaveData) (*big.Int, *big.Int) {
+				return sd.ShareID, sd.Xi
+			})
+		}
+		if dropped := max(droppedECDSA, droppedEDDSA); dropped > 0 {
+			warnings = append(warnings, Warning{
+				Kind:    WarnDuplicateShares,
+				VaultID: vID,
+				Message: fmt.Sprintf("Dropped %d duplicate share(s) of vault `%s`: the same share was found in more than one file.", dropped, vID),
+			})
+		}
+	}
+
+	// populate vault IDs
+	vaultIDs := make([]string, 0, len(vaultsDataFile)*16)
+	for vID := range clearVaults {
+		vaultIDs = append(vaultIDs, vID)
+	}
+	sort.Strings(vaultIDs)
+
+	// Create the list of ordered vaults from the ordered vault IDs
+	orderedVaults = make([]VaultSummary, 0, len(vaultIDs))
+	for _, vID := range vaultIDs {
+		vault := clearVaults[vID]
+		vaultFormData := VaultSummary{
+			VaultID:          vID,
+			Name:             vault.Name,
+			Quorum:           vault.Quroum,
+			LastReShareNonce: vault.LastReShareNonce,
+			NumberOfShares:   len(vaultAllSharesECDSA[vID]),
+			ReShareNonces:    vaultNonces[vID],
+			Files:            vaultFiles[vID],
+		}
+		if shares := vaultAllSharesECDSA[vID]; len(shares) > 0 {
+			vaultFormData.Parties = len(shares[0].Ks)
+		}
+		orderedVaults = append(orderedVaults, vaultFormData)
+	}
+
+	// Just list the ID's and names?
+	if justListingVaults {
+		// a vault that can't be recovered from these files is flagged now rather than once it is picked
+		for _, vault := range orderedVaults {
+			if vault.NumberOfShares < vault.Quorum {
+				warnings = append(warnings, notEnoughSharesWarning(vault))
+			}
+		}
+		return "", nil, nil, orderedVaults, warnings, nil
+	}
+
+	if progress != nil {
+		progress.finish()
+		fmt.Fprintln(progress)
+	}
+	if welp = cancelled(ctx); welp != nil {
+		return
+	}
+	if _, ok := vaultAllSharesECDSA[*vaultID]; !ok {
+		welp = WithKind(ErrInvalidInput, fmt.Errorf("⚠ provided files do not contain data for vault `%s` with the expected reshare nonce", *vaultID))
+		return
+	}
+	if len(vaultAllSharesECDSA[*vaultID]) == 0 && keylessShares[*vaultID] > 0 {
+		welp = WithKind(ErrBadFormat, fmt.Errorf("⚠ none of the ECDSA shares of vault `%s` carries a valid public key, so its key can't be checked; the backup may be corrupt", *vaultID))
+		return
+	}
+	// a skipped share is already reported, and the key can still be recovered from the others
+	if vaultHasEDDSA[*vaultID] && keylessShares[*vaultID] == 0 && corruptShares[*vaultID] == 0 && len(vaultAllSharesEDDSA[*vaultID]) != len(vaultAllSharesECDSA[*vaultID]) {
+		// the shares of files that disagree on the latest nonce are of different reshares, which don't line up, like their public keys
+		kind := ErrBadFormat
+		if slices.Contains(mismatchedNonces, *vaultID) {
+			kind = ErrPubKeyMismatch
+		}
+		welp = WithKind(kind, fmt.Errorf("⚠ count of EDDSA shares %d != count of ECDSA shares %d for vault `%s`",
+			len(vaultAllSharesEDDSA[*vaultID]), len(vaultAllSharesECDSA[*vaultID]), *vaultID))
+		return
+	}
+
+	sharesECDSA, sharesEDDSA := vaultAllSharesECDSA[*vaultID], vaultAllSharesEDDSA[*vaultID]
+
+	if welp = sharesMismatchError(*vaultID, sharesECDSA, sharesEDDSA); welp != nil {
+		return
+	}
+	tPlus1 := clearVaults[*vaultID].Quroum
+	if quorumOverride > 0 {
+		tPlus1 = quorumOverride
+	}
+	if w, ok := thresholdMismatchWarning(*vaultID, storedQuorums, tPlus1, quorumOverride > 0); ok {
+		warnings = append(warnings, w)
+	}
+	// the curve of the vault's ECDSA key is the one its shares are on, which must be the one its curve algorithm names, if any
+	var curve elliptic.Curve
+	if curve, welp = ecdsaCurve(sharesECDSA, clearVaults[*vaultID].ECDSACurve); welp != nil {
+		return
+	}
+	// with too few intact EdDSA shares left, the EdDSA key can't be recovered, but the ECDSA key still can
+	if vaultHasEDDSA[*vaultID] && len(sharesEDDSA) < tPlus1 && len(sharesECDSA) >= tPlus1 && keylessShares[*vaultID]+corruptShares[*vaultID] > 0 {
+		warnings = append(warnings, Warning{
+			Kind:    WarnCorruptShares,
+			VaultID: *vaultID,
+			Message: fmt.Sprintf("The EdDSA key of vault `%s` can't be recovered, as only %d of the %d EdDSA shares it needs are intact; only its ECDSA key is recovered.",
+				*vaultID, len(sharesEDDSA), tPlus1),
+		})
+		sharesEDDSA = nil
+	}
+	var pk *ecdsa.PublicKey
+	if len(sharesECDSA) < tPlus1 {
+		welp = WithKind(ErrInsufficientShares, fmt.Errorf("⚠ not enough shares to recover the key for vault %s (need %d, have %d)", *vaultID, tPlus1, len(sharesECDSA)))
+	} else {
+		ecdsaSK, eddsaSK, pk, welp = reconstructKeys(curve, sharesECDSA, sharesEDDSA, tPlus1)
+	}
+	if welp != nil && autoThreshold && quorumOverride == 0 {
+		// the stored quorum may be wrong; find the smallest number of shares that reconstructs the share 0 public key
+		found := false
+		for t := 1; t <= len(sharesECDSA) && !found; t++ {
+			if welp = cancelled(ctx); welp != nil {
+				return
+			}
+			edShares := sharesEDDSA[:min(t, len(sharesEDDSA))]
+			if ecdsaSK, eddsaSK, pk, welp = reconstructKeys(curve, sharesECDSA[:t], edShares, t); welp == nil {
+				found, tPlus1 = true, t
+				warnings = append(warnings, Warning{
+					Kind:    WarnThresholdDetected,
+					VaultID: *vaultID,
+					Message: fmt.Sprintf("Auto-detected threshold %d for vault `%s` (the backup says %d): %d share(s) reconstruct the vault's public key.", t, *vaultID, clearVaults[*vaultID].Quroum, t),
+				})
+			}
+		}
+		if !found {
+			welp = WithKind(ErrPubKeyMismatch, fmt.Errorf("⚠ -auto-threshold: no threshold from 1 to %d reconstructs the public key of vault %s; the shares may be from different reshares or corrupt", len(sharesECDSA), *vaultID))
+		}
+	}
+	if welp != nil {
+		return
+	}
+
+	// encode Ethereum address for human sanity check; a P-256 key has no Ethereum address
+	curveName, _ := ecdsaCurveName(curve)
+	if curveName == CurveSecp256k1 {
+		if _, address, welp = getTSSPubKeyForEthereum(pk.X, pk.Y); welp != nil {
+			return
+		}
+	}
+	for i := range orderedVaults {
+		if orderedVaults[i].VaultID == *vaultID {
+			orderedVaults[i].Threshold, orderedVaults[i].ECDSACurve = tPlus1, curveName
+		}
+	}
+
+	return address, ecdsaSK, eddsaSK, orderedVaults, warnings, nil
+}
+
+// thresholdMismatchWarning flags a threshold that the files of a vault disagree on, or that -threshold overrides with another one,
+// as a sign of a wrong -threshold or -nonce, or of files from different reshares. storedQuorums are the files per stored threshold.
+func thresholdMismatchWarning(vaultID string, storedQuorums map[int][]string, tPlus1 int, overridden bool) (Warning, bool) {
+	quorums := make([]int, 0, len(storedQuorums))
+	for q := range storedQuorums {
+		quorums = append(quorums, q)
+	}
+	slices.Sort(quorums)
+	stored := make([]string, len(quorums))
+	for i, q := range quorums {
+		stored[i] = fmt.Sprintf("%d in `%s`", q, strings.Join(storedQuorums[q], "`, `"))
+	}
+
+	var msg string
+	switch {
+	case len(quorums) > 1:
+		msg = fmt.Sprintf("The files store different thresholds for vault `%s`: %s. Threshold %d is used; "+
+			"the files may be from different reshares, so check -nonce and -threshold.", vaultID, strings.Join(stored, ", "), tPlus1)
+	case overridden && len(quorums) == 1 && quorums[0] != tPlus1:
+		msg = fmt.Sprintf("-threshold %d differs from the threshold that the files store for vault `%s`: %s. "+
+			"If the recovery fails, the -threshold or -nonce may be wrong.", tPlus1, vaultID, stored[0])
+	default:
+		return Warning{}, false
+	}
+	return Warning{Kind: WarnThresholdMismatch, VaultID: vaultID, Message: msg}, true
+}
+
+// noSharesAtNonceError explains that a file adds no shares to the recovery of a vault at an explicit nonce override.
+// fileReshares are the reshares of the vault in the file, which may be none.
+func noSharesAtNonceError(file, vaultID string, nonce int, fileReshares map[int]CipheredVault) error {
+	held := "nor at any other"
+	if len(fileReshares) > 0 {
+		nonces := make([]int, 0, len(fileReshares))
+		for n := range fileReshares {
+			nonces = append(nonces, n)
+		}
+		slices.Sort(nonces)
+		held = "only at reshare nonce(s) " + formatNonces(nonces)
+	}
+	return WithKind(ErrInvalidInput, fmt.Errorf("⚠ -nonce %d: file `%s` holds no shares of vault `%s` at that reshare nonce, %s. "+
+		"Remove the file, as it does not add to the recovery, or pick another -nonce", nonce, file, vaultID, held))
+}
+
+// formatNonces lists reshare nonces for a message, e.g. "1, 2 and 4".
+func formatNonces(nonces []int) string {
+	strs := make([]string, len(nonces))
+	for i, nonce := range nonces {
+		strs[i] = strconv.Itoa(nonce)
+	}
+	if len(strs) < 2 {
+		return strings.Join(strs, "")
+	}
+	return strings.Join(strs[:len(strs)-1], ", ") + " and " + strs[len(strs)-1]
+}
+
+// ReconstructKey interpolates the ECDSA private key of a vault from its shares in the group of the given curve, e.g. tss.S256()
+// or elliptic.P256(), with threshold the number of shares the key needs, and checks it against the public key of share 0.
+// The shares are not changed, so it may be called again with other shares or another threshold. The caller should wipe
+// the key once it is no longer needed; on an error, no key is returned.
+func ReconstructKey(shares []*ecdsa_keygen.LocalPartySaveData, threshold int, curve elliptic.Curve) (*big.Int, *ecdsa.PublicKey, error) {
+	if threshold < 1 || len(shares) < threshold {
+		return nil, nil, WithKind(ErrInsufficientShares, fmt.Errorf("⚠ not enough shares to reconstruct the ECDSA key (need %d, have %d)", threshold, len(shares)))
+	}
+	sk, err := toVSSShares(shares, threshold, func(sd *ecdsa_keygen.LocalPartySaveData) (*big.Int, *big.Int) {
+		return sd.ShareID, sd.Xi
+	}).ReConstruct(curve)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// ensure the ECDSA PK matches our expected share 0 PK, on the same curve
+	pk := crypto.ScalarBaseMult(curve, sk)
+	share0PubKey := shares[0].ECDSAPub
+	if share0PubKey == nil || share0PubKey.Curve().Params().N.Cmp(curve.Params().N) != 0 || !pk.Equals(share0PubKey) {
+		secmem.WipeInt(sk)
+		return nil, nil, WithKind(ErrPubKeyMismatch, fmt.Errorf("⚠ recovered ECDSA public key did not match the expected share 0 public key! did you input the right threshold?"))
+	}
+	return sk, &ecdsa.PublicKey{Curve: curve, X: pk.X(), Y: pk.Y()}, nil
+}
+
+// reconstructEdDSAKey interpolates the Ed25519 private scalar of a vault from its EdDSA shares, with threshold the number
+// of shares the key needs, and checks it against the public key of share 0. On an error, no key is returned.
+func reconstructEdDSAKey(shares []*eddsa_keygen.LocalPartySaveData, threshold int) (*big.Int, error) {
+	if threshold < 1 || len(shares) < threshold {
+		return nil, WithKind(ErrInsufficientShares, fmt.Errorf("⚠ not enough shares to reconstruct the EdDSA key (need %d, have %d)", threshold, len(shares)))
+	}
+	sk, err := toVSSShares(shares, threshold, func(sd *eddsa_keygen.LocalPartySaveData) (*big.Int, *big.Int) {
+		return sd.ShareID, sd.Xi
+	}).ReConstruct(tss.Edwards())
+	if err != nil {
+		return nil, err
+	}
+
+	// ensure the EDDSA PK matches our expected share 0 PK
+	skBytes := leftPadTo32Bytes(sk)
+	defer clear(skBytes)
+	_, edPK, err := edwards.PrivKeyFromScalar(skBytes)
+	if err != nil {
+		secmem.WipeInt(sk)
+		return nil, err
+	}
+	edPKPt, err := crypto.NewECPoint(tss.Edwards(), edPK.X, edPK.Y)
+	if err != nil {
+		secmem.WipeInt(sk)
+		return nil, err
+	}
+	if !edPKPt.Equals(shares[0].EDDSAPub) {
+		secmem.WipeInt(sk)
+		return nil, WithKind(ErrPubKeyMismatch, fmt.Errorf("⚠ recovered EdDSA public key did not match the expected share 0 public key! did you input the right threshold?"))
+	}
+	return sk, nil
+}
+
+// toVSSShares turns the shares of a vault into the VSS shares of a key that takes threshold of them.
+func toVSSShares[T any](shares []*T, threshold int, fields func(*T) (shareID, xi *big.Int)) vss.Shares {
+	vssShares := make(vss.Shares, len(shares))
+	for i, share := range shares {
+		shareID, xi := fields(share)
+		vssShares[i] = &vss.Share{Threshold: threshold - 1, ID: shareID, Share: xi}
+	}
+	return vssShares
+}
+
+// reconstructKeys reconstructs the private keys of a vault from its shares with ReconstructKey, the ECDSA key in the group
+// of the given curve, and returns them as 32 bytes each. The EdDSA key is only reconstructed when there are EdDSA shares.
+// On a mismatch, the keys are cleared and not returned.
+func reconstructKeys(curve elliptic.Curve, sharesECDSA []*ecdsa_keygen.LocalPartySaveData, sharesEDDSA []*eddsa_keygen.LocalPartySaveData, tPlus1 int) (
+	ecdsaSK, eddsaSK []byte, pk *ecdsa.PublicKey, welp error) {
+
+	ecdsaSKI, pk, welp := ReconstructKey(sharesECDSA, tPlus1, curve)
+	if welp != nil {
+		return nil, nil, nil, welp
+	}
+	ecdsaSK = leftPadTo32Bytes(ecdsaSKI)
+	secmem.Lock(ecdsaSK)
+	registerSecret(ecdsaSK)
+	secmem.WipeInt(ecdsaSKI)
+
+	if len(sharesEDDSA) > 0 {
+		eddsaSKI, err := reconstructEdDSAKey(sharesEDDSA, tPlus1)
+		if err != nil {
+			clear(ecdsaSK)
+			return nil, nil, nil, err
+		}
+		eddsaSK = leftPadTo32Bytes(eddsaSKI)
+		secmem.Lock(eddsaSK)
+		registerSecret(eddsaSK)
+		secmem.WipeInt(eddsaSKI)
+	}
+	return ecdsaSK, eddsaSK, pk, nil
+}
+
+// notEnoughSharesWarning explains that a vault has fewer shares than its quorum, with the files its shares came from
+// and how many more party files are probably needed.
+func notEnoughSharesWarning(vault VaultSummary) Warning {
+	files := "none of the files"
+	if len(vault.Files) > 0 {
+		files = "`" + strings.Join(vault.Files, "`, `") + "`"
+	}
+	msg := fmt.Sprintf("Vault `%s` (%s) has %d of the %d shares it needs, from %s. It can't be recovered from these files: "+
+		"add the backup files of %d more of its parties", vault.VaultID, vault.Name, vault.NumberOfShares, vault.Quorum, files, vault.Quorum-vault.NumberOfShares)
+	if missing, needed := vault.Parties-vault.NumberOfShares, vault.Quorum-vault.NumberOfShares; missing > needed {
+		msg += fmt.Sprintf(", any %d of the %d parties whose files were not given", needed, missing)
+	}
+	return Warning{Kind: WarnNotEnoughShares, VaultID: vault.VaultID, Message: msg + "."}
+}
+
+// DecryptVault decrypts one reshare generation of a vault with the AES key of its backup file, i.e. the BIP39 entropy of the
+// file's phrase, checks the SHA-512 hash of the plaintext and decodes it. No share is inflated and no key is reconstructed,
+// so the name, threshold and curves of a vault can be read without them, though its shares are still in the clear vault.
+// Its ECDSACurve is set from its curves.
+func DecryptVault(cv CipheredVault, aesKey []byte) (*ClearVault, error) {
+	clearVault, err := decryptVault(aesKey, "", "", cv, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, curve := range clearVault.Curves {
+		if kind, ecdsaCurve := curveAlgorithm(curve.Algorithm); kind == "ECDSA" {
+			clearVault.ECDSACurve = ecdsaCurve
+		}
+	}
+	return clearVault, nil
+}
+
+// decryptVault decrypts and decodes one reshare generation of a vault with the AES key of the given backup file.
+// The errors name the vault and the file, unless vID or file is empty.
+// With a verbose writer, the base64 variant the ciphertext was encoded with is reported to it.
+func decryptVault(aesKey32 []byte, vID, file string, cipheredVault CipheredVault, verbose io.Writer) (*ClearVault, error) {
+	vault, inFile := "the vault", ""
+	if vID != "" {
+		vault = "vault " + vID
+	}
+	if file != "" {
+		inFile = " in file `" + file + "`"
+	}
+	aesNonce, err := hex.DecodeString(cipheredVault.CipherParams.IV)
+	if err != nil {
+		return nil, WithKind(ErrBadFormat, errors2.Errorf("⚠ failed to decrypt %s: %s (on nonce decode)", vault, err))
+	}
+	aesCT, encoding, err := decodeCiphertext(cipheredVault.CipherTextB64)
+	if err != nil {
+		return nil, WithKind(ErrBadFormat, errors2.Errorf("⚠ failed to decrypt %s: %s (on ciphertext decode)", vault, err))
+	}
+	if verbose != nil {
+		fmt.Fprintf(verbose, "Vault %s: ciphertext is %s encoded.\n", vID, encoding)
+	}
+	if aesCT, err = withGCMTag(aesCT, cipheredVault.CipherParams.Tag); err != nil {
+		return nil, WithKind(ErrBadFormat, errors2.Errorf("⚠ failed to decrypt %s: %s (on tag decode)", vault, err))
+	}
+
+	// a shorter phrase gives a shorter key, which only decrypts a vault encrypted with the matching AES key size
+	if size, ok := cipherKeySizes[strings.ToLower(cipheredVault.Cipher)]; ok && size != len(aesKey32) {
+		return nil, WithKind(ErrWrongMnemonic, errors2.Errorf("⚠ failed to decrypt %s%s: it is encrypted with %s, which takes a %d word phrase, but the phrase has %d words",
+			vault, inFile, cipheredVault.Cipher, phraseWordsForKey(size), phraseWordsForKey(len(aesKey32))))
+	}
+
+	// init AES-GCM cipher
+	aesBlk, err := aes.NewCipher(aesKey32)
+	if err != nil {
+		return nil, errors2.Errorf("⚠ failed to decrypt %s: %s (on cipher init 1)", vault, err)
+	}
+	aesGCM, err := cipher.NewGCM(aesBlk)
+	if err != nil {
+		return nil, errors2.Errorf("⚠ failed to decrypt %s: %s (on cipher init 2)", vault, err)
+	}
+
+	plainload, err := aesGCM.Open(nil, aesNonce, aesCT, nil)
+	if err != nil {
+		// the phrase passed the BIP39 checksum, so it is most likely the wrong phrase rather than a typo
+		return nil, WithKind(ErrWrongMnemonic, errors2.Errorf("⚠ failed to decrypt %s%s: %s (on decrypt)\nThe phrase for this file is likely wrong. %s",
+			vault, inFile, err, seedPhraseHint))
+	}
+	secmem.Lock(plainload)
+	defer clear(plainload)
+	expHash := sha512.Sum512(plainload)
+	if gotHash := hex.EncodeToString(expHash[:]); gotHash != cipheredVault.Hash {
+		return nil, WithKind(ErrBadFormat, errors2.Errorf("⚠ failed to decrypt %s%s: integrity check failed (hash mismatch: computed %s…, expected %s…). "+
+			"The backup file may be corrupted, or the phrase may not be the one for this file", vault, inFile, hashPrefix(gotHash), hashPrefix(cipheredVault.Hash)))
+	}
+
+	// decode vault from json
+	clearVault := new(ClearVault)
+	if err = json.Unmarshal(plainload, clearVault); err != nil {
+		// the phrase and the hash were right, so the file is intact, but its vault data is not what this tool expects
+		return nil, WithKind(ErrBadFormat, fmt.Errorf("⚠ %s%s was decrypted and passed its integrity check, but its data is not in the expected format (%s). "+
+			"The backup may have been made by a version of the app that this tool does not support (code: 3)", vault, inFile, jsonDecodeError(err)))
+	}
+	return clearVault, nil
+}
+
+// vaultJob is a vault to decrypt and inflate the shares of, at the reshare nonce picked for it.
+type vaultJob struct {
+	aesKey32      []byte
+	vaultID       string
+	file          string
+	nonce         int
+	cipheredVault CipheredVault
+}
+
+// vaultJobResult is the decrypted vault of a vaultJob and its decoded shares. The EdDSA shares are nil for a legacy vault.
+// skippedShares describes the corrupt V2 shares that were left out.
+type vaultJobResult struct {
+	vaultID       string
+	vault         *ClearVault
+	sharesECDSA   []*ecdsa_keygen.LocalPartySaveData
+	sharesEDDSA   []*eddsa_keygen.LocalPartySaveData
+	skippedShares []string
+	err           error
+}
+
+// decryptVaults runs the jobs on up to GOMAXPROCS workers, as the vaults do not depend on each other, and returns
+// the results in the order of the jobs. Once a job fails, the jobs that have not started yet are skipped.
+func decryptVaults(ctx context.Context, jobs []vaultJob, bounds inflateBounds, cache *shareCache, progress *progressWriter, verbose io.Writer) []vaultJobResult {
+	results := make([]vaultJobResult, len(jobs))
+	next := make(chan int)
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if failed.Load() {
+					results[i].vaultID = jobs[i].vaultID
+					continue
+				}
+				if results[i] = decryptVaultJob(ctx, jobs[i], bounds, cache, progress, verbose); results[i].err != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	// the jobs are handed out in order, so a skipped job always comes after the one that failed
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// decryptVaultJob decrypts a vault and decodes its shares, inflating V2 shares, or takes them from cache if it has them.
+// The shares are counted on progress, if set.
+func decryptVaultJob(ctx context.Context, job vaultJob, bounds inflateBounds, cache *shareCache, progress *progressWriter, verbose io.Writer) (result vaultJobResult) {
+	vID := job.vaultID
+	result.vaultID = vID
+	// a share that passes the integrity check but is malformed may panic in its decoder, which would otherwise crash the tool
+	// from a worker; it is reported as an error of the vault instead, with any key material in the panic redacted
+	defer func() {
+		if r := recover(); r != nil {
+			result.err = WithKind(ErrBadFormat, fmt.Errorf("⚠ vault %s in file `%s` could not be decoded: %s. The share data may be corrupt", vID, job.file, Redact(fmt.Sprint(r))))
+		}
+	}()
+	if result.err = cancelled(ctx); result.err != nil {
+		return
+	}
+
+	// DECRYPT
+	if result.vault, result.err = decryptVault(job.aesKey32, vID, job.file, job.cipheredVault, verbose); result.err != nil {
+		return
+	}
+	result.vault.LastReShareNonce = job.nonce
+
+	// rack up the shares
+	sharesECDSA, sharesEDDSA := result.vault.SharesLegacy, ([]string)(nil)
+	if sharesECDSA == nil {
+		for _, curve := range result.vault.Curves {
+			switch kind, ecdsaCurve := curveAlgorithm(curve.Algorithm); kind {
+			case "ECDSA":
+				sharesECDSA, result.vault.ECDSACurve = curve.Shares, ecdsaCurve
+			case "EDDSA":
+				sharesEDDSA = curve.Shares
+			}
+		}
+	}
+
+	// Build up shares lists
+	// - Ensure that ECDSA shares were found.
+	// - EdDSA shares may not be set for a legacy vault, so we won't catch that as a blocking issue
+	if sharesECDSA == nil {
+		result.err = WithKind(ErrBadFormat, fmt.Errorf("no legacy or new shares found for vault %s %s", vID, result.vault.Name))
+		return
+	}
+	progress.addShares(len(sharesECDSA) + len(sharesEDDSA))
+	var skipped []string
+	if result.sharesECDSA, skipped, result.err = inflateSharesForCurve[ecdsa_keygen.LocalPartySaveData](ctx, sharesECDSA, bounds, cache, progress); result.err != nil {
+		return
+	}
+	for _, msg := range skipped {
+		result.skippedShares = append(result.skippedShares, "ECDSA "+msg)
+	}
+	if sharesEDDSA != nil {
+		result.sharesEDDSA, skipped, result.err = inflateSharesForCurve[eddsa_keygen.LocalPartySaveData](ctx, sharesEDDSA, bounds, cache, progress)
+		for _, msg := range skipped {
+			result.skippedShares = append(result.skippedShares, "EdDSA "+msg)
+		}
+	}
+	return
+}
+
+// hashPrefix shortens a hex hash for error messages.
+func hashPrefix(hash string) string {
+	return hash[:min(len(hash), 16)]
+}
+
+// ciphertextEncodings are tried in order when decoding a vault's ciphertext.
+// Standard base64 is what the backups use; the others cover variants that encode it slightly differently.
+var ciphertextEncodings = []struct {
+	name string
+	enc  *base64.Encoding
+}{
+	{"base64", base64.StdEncoding},
+	{"base64url", base64.URLEncoding},
+	{"unpadded base64", base64.RawStdEncoding},
+	{"unpadded base64url", base64.RawURLEncoding},
+}
+
+// decodeCiphertext decodes the ciphertext with the first base64 variant that accepts it and returns that variant's name.
+// The error of the standard encoding is returned if none of them do.
+func decodeCiphertext(ctB64 string) ([]byte, string, error) {
+	var firstErr error
+	for _, e := range ciphertextEncodings {
+		ct, err := e.enc.DecodeString(ctB64)
+		if err == nil {
+			return ct, e.name, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, "", firstErr
+}
+
+// withGCMTag returns the ciphertext with the GCM tag appended, which is what golang's GCM implementation expects.
+// Some backup variants already store the tag at the end of the ciphertext and leave the tag field empty;
+// in that case the last 16 bytes of the ciphertext are used as the tag.
+func withGCMTag(aesCT []byte, tagHex string) ([]byte, error) {
+	if tagHex == "" {
+		if len(aesCT) < gcmTagSize {
+			return nil, fmt.Errorf("ciphertext is too short (%d bytes) to contain a tag", len(aesCT))
+		}
+		return aesCT, nil
+	}
+	aesTag, err := hex.DecodeString(tagHex)
+	if err != nil {
+		return nil, err
+	}
+	if len(aesTag) != gcmTagSize {
+		return nil, fmt.Errorf("tag is %d bytes, expected %d", len(aesTag), gcmTagSize)
+	}
+	return append(aesCT, aesTag...), nil
+}
+
+// inflateBounds are the accepted sizes in bytes of an inflated V2 share.
+type inflateBounds struct {
+	min, max int
+}
+
+func kbToBytes(kb float64) int {
+	return int(kb * 1024)
+}
+
+// inflateSharesForCurve decodes the shares of one curve, inflating V2 shares first. Their sizes are reported to progress, if set,
+// and each decoded share is counted on its status line. A V2 share that is corrupt, e.g. as its deflate data is cut short,
+// is skipped, so that the shares of the other parties can still reach the threshold; skipped describes each of them.
+// A share that cache holds is not decoded again, and each share decoded is added to it; the caller owns the shares returned either way.
+// On an error, including the cancellation of ctx, the secrets of the shares decoded so far are wiped.
+func inflateSharesForCurve[T SaveData](ctx context.Context, shares []string, bounds inflateBounds, cache *shareCache, progress *progressWriter) (_ []*T, skipped []string, welp error) {
+	shareDatas := make([]*T, 0, len(shares))
+	defer func() {
+		if welp != nil {
+			for _, shareData := range shareDatas {
+				wipeShareSecret(shareData)
+			}
+		}
+	}()
+	for j, strShare := range shares {
+		if err := cancelled(ctx); err != nil {
+			return nil, nil, err
+		}
+		key := newShareKey(strShare)
+		if shareData, ok := cachedShare[T](cache, key); ok {
+			shareDatas = append(shareDatas, shareData)
+			progress.shareDone()
+			continue
+		}
+		shareJSON := []byte(strShare)
+		// handle compressed "V2" format (ECDSA)
+		hadPrefix := strings.HasPrefix(strShare, v2MagicPrefix)
+		if hadPrefix {
+			inflated, err := inflateV2Share(strings.TrimPrefix(strShare, v2MagicPrefix), j, bounds, progress)
+			if err != nil {
+				skipped = append(skipped, err.Error())
+				progress.shareDone()
+				continue
+			}
+			clear(shareJSON)
+			shareJSON = inflated
+		}
+		// proceed with regular json unmarshal
+		shareData := new(T)
+		err := json.Unmarshal(shareJSON, shareData)
+		clear(shareJSON)
+		if err != nil {
+			if hadPrefix {
+				skipped = append(skipped, fmt.Sprintf("V2 share %d of the vault is not in the expected share format (%s). The share may be corrupt (code: 5)", j+1, jsonDecodeError(err)))
+				progress.shareDone()
+				continue
+			}
+			return nil, nil, WithKind(ErrBadFormat, fmt.Errorf("share %d of the vault is not in the expected share format (%s). "+
+				"The backup may have been made by a version of the app that this tool does not support (code: 6)", j+1, jsonDecodeError(err)))
+		}
+		lockShareSecrets(shareData)
+		registerShareSecret(shareData)
+		cacheShare(cache, key, shareData)
+		shareDatas = append(shareDatas, shareData)
+		progress.shareDone()
+	}
+	return shareDatas, skipped, nil
+}
+
+// inflateV2Share inflates a V2 share, i.e. its share ID and its deflated share data in base64, without the V2 prefix,
+// and checks that the inflated share data has that share ID. Each error names the share, by its ID once it is known,
+// or else by its position j in the vault.
+func inflateV2Share(strShare string, j int, bounds inflateBounds, progress *progressWriter) ([]byte, error) {
+	expShareID, b64Part, found := strings.Cut(strShare, "_")
+	if !found {
+		return nil, fmt.Errorf("V2 share %d of the vault has no share ID delimiter, so it is not V2 share data", j+1)
+	}
+	deflated, err := base64.StdEncoding.DecodeString(b64Part)
+	if err != nil {
+		return nil, fmt.Errorf("V2 share %s: its base64 data does not decode (%v)", expShareID, err)
+	}
+	inflated, err := data.InflateSaveDataJSON(deflated, bounds.min, bounds.max)
+	clear(deflated)
+	switch {
+	case errors.Is(err, data.ErrInflatedTooLarge):
+		return nil, fmt.Errorf("V2 share %s: %s (limit %.1f KB, see -max-kb)", expShareID, err, float64(bounds.max)/1024)
+	case errors.Is(err, data.ErrInflatedTooSmall):
+		return nil, fmt.Errorf("V2 share %s: %s (floor %.1f KB, see -min-kb)", expShareID, err, float64(bounds.min)/1024)
+	case err != nil:
+		clear(inflated)
+		return nil, fmt.Errorf("V2 share %s: its deflate data is corrupt or cut short (%v)", expShareID, err)
+	}
+	// shareID integrity check
+	abridgedData := new(struct {
+		ShareID *big.Int `json:"shareID"`
+	})
+	if err = json.Unmarshal(inflated, abridgedData); err != nil {
+		clear(inflated)
+		return nil, fmt.Errorf("V2 share %s was inflated, but the result is not share data (%s). The share may be corrupt (code: 4)", expShareID, jsonDecodeError(err))
+	}
+	if abridgedData.ShareID.String() != expShareID {
+		clear(inflated)
+		return nil, fmt.Errorf("V2 share %s: share ID mismatch, its share data has the ShareID %s", expShareID, abridgedData.ShareID)
+	}
+
+	// log deflated vs inflated sizes in KB
+	if progress != nil {
+		fmt.Fprintf(progress, "Processing V2 share %s.\t %.1f KB → %.1f KB\n",
+			abridgedData.ShareID, float64(len(deflated))/1024, float64(len(inflated))/1024)
+	}
+	return inflated, nil
+}
+
+// dedupeShares drops the shares whose share ID was already seen, keeping the first occurrence, and returns how many were dropped.
+// The secrets of the dropped shares are wiped, as they are no longer reachable by wipeShareSecrets.
+func dedupeShares[T any](shares []*T, fields func(*T) (shareID, xi *big.Int)) ([]*T, int) {
+	seen := make(map[string]struct{}, len(shares))
+	kept := shares[:0]
+	for _, share := range shares {
+		shareID, xi := fields(share)
+		if _, ok := seen[shareID.String()]; ok {
+			secmem.WipeInt(xi)
+			continue
+		}
+		seen[shareID.String()] = struct{}{}
+		kept = append(kept, share)
+	}
+	return kept, len(shares) - len(kept)
+}
+
+// dropKeylessShares drops the shares that carry no valid public key, and returns their share IDs.
+// The secrets of the dropped shares are wiped, as they are no longer reachable by wipeShareSecrets.
+func dropKeylessShares[T any](shares []*T, fields func(*T) (shareID, xi *big.Int, pub *crypto.ECPoint)) ([]*T, []string) {
+	kept := shares[:0]
+	var dropped []string
+	for _, share := range shares {
+		shareID, xi, pub := fields(share)
+		if !pub.ValidateBasic() {
+			secmem.WipeInt(xi)
+			dropped = append(dropped, shareID.String())
+			continue
+		}
+		kept = append(kept, share)
+	}
+	return kept, dropped
+}
+
+// sharesMismatchError checks that every share carries the vault's public key, which a reshare keeps, so that a share that
+// disagrees with the first share of its kind is not of this vault. The EdDSA shares are checked against the first EdDSA share,
+// as the ECDSA shares may all have been skipped as corrupt.
+func sharesMismatchError(vaultID string, sharesECDSA []*ecdsa_keygen.LocalPartySaveData, sharesEDDSA []*eddsa_keygen.LocalPartySaveData) error {
+	mismatch := func(keyType string, outliers []string, share0ID *big.Int) error {
+		return WithKind(ErrPubKeyMismatch, fmt.Errorf("⚠ the %s public key of share(s) %s does not match the public key of share %s of vault %s. "+
+			"These shares likely come from a different vault or reshare, or are corrupt; remove the files they came from and try again",
+			keyType, strings.Join(outliers, ", "), share0ID, vaultID))
+	}
+	// an outlier is only found among two or more shares, so the first share is there to name
+	if outliers := sharesNotMatching(sharesECDSA, func(sd *ecdsa_keygen.LocalPartySaveData) (*big.Int, *crypto.ECPoint) {
+		return sd.ShareID, sd.ECDSAPub
+	}); len(outliers) > 0 {
+		return mismatch("ECDSA", outliers, sharesECDSA[0].ShareID)
+	}
+	if outliers := sharesNotMatching(sharesEDDSA, func(sd *eddsa_keygen.LocalPartySaveData) (*big.Int, *crypto.ECPoint) {
+		return sd.ShareID, sd.EDDSAPub
+	}); len(outliers) > 0 {
+		return mismatch("EdDSA", outliers, sharesEDDSA[0].ShareID)
+	}
+	return nil
+}
+
+// sharesNotMatching returns the IDs of the shares whose public key differs from the public key of the first share.
+func sharesNotMatching[T any](shares []*T, fields func(*T) (shareID *big.Int, pub *crypto.ECPoint)) []string {
+	outliers := make([]string, 0)
+	if len(shares) == 0 {
+		return outliers
+	}
+	_, share0Pub := fields(shares[0])
+	for _, share := range shares[1:] {
+		if shareID, pub := fields(share); pub == nil || !pub.Equals(share0Pub) {
+			outliers = append(outliers, shareID.String())
+		}
+	}
+	return outliers
+}
+
+// wipeShareSecrets overwrites the Xi value of every share.
+func wipeShareSecrets(sharesECDSA VaultAllSharesECDSA, sharesEDDSA VaultAllSharesEdDSA) {
+	for _, shares := range sharesECDSA {
+		for _, share := range shares {
+			secmem.WipeInt(share.Xi)
+		}
+	}
+	for _, shares := range sharesEDDSA {
+		for _, share := range shares {
+			secmem.WipeInt(share.Xi)
+		}
+	}
+}
+
+// wipeShareSecret overwrites the share's Xi value with zeros. A nil share is skipped.
+func wipeShareSecret(shareData any) {
+	switch sd := shareData.(type) {
+	case *ecdsa_keygen.LocalPartySaveData:
+		if sd != nil {
+			secmem.WipeInt(sd.Xi)
+		}
+	case *eddsa_keygen.LocalPartySaveData:
+		if sd != nil {
+			secmem.WipeInt(sd.Xi)
+		}
+	}
+}
+
+// cancelled returns an error once ctx is done, so that a recovery stops between its steps.
+func cancelled(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("⚠ recovery cancelled: %w", err)
+	}
+	return nil
+}
+
+// lockShareSecrets locks the share's Xi value into RAM when -mlock is enabled.
+func lockShareSecrets(shareData any) {
+	switch sd := shareData.(type) {
+	case *ecdsa_keygen.LocalPartySaveData:
+		secmem.LockInt(sd.Xi)
+	case *eddsa_keygen.LocalPartySaveData:
+		secmem.LockInt(sd.Xi)
+	}
+}
+
+// registerShareSecret adds the share's Xi value to the secrets that Redact removes from the output.
+func registerShareSecret(shareData any) {
+	switch sd := shareData.(type) {
+	case *ecdsa_keygen.LocalPartySaveData:
+		registerSecretInt(sd.Xi)
+	case *eddsa_keygen.LocalPartySaveData:
+		registerSecretInt(sd.Xi)
+	}
+}
+
+func getTSSPubKeyForEthereum(x, y *big.Int) (*secp256k1.PublicKey, string, error) {
+	if x == nil || y == nil || x.BitLen() > 256 || y.BitLen() > 256 {
+		return nil, "", errors.New("invalid public key coordinates")
+	}
+	// a coordinate with leading zero bytes is padded, as the uncompressed key has two 32-byte coordinates
+	var uncompressed [65]byte
+	uncompressed[0] = 0x04
+	x.FillBytes(uncompressed[1:33])
+	y.FillBytes(uncompressed[33:])
+	pubKey, err := secp256k1.ParsePubKey(uncompressed[:])
+	if err != nil {
+		return nil, "", err
+	}
+	var pubKeyBz [65]byte
+	copy(pubKeyBz[:], pubKey.SerializeUncompressed())
+
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(pubKeyBz[1:])
+	sum := hash.Sum(nil)
+	addr := fmt.Sprintf("0x%s", hex.EncodeToString(sum[len(sum)-20:]))
+
+	// render the address in "checksum" format (mix of uppercase and lowercase chars)
+	addr = common.HexToAddress(addr).Hex()
+	return pubKey, addr, nil
+}
+
+// leftPadTo32Bytes pads the byte representation of a big.Int to 32 bytes with leading zeros.
+func leftPadTo32Bytes(i *big.Int) []byte {
+	padded := make([]byte, 32)
+	if i == nil {
+		return padded
+	}
+	bytes := i.Bytes()
+	if len(bytes) >= 32 {
+		return bytes
+	}
+	copy(padded[32-len(bytes):], bytes)
+	return padded
+}
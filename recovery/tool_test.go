@@ -0,0 +1,1258 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package recovery
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/binance-chain/tss-lib/crypto"
+	ecdsa_keygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+	eddsa_keygen "github.com/binance-chain/tss-lib/eddsa/keygen"
+	"github.com/binance-chain/tss-lib/tss"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// Test fixture mnemonics. Used only for this purpose.
+const (
+	mmI  = "season pole chronic surround fiber stumble remove artwork muffin apart limit vacuum horror above donkey olympic earn dizzy addict gym animal leopard before unfair"
+	mmL  = "casual gallery jump mad claw curve portion enrich oyster calm spoon flash hat soft dizzy example exile large provide smart magnet raven nurse prison"
+	mmM  = "decade explain repeat popular pigeon sail atom enhance toy awake breeze draw focus desert movie skull news inherit cruel case start film used unit"
+	mmV2 = "ridge scare utility perfect trial van inflict feel top dice present monitor always order charge door curious lobster quick guide obvious danger crisp cinnamon"
+
+	// James test case mnemonics
+	mmNewBvn = "domain damp hill depth label eye erode dutch impulse betray floor donate bonus hover bitter ring unfold poet identify capital combine question profit april"
+	mmNewX2q = "found midnight praise exhibit weather neutral inmate strong grass famous blind pet frozen shock avocado ring fringe planet opera license stand coil beauty capable"
+	mmNewU44 = "aerobic foam smooth immune card tragic window myth planet notice piece agree add target tortoise weather kite track spot dish dignity twice gadget spell"
+
+	// Single Signer test case mnemonics
+	mmNewSingle = "jacket zone rotate merry forward paper cruel forget train prevent teach bitter lumber razor uncle stairs finger chief curtain render tray tower odor garbage"
+)
+
+func TestTool_New_V2_List(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn},
+		{File: "../test-files/new_x2q.json", Mnemonics: mmNewX2q},
+		{File: "../test-files/new_u44.json", Mnemonics: mmNewU44},
+	}
+
+	// use the correct file path for tests
+	address, ecSK, edSK, vaultFormData, warnings, err := runTool(context.Background(), files, nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, vaultFormData, 14) {
+		return
+	}
+	// the files disagree on the last reshare nonce of one vault, and three vaults have a share in only one file
+	if !assert.Len(t, warnings, 4) {
+		return
+	}
+	if !assert.Equal(t, WarnNonceMismatch, warnings[0].Kind) || !assert.Equal(t, "e0wspn90rz8vnngv0kdklaog", warnings[0].VaultID) {
+		return
+	}
+	for i, vID := range []string{"bfc8uksrk5zuxihufj4m8dkt", "ejrye15wiew2201f3fahho8k", "nbpxb6hmupk1ygcl53jf9zg5"} {
+		if !assert.Equal(t, WarnNotEnoughShares, warnings[i+1].Kind) || !assert.Equal(t, vID, warnings[i+1].VaultID) {
+			return
+		}
+	}
+	assert.Contains(t, warnings[3].Message, "has 1 of the 3 shares it needs, from `../test-files/new_bvn.json`")
+	assert.Contains(t, warnings[3].Message, "add the backup files of 2 more of its parties")
+	assert.Contains(t, warnings[0].Message, "reshare nonces 0 and 1 of that vault")
+	assert.Contains(t, warnings[0].Message, "try: -vault-id e0wspn90rz8vnngv0kdklaog -nonce 0 ")
+	assert.Equal(t, []int{0, 1}, vaultFormData[5].ReShareNonces)
+	assert.Equal(t, []string{"../test-files/new_bvn.json"}, vaultFormData[9].Files)
+	assert.Equal(t, []string{"../test-files/new_bvn.json", "../test-files/new_x2q.json", "../test-files/new_u44.json"}, vaultFormData[12].Files)
+
+	vaultIDs := vaultIdsFromFormData(vaultFormData)
+	if !assert.Equal(t,
+		[]string{
+			"a70uaean4isi6aci8zzky970",
+			"afpuzaa5j3k7wyjfgkuvbcxz",
+			"bfc8uksrk5zuxihufj4m8dkt",
+			"d1rqfhghbr1qy819iym5dgyv",
+			"dfqyrx0f7vevbjx9o5yrg7gw",
+			"e0wspn90rz8vnngv0kdklaog",
+			"ejrye15wiew2201f3fahho8k",
+			"iesd46upmcrwnu0qojph9hst",
+			"liw3bn8yqykgh96uort11knz",
+			"nbpxb6hmupk1ygcl53jf9zg5",
+			"ngo46g83iug985q3fxyhsp4w",
+			"prd15bna3h9oxoo04dc4cn1p",
+			"yz5x2a7zhwwt7r0lv4gklqns",
+			"zbgtamgot1f6u51kt6bsn5qr",
+		}, vaultIDs) {
+		return
+	}
+	if !assert.Empty(t, address) {
+		return
+	}
+	if !assert.Nil(t, ecSK) || !assert.Nil(t, edSK) {
+		return
+	}
+}
+
+func TestTool_New_V2_Export_lqns(t *testing.T) {
+	// use the correct file path for tests
+	vaultID := "yz5x2a7zhwwt7r0lv4gklqns"
+
+	files := []VaultsDataFile{
+		{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn},
+		{File: "../test-files/new_x2q.json", Mnemonics: mmNewX2q},
+		{File: "../test-files/new_u44.json", Mnemonics: mmNewU44},
+	}
+
+	address, ecSK, edSK, vaultsFormData, warnings, err := runTool(context.Background(), files, &vaultID, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Empty(t, warnings) {
+		return
+	}
+	if !assert.Len(t, vaultsFormData, 1) {
+		return
+	}
+	if !assert.Equal(t, vaultID, vaultsFormData[0].VaultID) {
+		return
+	}
+	if !assert.Equal(t, "0x620Ac72121234f1b313BD4e8b78C81323502679A", address) {
+		return
+	}
+	if !assert.Equal(t, "4cc05b1d3216da8ef91729744159019b25ea1ed5932e387199f1de6ff6667ac2",
+		hex.EncodeToString(ecSK)) {
+		return
+	}
+	if !assert.Equal(t, "0e6f0e12d72483d32255000d01242fa4e179b9bbfa060de26cfb9c84e1d02d9e",
+		hex.EncodeToString(edSK)) {
+		return
+	}
+}
+
+func TestTool_NewSingle_V2_List(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_single.json", Mnemonics: mmNewSingle},
+	}
+	// use the correct file path for tests
+	address, _, edSK, vaultFormData, _, err := runTool(context.Background(), files, nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, vaultFormData, 1) {
+		return
+	}
+	vaultIDs := vaultIdsFromFormData(vaultFormData)
+	if !assert.Contains(t, vaultIDs, "phrot42ltzawmn7nrm7mqvl5", "vaults must contain expected vaultId qvl5") {
+		return
+	}
+	if !assert.Empty(t, address) {
+		return
+	}
+	if !assert.Nil(t, edSK) {
+		return
+	}
+}
+
+func TestTool_NewSingle_V2_List_BadMnemonic(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_single.json", Mnemonics: mmV2},
+	}
+	// use the correct file path for tests
+	_, _, _, _, _, err := runTool(context.Background(), files, nil, nil)
+	if !assert.Error(t, err) {
+		return
+	}
+	if !assert.Contains(t, err.Error(), "not a wallet seed phrase") {
+		return
+	}
+}
+
+func TestTool_NewSingle_V2_Export_qvl5(t *testing.T) {
+	// use the correct file path for tests
+	vaultID := "phrot42ltzawmn7nrm7mqvl5"
+
+	files := []VaultsDataFile{
+		{File: "../test-files/new_single.json", Mnemonics: mmNewSingle},
+	}
+	_, ecSK, edSK, vaultsFormData, _, err := runTool(context.Background(), files, &vaultID, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, vaultsFormData, 1) {
+		return
+	}
+	if !assert.Equal(t, vaultID, vaultsFormData[0].VaultID) {
+		return
+	}
+	if !assert.Equal(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7",
+		hex.EncodeToString(ecSK)) {
+		return
+	}
+	if !assert.Equal(t, "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44",
+		hex.EncodeToString(edSK)) {
+		return
+	}
+}
+
+func TestTool_NewSingle_V2_Export_qvl5_Gzip(t *testing.T) {
+	// use the correct file path for tests
+	vaultID := "phrot42ltzawmn7nrm7mqvl5"
+
+	// the same backup file, gzipped
+	files := []VaultsDataFile{
+		{File: "../test-files/new_single.json.gz", Mnemonics: mmNewSingle},
+	}
+	_, ecSK, edSK, _, _, err := runTool(context.Background(), files, &vaultID, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Equal(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7",
+		hex.EncodeToString(ecSK)) {
+		return
+	}
+	if !assert.Equal(t, "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44",
+		hex.EncodeToString(edSK)) {
+		return
+	}
+}
+
+func TestTool_New_V2_WrongPhrase(t *testing.T) {
+	// the phrases of two files are swapped, so the second file is the first to fail
+	files := []VaultsDataFile{
+		{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn},
+		{File: "../test-files/new_x2q.json", Mnemonics: mmNewU44},
+		{File: "../test-files/new_u44.json", Mnemonics: mmNewX2q},
+	}
+	_, _, _, _, _, err := runTool(context.Background(), files, nil, nil)
+	if !assert.Error(t, err) {
+		return
+	}
+	assert.Contains(t, err.Error(), "in file `../test-files/new_x2q.json`")
+	assert.Contains(t, err.Error(), "The phrase for this file is likely wrong")
+}
+
+func TestTool_NewSingle_V2_Export_qvl5_Zip(t *testing.T) {
+	// use the correct file path for tests
+	vaultID := "phrot42ltzawmn7nrm7mqvl5"
+
+	// the same backup file, gzipped inside a zip archive
+	content, err := os.ReadFile("../test-files/new_single.json.gz")
+	if !assert.NoError(t, err) {
+		return
+	}
+	archive := filepath.Join(t.TempDir(), "backups.zip")
+	f, err := os.Create(archive)
+	if !assert.NoError(t, err) {
+		return
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("party1/new_single.json.gz")
+	if !assert.NoError(t, err) {
+		return
+	}
+	if _, err = w.Write(content); !assert.NoError(t, err) || !assert.NoError(t, zw.Close()) || !assert.NoError(t, f.Close()) {
+		return
+	}
+
+	files := []VaultsDataFile{
+		{File: archive + "!/party1/new_single.json.gz", Mnemonics: mmNewSingle},
+	}
+	_, ecSK, _, _, _, err := runTool(context.Background(), files, &vaultID, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Equal(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7",
+		hex.EncodeToString(ecSK)) {
+		return
+	}
+}
+
+func TestTool_NewSingle_V2_Export_qvl5_BadMnemonic(t *testing.T) {
+	// use the correct file path for tests
+	vaultID := "phrot42ltzawmn7nrm7mqvl5"
+
+	files := []VaultsDataFile{
+		{File: "../test-files/new_single.json", Mnemonics: mmV2},
+	}
+	_, _, _, _, _, err := runTool(context.Background(), files, &vaultID, nil)
+	if !assert.Error(t, err) {
+		return
+	}
+}
+
+func TestTool_NewSingle_V2_Export_qvl5_AppendedTag(t *testing.T) {
+	// use the correct file path for tests
+	vaultID := "phrot42ltzawmn7nrm7mqvl5"
+
+	// move every tag to the end of its ciphertext, leaving the tag field empty
+	file := rewriteBackupFile(t, "../test-files/new_single.json", func(cv *CipheredVault) {
+		ct, err := base64.StdEncoding.DecodeString(cv.CipherTextB64)
+		if !assert.NoError(t, err) {
+			return
+		}
+		tag, err := hex.DecodeString(cv.CipherParams.Tag)
+		if !assert.NoError(t, err) {
+			return
+		}
+		cv.CipherTextB64 = base64.StdEncoding.EncodeToString(append(ct, tag...))
+		cv.CipherParams.Tag = ""
+	})
+	files := []VaultsDataFile{
+		{File: file, Mnemonics: mmNewSingle},
+	}
+	_, ecSK, edSK, _, _, err := runTool(context.Background(), files, &vaultID, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Equal(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7",
+		hex.EncodeToString(ecSK)) {
+		return
+	}
+	if !assert.Equal(t, "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44",
+		hex.EncodeToString(edSK)) {
+		return
+	}
+}
+
+func TestTool_NewSingle_V2_Export_qvl5_InflateBounds(t *testing.T) {
+	// use the correct file path for tests
+	vaultID := "phrot42ltzawmn7nrm7mqvl5"
+
+	files := []VaultsDataFile{
+		{File: "../test-files/new_single.json", Mnemonics: mmNewSingle},
+	}
+	// the ECDSA shares inflate to ~13.7 KB, so they are skipped, and the vault can't be recovered from the file
+	_, _, _, _, warnings, err := runTool(context.Background(), files, &vaultID, &Options{NonceOverride: -1, MinInflatedKB: 0, MaxInflatedKB: 8})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not enough shares")
+	}
+	if assert.NotEmpty(t, warnings) {
+		assert.Equal(t, WarnCorruptShares, warnings[0].Kind)
+		assert.Contains(t, warnings[0].Message, "-max-kb")
+	}
+	// the EdDSA shares inflate to ~0.7 KB, so only the ECDSA key is recovered
+	_, ecSK, edSK, _, warnings, err := runTool(context.Background(), files, &vaultID, &Options{NonceOverride: -1, MinInflatedKB: 1, MaxInflatedKB: 64})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, ecSK, 32)
+	assert.Nil(t, edSK)
+	if assert.Len(t, warnings, 2) {
+		assert.Contains(t, warnings[0].Message, "-min-kb")
+		assert.Contains(t, warnings[1].Message, "The EdDSA key of vault `phrot42ltzawmn7nrm7mqvl5` can't be recovered")
+	}
+	_, ecSK, _, _, _, err = runTool(context.Background(), files, &vaultID, &Options{NonceOverride: -1, MinInflatedKB: 0.5, MaxInflatedKB: 64})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", hex.EncodeToString(ecSK))
+}
+
+func TestTool_Legacy_V2_List(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/v2.json", Mnemonics: mmV2},
+	}
+
+	// use the correct file path for tests
+	address, ecSK, edSK, vaultsFormData, _, err := runTool(context.Background(), files, nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, vaultsFormData, 1) {
+		return
+	}
+	if !assert.Equal(t, "yjanjbgmbrptwwa9i5v9c20x", vaultsFormData[0].VaultID) {
+		return
+	}
+	if !assert.Empty(t, address) {
+		return
+	}
+	if !assert.Nil(t, ecSK) || !assert.Nil(t, edSK) {
+		return
+	}
+}
+
+func TestTool_Legacy_V2_Export_c20x(t *testing.T) {
+	// use the correct file path for tests
+	vaultID := "yjanjbgmbrptwwa9i5v9c20x"
+
+	files := []VaultsDataFile{
+		{File: "../test-files/v2.json", Mnemonics: mmV2},
+	}
+
+	address, ecSK, edSK, vaultsFormData, _, err := runTool(context.Background(), files, &vaultID, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, vaultsFormData, 1) {
+		return
+	}
+	if !assert.Equal(t, vaultID, vaultsFormData[0].VaultID) {
+		return
+	}
+	if !assert.Equal(t, "0x66e36b136fb8b2C98c72eEC8Ae02D531e526f454", address) {
+		return
+	}
+	if !assert.Equal(t, "9ca4dc783e108938e81b06d76d7b74ec4488e1acc9c569eedfaf4c949c3531d7",
+		hex.EncodeToString(ecSK)) {
+		return
+	}
+	// no EdDSA key for this vault
+	if !assert.Nil(t, edSK) {
+		return
+	}
+}
+
+func TestTool_Legacy_V1_IL_List(t *testing.T) {
+	// use the correct file path for tests
+	files := []VaultsDataFile{
+		{File: "../test-files/i.json", Mnemonics: mmI},
+		{File: "../test-files/l.json", Mnemonics: mmL},
+	}
+
+	address, ecSK, edSK, vaultsFormData, _, err := runTool(context.Background(), files, nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, vaultsFormData, 6) {
+		return
+	}
+	vaultIDs := vaultIdsFromFormData(vaultsFormData)
+	if !assert.Equal(t, []string{
+		"clujhtm9d0013wc3xso1b2m0k", "clujmawnb001j173x9a2c0x47", "clujn9hhr001u173xiv9gfme6", "clujnasrf001x173xjxtcwzeq", "clul2s3f70008yf3x7mada0gb", "clur52dfl0001vc3xlbdy1d7p",
+	}, vaultIDs) {
+		return
+	}
+	if !assert.Empty(t, address) {
+		return
+	}
+	if !assert.Nil(t, ecSK) || !assert.Nil(t, edSK) {
+		return
+	}
+}
+
+func TestTool_Legacy_V1_IL_Export_m0k(t *testing.T) {
+	// use the correct file path for tests
+	vaultID := "clujhtm9d0013wc3xso1b2m0k"
+
+	files := []VaultsDataFile{
+		{File: "../test-files/i.json", Mnemonics: mmI},
+		{File: "../test-files/l.json", Mnemonics: mmL},
+	}
+
+	address, ecSK, edSK, vaultFormData, _, err := runTool(context.Background(), files, &vaultID, nil)
+
+	if !assert.NoError(t, err) {
+		return
+	}
+	vaultIDs := vaultIdsFromFormData(vaultFormData)
+	if !assert.Len(t, vaultIDs, 1) {
+		return
+	}
+	if !assert.Equal(t, vaultID, vaultIDs[0]) {
+		return
+	}
+	if !assert.Equal(t, "0x66EE83F83002b01459B750233F7B21744E679182", address) {
+		return
+	}
+	if !assert.Equal(t, "7d3c016f339f8cc797ee35502a5c93416d47bdd04360d22ea4fcaf85cec229b3",
+		hex.EncodeToString(ecSK)) {
+		return
+	}
+	// no EdDSA key for this vault
+	if !assert.Nil(t, edSK) {
+		return
+	}
+}
+
+func TestTool_Legacy_V1_ILM_List(t *testing.T) {
+	// use the correct file path for tests
+	files := []VaultsDataFile{
+		{File: "../test-files/i.json", Mnemonics: mmI},
+		{File: "../test-files/m.json", Mnemonics: mmM},
+		{File: "../test-files/l.json", Mnemonics: mmL},
+	}
+
+	address, ecSK, edSK, vaultsFormData, _, err := runTool(context.Background(), files, nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, vaultsFormData, 6) {
+		return
+	}
+	vaultIDs := vaultIdsFromFormData(vaultsFormData)
+	if !assert.Equal(t, []string{
+		"clujhtm9d0013wc3xso1b2m0k", "clujmawnb001j173x9a2c0x47", "clujn9hhr001u173xiv9gfme6", "clujnasrf001x173xjxtcwzeq", "clul2s3f70008yf3x7mada0gb", "clur52dfl0001vc3xlbdy1d7p",
+	}, vaultIDs) {
+		return
+	}
+	if !assert.Empty(t, address) {
+		return
+	}
+	if !assert.Nil(t, ecSK) || !assert.Nil(t, edSK) {
+		return
+	}
+}
+
+func TestTool_Legacy_V1_ILM_Export_m0k(t *testing.T) {
+	// use the correct file path for tests
+	vaultID := "clujhtm9d0013wc3xso1b2m0k"
+
+	files := []VaultsDataFile{
+		{File: "../test-files/i.json", Mnemonics: mmI},
+		{File: "../test-files/m.json", Mnemonics: mmM},
+		{File: "../test-files/l.json", Mnemonics: mmL},
+	}
+
+	address, ecSK, edSK, vaultsFormData, _, err := runTool(context.Background(), files, &vaultID, nil)
+
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, vaultsFormData, 1) {
+		return
+	}
+	if !assert.Equal(t, vaultID, vaultsFormData[0].VaultID) {
+		return
+	}
+	if !assert.Equal(t, "0x66EE83F83002b01459B750233F7B21744E679182", address) {
+		return
+	}
+	if !assert.Equal(t, "7d3c016f339f8cc797ee35502a5c93416d47bdd04360d22ea4fcaf85cec229b3",
+		hex.EncodeToString(ecSK)) {
+		return
+	}
+	// no EdDSA key for this vault
+	if !assert.Nil(t, edSK) {
+		return
+	}
+}
+
+// rewriteBackupFile writes a copy of a backup file to a temp dir, with each ciphered vault passed through `edit`.
+func rewriteBackupFile(t *testing.T, src string, edit func(cv *CipheredVault)) string {
+	t.Helper()
+	content, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := make(map[string]json.RawMessage)
+	if err = json.Unmarshal(content, &raw); err != nil {
+		t.Fatal(err)
+	}
+	saveData := new(SavedData)
+	if err = json.Unmarshal(content, saveData); err != nil {
+		t.Fatal(err)
+	}
+	for _, resharesMap := range saveData.Vaults {
+		for nonce, cv := range resharesMap {
+			edit(&cv)
+			resharesMap[nonce] = cv
+		}
+	}
+	if raw["vaults"], err = json.Marshal(saveData.Vaults); err != nil {
+		t.Fatal(err)
+	}
+	if content, err = json.Marshal(raw); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(t.TempDir(), filepath.Base(src))
+	if err = os.WriteFile(dst, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dst
+}
+
+func vaultIdsFromFormData(vaultFormData []VaultSummary) []string {
+	vaultIDs := make([]string, len(vaultFormData))
+	for i, v := range vaultFormData {
+		vaultIDs[i] = v.VaultID
+	}
+	return vaultIDs
+}
+
+func TestLeftPadTo32Bytes(t *testing.T) {
+	bytes32Input, _ := hex.DecodeString("04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44")
+	bytes34Input, _ := hex.DecodeString("04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e440f0f")
+
+	tests := []struct {
+		name     string
+		input    []byte
+		expected string
+	}{
+		{"Nil Input", nil, "0000000000000000000000000000000000000000000000000000000000000000"},
+		{"Empty Input", []byte{}, "0000000000000000000000000000000000000000000000000000000000000000"},
+		{"Short Input", []byte{0xab, 0xcd}, "000000000000000000000000000000000000000000000000000000000000abcd"},
+		{"32 Bytes Input", bytes32Input, "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44"},
+		{"Long Input", bytes34Input, "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e440f0f"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := leftPadTo32Bytes(new(big.Int).SetBytes(tt.input))
+			if !assert.Equal(t, tt.expected, hex.EncodeToString(result)) {
+				return
+			}
+		})
+	}
+}
+
+func TestWithGCMTag(t *testing.T) {
+	ct := []byte("0123456789abcdef-ciphertext")
+	tag, _ := hex.DecodeString("92f88ff77e413bb0f47d8bef111f451a")
+
+	tests := []struct {
+		name     string
+		ct       []byte
+		tagHex   string
+		expected []byte
+		wantErr  bool
+	}{
+		{"Separate Tag", ct, hex.EncodeToString(tag), append(append([]byte{}, ct...), tag...), false},
+		{"Appended Tag", append(append([]byte{}, ct...), tag...), "", append(append([]byte{}, ct...), tag...), false},
+		{"Appended Tag Too Short", []byte{0x01, 0x02}, "", nil, true},
+		{"Bad Tag Hex", ct, "zz", nil, true},
+		{"Bad Tag Length", ct, "92f8", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := withGCMTag(tt.ct, tt.tagHex)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestDecodeCiphertext(t *testing.T) {
+	// encodes to characters that differ between the standard and URL-safe alphabets, with padding
+	ct := []byte{0xfb, 0xef, 0xbe, 0xff, 0xfe}
+
+	tests := []struct {
+		name     string
+		enc      *base64.Encoding
+		expected string
+	}{
+		{"Standard", base64.StdEncoding, "base64"},
+		{"URL", base64.URLEncoding, "base64url"},
+		{"Raw Standard", base64.RawStdEncoding, "unpadded base64"},
+		{"Raw URL", base64.RawURLEncoding, "unpadded base64url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, encoding, err := decodeCiphertext(tt.enc.EncodeToString(ct))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, ct, result)
+			assert.Equal(t, tt.expected, encoding)
+		})
+	}
+
+	_, _, err := decodeCiphertext("not*base64")
+	assert.Error(t, err)
+}
+
+func TestTool_NewSingle_V2_Export_qvl5_Base64URL(t *testing.T) {
+	// use the correct file path for tests
+	vaultID := "phrot42ltzawmn7nrm7mqvl5"
+
+	// re-encode every ciphertext as unpadded base64url
+	file := rewriteBackupFile(t, "../test-files/new_single.json", func(cv *CipheredVault) {
+		ct, err := base64.StdEncoding.DecodeString(cv.CipherTextB64)
+		if !assert.NoError(t, err) {
+			return
+		}
+		cv.CipherTextB64 = base64.RawURLEncoding.EncodeToString(ct)
+	})
+	files := []VaultsDataFile{
+		{File: file, Mnemonics: mmNewSingle},
+	}
+	_, ecSK, _, _, _, err := runTool(context.Background(), files, &vaultID, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", hex.EncodeToString(ecSK))
+}
+
+// A reconstructed scalar with leading zero bytes must be padded before it is used anywhere, or the WIF and address are wrong.
+func TestSmallScalar_Padded(t *testing.T) {
+	sk := leftPadTo32Bytes(big.NewInt(1))
+	if !assert.Len(t, sk, 32) {
+		return
+	}
+
+	scl := secp256k1.ModNScalar{}
+	scl.SetByteSlice(sk)
+	pk := secp256k1.NewPrivateKey(&scl).PubKey()
+	_, address, err := getTSSPubKeyForEthereum(pk.X(), pk.Y())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", address)
+
+	chains, err := DeriveChains(sk, nil, BTCAddressBech32, true)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, Bitcoin{Address: "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", LegacyAddress: "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r", WIF: "cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA"}, chains.BitcoinTestnet)
+	assert.Equal(t, Bitcoin{Address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", LegacyAddress: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", WIF: "KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn"}, chains.BitcoinMainnet)
+	assert.Equal(t, address, chains.Ethereum)
+
+	chains, err = DeriveChains(sk, nil, BTCAddressLegacy, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, Bitcoin{Address: "mtoKs9V381UAhUia3d7Vb9GNak8Qvmcsme", LegacyAddress: "mtoKs9V381UAhUia3d7Vb9GNak8Qvmcsme", WIF: "91avARGdfge8E4tZfYLoxeJ5sGBdNJQH4kvjJoQFacbgwmaKkrx"}, chains.BitcoinTestnet)
+	assert.Equal(t, Bitcoin{Address: "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm", LegacyAddress: "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm", WIF: "5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf"}, chains.BitcoinMainnet)
+}
+
+// A public key coordinate with a leading zero byte must be padded too, or the key does not parse.
+func TestShortPubKeyCoordinate_Padded(t *testing.T) {
+	tests := []struct {
+		name     string
+		sk       int64
+		expected string
+	}{
+		{"Private Key 122", 122, "0x872917cEC8992487651Ee633DBA73bd3A9dcA309"},
+		{"Private Key 130", 130, "0x00eDf2d16AfbC028FB1e879559b07997Af79539f"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sk := leftPadTo32Bytes(big.NewInt(tt.sk))
+			pk := secp256k1.PrivKeyFromBytes(sk).PubKey()
+			if !assert.Less(t, len(pk.Y().Bytes()), 32) {
+				return
+			}
+			_, address, err := getTSSPubKeyForEthereum(pk.X(), pk.Y())
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expected, address)
+
+			chains, err := DeriveChains(sk, nil, BTCAddressBech32, true)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, chains.Ethereum)
+			}
+		})
+	}
+}
+
+func TestTool_NewSingle_V2_Export_qvl5_HashMismatch(t *testing.T) {
+	// use the correct file path for tests
+	vaultID := "phrot42ltzawmn7nrm7mqvl5"
+
+	// flip the first hex digit of every integrity hash
+	file := rewriteBackupFile(t, "../test-files/new_single.json", func(cv *CipheredVault) {
+		flipped := "0"
+		if cv.Hash[0] == '0' {
+			flipped = "1"
+		}
+		cv.Hash = flipped + cv.Hash[1:]
+	})
+	files := []VaultsDataFile{
+		{File: file, Mnemonics: mmNewSingle},
+	}
+	_, _, _, _, _, err := runTool(context.Background(), files, &vaultID, nil)
+	if !assert.Error(t, err) {
+		return
+	}
+	assert.Contains(t, err.Error(), "integrity check failed (hash mismatch: computed ")
+	assert.Contains(t, err.Error(), "The backup file may be corrupted")
+	assert.NotContains(t, err.Error(), "%!")
+}
+
+func TestGetTSSPubKeyForEthereum_EIP55(t *testing.T) {
+	tests := []struct {
+		name     string
+		sk       int64
+		expected string
+	}{
+		{"Private Key 1", 1, "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},
+		{"Private Key 2", 2, "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"},
+		{"Private Key 3", 3, "0x6813Eb9362372EEF6200f3b1dbC3f819671cBA69"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sk := leftPadTo32Bytes(big.NewInt(tt.sk))
+			scl := secp256k1.ModNScalar{}
+			scl.SetByteSlice(sk)
+			pk := secp256k1.NewPrivateKey(&scl).PubKey()
+			_, address, err := getTSSPubKeyForEthereum(pk.X(), pk.Y())
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expected, address)
+
+			// the wallet v3 file is keyed on the same checksummed address
+			privKey, err := ethcrypto.ToECDSA(sk)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, address, ethcrypto.PubkeyToAddress(privKey.PublicKey).Hex())
+		})
+	}
+}
+
+// resealBackupFile copies a backup file with every vault decrypted with the key of mnemonic, its data passed through edit,
+// and encrypted again with the key of newMnemonic. edit may also change the vault's fields, e.g. its cipher.
+func resealBackupFile(t *testing.T, src, mnemonic, newMnemonic string, edit func(cv *CipheredVault, plain []byte) []byte) string {
+	t.Helper()
+	gcmFor := func(mnemonic string) cipher.AEAD {
+		aesKey, err := bip39.EntropyFromMnemonic(mnemonic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		aesBlk, err := aes.NewCipher(aesKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		aesGCM, err := cipher.NewGCM(aesBlk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return aesGCM
+	}
+	oldGCM, newGCM := gcmFor(mnemonic), gcmFor(newMnemonic)
+	return rewriteBackupFile(t, src, func(cv *CipheredVault) {
+		aesNonce, err := hex.DecodeString(cv.CipherParams.IV)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ct, _, err := decodeCiphertext(cv.CipherTextB64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ct, err = withGCMTag(ct, cv.CipherParams.Tag); err != nil {
+			t.Fatal(err)
+		}
+		plain, err := oldGCM.Open(nil, aesNonce, ct, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plain = edit(cv, plain)
+
+		sealed := newGCM.Seal(nil, aesNonce, plain, nil)
+		tagAt := len(sealed) - newGCM.Overhead()
+		hash := sha512.Sum512(plain)
+		cv.CipherTextB64 = base64.StdEncoding.EncodeToString(sealed[:tagAt])
+		cv.CipherParams.Tag = hex.EncodeToString(sealed[tagAt:])
+		cv.Hash = hex.EncodeToString(hash[:])
+	})
+}
+
+// reencryptBackupFile copies a backup file with the decrypted data of every vault edited, re-encrypted with the file's phrase.
+func reencryptBackupFile(t *testing.T, src, mnemonic string, edit func(clearVault map[string]json.RawMessage)) string {
+	t.Helper()
+	return resealBackupFile(t, src, mnemonic, mnemonic, func(_ *CipheredVault, plain []byte) []byte {
+		clearVault := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(plain, &clearVault); err != nil {
+			t.Fatal(err)
+		}
+		edit(clearVault)
+		plain, err := json.Marshal(clearVault)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return plain
+	})
+}
+
+func TestTool_New_V2_AutoThreshold(t *testing.T) {
+	// the backups claim a threshold of 5, but the vault's shares were created with a threshold of 3
+	setThreshold := func(clearVault map[string]json.RawMessage) {
+		clearVault["threshold"] = json.RawMessage("5")
+	}
+	files := []VaultsDataFile{
+		{File: reencryptBackupFile(t, "../test-files/new_bvn.json", mmNewBvn, setThreshold), Mnemonics: mmNewBvn},
+		{File: reencryptBackupFile(t, "../test-files/new_x2q.json", mmNewX2q, setThreshold), Mnemonics: mmNewX2q},
+		{File: reencryptBackupFile(t, "../test-files/new_u44.json", mmNewU44, setThreshold), Mnemonics: mmNewU44},
+	}
+	vaultID := "yz5x2a7zhwwt7r0lv4gklqns"
+	opts := &Options{NonceOverride: -1, MinInflatedKB: DefaultMinInflatedKB, MaxInflatedKB: DefaultMaxInflatedKB}
+	_, _, _, _, _, err := runTool(context.Background(), files, &vaultID, opts)
+	if !assert.Error(t, err) {
+		return
+	}
+	assert.Contains(t, err.Error(), "not enough shares")
+
+	opts.AutoThreshold = true
+	_, ecSK, _, _, warnings, err := runTool(context.Background(), files, &vaultID, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, ecSK, 32)
+	if !assert.Len(t, warnings, 1) {
+		return
+	}
+	assert.Equal(t, WarnThresholdDetected, warnings[0].Kind)
+	assert.Contains(t, warnings[0].Message, "Auto-detected threshold 3")
+}
+
+func TestTool_New_V2_ThresholdMismatch(t *testing.T) {
+	// one of the backups claims a threshold of 5, while the others store the threshold of 3 the vault's shares were created with
+	bvn := reencryptBackupFile(t, "../test-files/new_bvn.json", mmNewBvn, func(clearVault map[string]json.RawMessage) {
+		clearVault["threshold"] = json.RawMessage("5")
+	})
+	files := []VaultsDataFile{
+		{File: bvn, Mnemonics: mmNewBvn},
+		{File: "../test-files/new_x2q.json", Mnemonics: mmNewX2q},
+		{File: "../test-files/new_u44.json", Mnemonics: mmNewU44},
+	}
+	vaultID := "yz5x2a7zhwwt7r0lv4gklqns"
+	opts := NewOptions(vaultID)
+	_, ecSK, _, _, warnings, err := runTool(context.Background(), files, &vaultID, &opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, ecSK, 32)
+	if !assert.Len(t, warnings, 1) {
+		return
+	}
+	assert.Equal(t, WarnThresholdMismatch, warnings[0].Kind)
+	assert.Contains(t, warnings[0].Message, "The files store different thresholds for vault `yz5x2a7zhwwt7r0lv4gklqns`: "+
+		"3 in `../test-files/new_x2q.json`, `../test-files/new_u44.json`, 5 in `"+bvn+"`. Threshold 3 is used")
+
+	// a -threshold that the files do not store is flagged before the reconstruction fails
+	opts.QuorumOverride = 2
+	_, _, _, _, warnings, err = runTool(context.Background(), files[1:], &vaultID, &opts)
+	assert.Error(t, err)
+	if assert.Len(t, warnings, 2) {
+		assert.Equal(t, WarnThresholdMismatch, warnings[1].Kind)
+		assert.Contains(t, warnings[1].Message, "-threshold 2 differs from the threshold that the files store for vault `yz5x2a7zhwwt7r0lv4gklqns`: "+
+			"3 in `../test-files/new_x2q.json`, `../test-files/new_u44.json`.")
+	}
+}
+
+func TestTool_New_V2_AutoThreshold_NotFound(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn},
+		{File: "../test-files/new_x2q.json", Mnemonics: mmNewX2q},
+		{File: "../test-files/new_u44.json", Mnemonics: mmNewU44},
+	}
+	// the latest generation of this vault lacks shares, so no threshold reconstructs its public key
+	vaultID := "nbpxb6hmupk1ygcl53jf9zg5"
+	_, ecSK, _, _, _, err := runTool(context.Background(), files, &vaultID, &Options{
+		NonceOverride: -1, AutoThreshold: true, MinInflatedKB: DefaultMinInflatedKB, MaxInflatedKB: DefaultMaxInflatedKB,
+	})
+	if !assert.Error(t, err) {
+		return
+	}
+	assert.Nil(t, ecSK)
+	assert.Contains(t, err.Error(), "-auto-threshold: no threshold from 1 to")
+}
+
+func TestTool_New_V2_DuplicateShares(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn},
+		{File: "../test-files/new_x2q.json", Mnemonics: mmNewX2q},
+		{File: "../test-files/new_u44.json", Mnemonics: mmNewU44},
+	}
+	vaultID := "yz5x2a7zhwwt7r0lv4gklqns"
+	_, expectedSK, _, _, _, err := runTool(context.Background(), files, &vaultID, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the same party's backup file is supplied twice
+	files = append(files, VaultsDataFile{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn})
+	_, ecSK, _, vaultsFormData, warnings, err := runTool(context.Background(), files, &vaultID, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, expectedSK, ecSK)
+	for _, vault := range vaultsFormData {
+		if vault.VaultID == vaultID {
+			assert.Equal(t, 3, vault.NumberOfShares)
+		}
+	}
+	if !assert.Len(t, warnings, 1) {
+		return
+	}
+	assert.Equal(t, WarnDuplicateShares, warnings[0].Kind)
+	assert.Contains(t, warnings[0].Message, "Dropped 1 duplicate share(s)")
+}
+
+func TestTool_New_V2_CorruptShare(t *testing.T) {
+	files := []VaultsDataFile{
+		{File: "../test-files/new_bvn.json", Mnemonics: mmNewBvn},
+		{File: "../test-files/new_x2q.json", Mnemonics: mmNewX2q},
+		{File: "../test-files/new_u44.json", Mnemonics: mmNewU44},
+	}
+	vaultID := "yz5x2a7zhwwt7r0lv4gklqns"
+	_, expectedSK, _, _, _, err := runTool(context.Background(), files, &vaultID, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// a copy of a party's backup file whose V2 shares were cut short is skipped, and the intact copy is used instead
+	truncateShares := func(clearVault map[string]json.RawMessage) {
+		var curves []ClearVaultCurve
+		if err := json.Unmarshal(clearVault["curves"], &curves); err != nil {
+			t.Fatal(err)
+		}
+		for _, curve := range curves {
+			for i, share := range curve.Shares {
+				shareID, b64Part, _ := strings.Cut(strings.TrimPrefix(share, v2MagicPrefix), "_")
+				deflated, err := base64.StdEncoding.DecodeString(b64Part)
+				if err != nil {
+					t.Fatal(err)
+				}
+				curve.Shares[i] = v2MagicPrefix + shareID + "_" + base64.StdEncoding.EncodeToString(deflated[:len(deflated)/2])
+			}
+		}
+		clearVault["curves"], _ = json.Marshal(curves)
+	}
+	corrupt := reencryptBackupFile(t, "../test-files/new_bvn.json", mmNewBvn, truncateShares)
+	files = append([]VaultsDataFile{{File: corrupt, Mnemonics: mmNewBvn}}, files...)
+	_, ecSK, _, _, warnings, err := runTool(context.Background(), files, &vaultID, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, expectedSK, ecSK)
+	if !assert.Len(t, warnings, 1) {
+		return
+	}
+	assert.Equal(t, WarnCorruptShares, warnings[0].Kind)
+	assert.Contains(t, warnings[0].Message, "Skipped 2 corrupt share(s) of vault `yz5x2a7zhwwt7r0lv4gklqns` in file `"+corrupt+"`")
+	assert.Contains(t, warnings[0].Message, "its deflate data is corrupt or cut short (failed to read from flate reader: unexpected EOF)")
+
+	// without the intact copy, the vault has too few shares left
+	_, _, _, _, warnings, err = runTool(context.Background(), []VaultsDataFile{files[0], files[2], files[3]}, &vaultID, nil)
+	assert.ErrorContains(t, err, "not enough shares to recover the key for vault yz5x2a7zhwwt7r0lv4gklqns (need 3, have 2)")
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, WarnCorruptShares, warnings[0].Kind)
+	}
+}
+
+func TestSharesNotMatching(t *testing.T) {
+	vaultPub := crypto.ScalarBaseMult(tss.S256(), big.NewInt(7))
+	otherPub := crypto.ScalarBaseMult(tss.S256(), big.NewInt(8))
+	share := func(id int64, pub *crypto.ECPoint) *ecdsa_keygen.LocalPartySaveData {
+		sd := new(ecdsa_keygen.LocalPartySaveData)
+		sd.ShareID, sd.ECDSAPub = big.NewInt(id), pub
+		return sd
+	}
+	fields := func(sd *ecdsa_keygen.LocalPartySaveData) (*big.Int, *crypto.ECPoint) {
+		return sd.ShareID, sd.ECDSAPub
+	}
+
+	shares := []*ecdsa_keygen.LocalPartySaveData{share(1, vaultPub), share(2, vaultPub), share(3, otherPub), share(4, nil)}
+	assert.Equal(t, []string{"3", "4"}, sharesNotMatching(shares, fields))
+	assert.Empty(t, sharesNotMatching(shares[:2], fields))
+	assert.Empty(t, sharesNotMatching(nil, fields))
+}
+
+func TestSharesMismatchError(t *testing.T) {
+	ecShare := func(id, sk int64) *ecdsa_keygen.LocalPartySaveData {
+		sd := new(ecdsa_keygen.LocalPartySaveData)
+		sd.ShareID, sd.ECDSAPub = big.NewInt(id), crypto.ScalarBaseMult(tss.S256(), big.NewInt(sk))
+		return sd
+	}
+	edShare := func(id, sk int64) *eddsa_keygen.LocalPartySaveData {
+		sd := new(eddsa_keygen.LocalPartySaveData)
+		sd.ShareID, sd.EDDSAPub = big.NewInt(id), crypto.ScalarBaseMult(tss.Edwards(), big.NewInt(sk))
+		return sd
+	}
+	ecShares := []*ecdsa_keygen.LocalPartySaveData{ecShare(1, 7), ecShare(2, 7)}
+	assert.NoError(t, sharesMismatchError("v1", ecShares, []*eddsa_keygen.LocalPartySaveData{edShare(1, 9), edShare(2, 9)}))
+
+	err := sharesMismatchError("v1", append(ecShares, ecShare(3, 8)), nil)
+	if assert.ErrorIs(t, err, ErrPubKeyMismatch) {
+		assert.Contains(t, err.Error(), "the ECDSA public key of share(s) 3 does not match the public key of share 1 of vault v1")
+	}
+
+	// every ECDSA share may have been skipped as corrupt, so an EdDSA outlier is named against the first EdDSA share
+	err = sharesMismatchError("v1", nil, []*eddsa_keygen.LocalPartySaveData{edShare(4, 9), edShare(5, 9), edShare(6, 10)})
+	if assert.ErrorIs(t, err, ErrPubKeyMismatch) {
+		assert.Contains(t, err.Error(), "the EdDSA public key of share(s) 6 does not match the public key of share 4 of vault v1")
+	}
+}
+
+func TestDropKeylessShares(t *testing.T) {
+	vaultPub := crypto.ScalarBaseMult(tss.S256(), big.NewInt(7))
+	share := func(id int64, pub *crypto.ECPoint) *ecdsa_keygen.LocalPartySaveData {
+		sd := new(ecdsa_keygen.LocalPartySaveData)
+		sd.ShareID, sd.Xi, sd.ECDSAPub = big.NewInt(id), big.NewInt(id*100), pub
+		return sd
+	}
+	fields := func(sd *ecdsa_keygen.LocalPartySaveData) (*big.Int, *big.Int, *crypto.ECPoint) {
+		return sd.ShareID, sd.Xi, sd.ECDSAPub
+	}
+
+	// the first share is keyless, so the second one becomes the reference of the public key checks
+	keyless := share(1, nil)
+	shares, dropped := dropKeylessShares([]*ecdsa_keygen.LocalPartySaveData{keyless, share(2, vaultPub), share(3, vaultPub)}, fields)
+	assert.Equal(t, []string{"1"}, dropped)
+	if assert.Len(t, shares, 2) {
+		assert.Equal(t, int64(2), shares[0].ShareID.Int64())
+	}
+	assert.Zero(t, keyless.Xi.Sign())
+	assert.Empty(t, sharesNotMatching(shares, func(sd *ecdsa_keygen.LocalPartySaveData) (*big.Int, *crypto.ECPoint) {
+		return sd.ShareID, sd.ECDSAPub
+	}))
+
+	shares, dropped = dropKeylessShares([]*ecdsa_keygen.LocalPartySaveData{share(4, nil)}, fields)
+	assert.Empty(t, shares)
+	assert.Equal(t, []string{"4"}, dropped)
+}
+
+func TestDecryptVaults_Order(t *testing.T) {
+	jobs := make([]vaultJob, 0, 8)
+	for i := range 8 {
+		// an invalid IV fails the job, naming its vault
+		jobs = append(jobs, vaultJob{vaultID: fmt.Sprintf("v%d", i), cipheredVault: CipheredVault{CipherParams: CipherParams{IV: "x"}}})
+	}
+	results := decryptVaults(context.Background(), jobs, inflateBounds{max: 1024}, nil, nil, nil)
+	if !assert.Len(t, results, len(jobs)) {
+		return
+	}
+	for i, result := range results {
+		assert.Equal(t, jobs[i].vaultID, result.vaultID)
+	}
+	// the first job always runs, and any job that ran failed with its own vault
+	if !assert.Error(t, results[0].err) {
+		return
+	}
+	for _, result := range results {
+		if result.err != nil {
+			assert.Contains(t, result.err.Error(), "vault "+result.vaultID+":")
+		}
+	}
+}
+
+// rekeyBackupFile copies a backup file with every vault re-encrypted with the key of another phrase, naming the given cipher.
+func rekeyBackupFile(t *testing.T, src, mnemonic, newMnemonic, cipherName string) string {
+	t.Helper()
+	return resealBackupFile(t, src, mnemonic, newMnemonic, func(cv *CipheredVault, plain []byte) []byte {
+		cv.Cipher = cipherName
+		return plain
+	})
+}
+
+func TestTool_ShortPhrases(t *testing.T) {
+	vaultID := "phrot42ltzawmn7nrm7mqvl5"
+	tests := []struct {
+		name     string
+		mnemonic string
+		cipher   string
+		words    int
+	}{
+		{"12 Words", strings.Repeat("abandon ", 11) + "about", "aes-128-gcm", 12},
+		{"18 Words", strings.Repeat("abandon ", 17) + "agent", "aes-192-gcm", 18},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := rekeyBackupFile(t, "../test-files/new_single.json", mmNewSingle, tt.mnemonic, tt.cipher)
+			assert.Equal(t, tt.words, PhraseWords(file))
+
+			address, ecSK, _, _, _, err := runTool(context.Background(), []VaultsDataFile{{File: file, Mnemonics: tt.mnemonic}}, &vaultID, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Len(t, ecSK, 32)
+			assert.Equal(t, "0xeFb4d67625fE88A4B7Cb854b75f6B1ef370550d1", address)
+
+			// the phrase of the original file is a valid phrase, but of the wrong length for this file
+			_, _, _, _, _, err = runTool(context.Background(), []VaultsDataFile{{File: file, Mnemonics: mmNewSingle}}, &vaultID, nil)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), fmt.Sprintf("it is encrypted with %s, which takes a %d word phrase, but the phrase has 24 words", tt.cipher, tt.words))
+			}
+		})
+	}
+	assert.Equal(t, 24, PhraseWords("../test-files/new_single.json"))
+	assert.Equal(t, 0, PhraseWords("../test-files/nope.json"))
+}
+
+func TestPhraseKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		mnemonic string
+		keySize  int
+		wantErr  string
+	}{
+		{"24 Words", strings.Repeat("abandon ", 23) + "art", 32, ""},
+		{"18 Words", strings.Repeat("abandon ", 17) + "agent", 24, ""},
+		{"12 Words", strings.Repeat("abandon ", 11) + "about", 16, ""},
+		// valid BIP39 phrases, but their entropy is not an AES key
+		{"15 Words", strings.Repeat("abandon ", 14) + "address", 0, "the phrase for `f.json` has 15 words, but the phrase of a backup file has 12, 18 or 24 words"},
+		{"21 Words", strings.Repeat("abandon ", 20) + "admit", 0, "has 21 words"},
+		{"Bad Checksum", strings.TrimSpace(strings.Repeat("abandon ", 24)), 0, "are your words correct?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aesKey, err := phraseKey(VaultsDataFile{File: "f.json", Mnemonics: tt.mnemonic})
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.wantErr)
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Len(t, aesKey, tt.keySize)
+		})
+	}
+}
+
+func TestDecryptVault(t *testing.T) {
+	file := VaultsDataFile{File: "../test-files/new_single.json", Mnemonics: mmNewSingle}
+	aesKey, err := phraseKey(file)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var cipheredVault CipheredVault
+	err = readBackup(file.File, func(vaultID string, reshares CipheredVaultMap) error {
+		for _, cv := range reshares {
+			if vaultID == "phrot42ltzawmn7nrm7mqvl5" {
+				cipheredVault = cv
+			}
+		}
+		return nil
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	clearVault, err := DecryptVault(cipheredVault, aesKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEmpty(t, clearVault.Name)
+	assert.Equal(t, 2, clearVault.Quroum)
+	if assert.Len(t, clearVault.Curves, 2) {
+		assert.NotEmpty(t, clearVault.Curves[0].Shares)
+	}
+
+	// the integrity check is part of the decryption
+	tampered := cipheredVault
+	tampered.Hash = strings.Repeat("0", 128)
+	_, err = DecryptVault(tampered, aesKey)
+	assert.ErrorContains(t, err, "⚠ failed to decrypt the vault: integrity check failed")
+
+	wrongKey := make([]byte, len(aesKey))
+	_, err = DecryptVault(cipheredVault, wrongKey)
+	assert.ErrorContains(t, err, "⚠ failed to decrypt the vault: cipher: message authentication failed (on decrypt)")
+}
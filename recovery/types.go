@@ -2,7 +2,7 @@
 // SPDX-License-Identifier: AGPL-3.0-or-later
 // Full license text available in LICENSE file in repository root.
 
-package main
+package recovery
 
 import (
 	ecdsa_keygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
@@ -38,6 +38,8 @@ type (
 		SharesLegacy     []string          `json:"shares"`
 		LastReShareNonce int               `json:"-"`
 		Curves           []ClearVaultCurve `json:"curves"`
+		// ECDSACurve is the ECDSA curve named by the algorithm of the vault's curve, e.g. CurveP256, or empty if it names none.
+		ECDSACurve string `json:"-"`
 	}
 
 	VaultAllSharesECDSA map[string][]*ecdsa_keygen.LocalPartySaveData
@@ -45,4 +47,42 @@ type (
 
 	SaveData interface {
 	}
+
+	// WarningKind classifies a non-fatal issue found by runTool.
+	WarningKind int
+
+	// Warning is a non-fatal issue collected by runTool, to be rendered by the caller.
+	Warning struct {
+		Kind    WarningKind
+		VaultID string
+		Message string
+	}
+)
+
+const (
+	v2MagicPrefix = "_V2_"
+	gcmTagSize    = 16
+)
+
+const (
+	WarnNonceOverride WarningKind = iota + 1
+	WarnQuorumOverride
+	WarnNonceMismatch
+	WarnKeystoreSkipped
+	WarnKeystoreFailed
+	WarnNonceDetected
+	WarnThresholdDetected
+	WarnDuplicateShares
+	WarnNotEnoughShares
+	WarnQRFileFailed
+	WarnBalanceFailed
+	WarnSweepFailed
+	WarnKeylessShares
+	WarnThresholdMismatch
+	WarnClipboardFailed
+	WarnCorruptShares
 )
+
+func (w Warning) String() string {
+	return "⚠ " + w.Message
+}
@@ -0,0 +1,132 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/config"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/data"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/version"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+)
+
+type (
+	// fileDigest is an input file of the -report, with the SHA-256 hash of its content as stored.
+	fileDigest struct {
+		File   string
+		SHA256 string
+	}
+
+	// reportVault is a recovered vault of the -report. It only holds public data: the keys are never part of it.
+	reportVault struct {
+		VaultID, Name            string
+		Nonce, Quorum, Threshold int
+		Shares                   int
+		Sections                 []outputSection
+		Checks                   []string
+		Warnings                 []string
+	}
+)
+
+// newReportVault describes a recovered vault for the -report, with the public values of its recovered data. It is only
+// called once the checks that were asked for have passed, as a failed check stops the tool before a report is written.
+func newReportVault(result *recovery.Result, sections []outputSection, appConfig config.AppConfig) reportVault {
+	vault := reportVault{
+		VaultID: result.VaultID, Name: result.Name,
+		Nonce: result.Nonce, Quorum: result.Quorum, Threshold: result.Threshold, Shares: result.Shares,
+		Sections: publicSections(sections),
+		Checks:   []string{"Public key check: passed, the reconstructed keys match the public keys in the vault's shares"},
+	}
+	if appConfig.VerifyAgainst != "" {
+		vault.Checks = append(vault.Checks, fmt.Sprintf("Wallet v3 file `%s`: matches the recovered key", appConfig.VerifyAgainst))
+	}
+	if appConfig.ExpectedAddress != "" {
+		vault.Checks = append(vault.Checks, fmt.Sprintf("Expected address `%s`: matches", appConfig.ExpectedAddress))
+	}
+	for _, w := range result.Warnings {
+		vault.Warnings = append(vault.Warnings, recovery.Redact(w.Message))
+	}
+	return vault
+}
+
+// fileDigests hashes the input files as they are stored, e.g. still gzipped, so that the hashes can be checked against the files later.
+func fileDigests(files []string) ([]fileDigest, error) {
+	digests := make([]fileDigest, 0, len(files))
+	for _, file := range files {
+		sum, err := data.FileSHA256(file)
+		if err != nil {
+			return nil, fmt.Errorf("⚠ unable to hash `%s` for the -report: %s", file, err)
+		}
+		digests = append(digests, fileDigest{File: file, SHA256: sum})
+	}
+	return digests, nil
+}
+
+// renderReport renders the -report of a recovery as Markdown: when and with which build it ran, the input files and,
+// per vault, what it was recovered from, its addresses and the checks that passed. No private key is written.
+func renderReport(now time.Time, verifyOnly bool, files []fileDigest, vaults []reportVault) string {
+	var sb strings.Builder
+	commit, date := version.Info()
+	mode := "recovery"
+	if verifyOnly {
+		mode = "verification (-verify), no private keys were shown"
+	}
+	sb.WriteString("# Vault Recovery Report\n\n")
+	fmt.Fprintf(&sb, "- Date: %s\n", now.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&sb, "- Tool: io.finnet Key Recovery Tool %s (commit %s, built %s)\n", version.Version, commit, date)
+	fmt.Fprintf(&sb, "- Mode: %s\n", mode)
+
+	sb.WriteString("\n## Input Files\n\n| File | SHA-256 |\n| --- | --- |\n")
+	for _, file := range files {
+		fmt.Fprintf(&sb, "| `%s` | `%s` |\n", markdownCell(file.File), file.SHA256)
+	}
+
+	for _, vault := range vaults {
+		fmt.Fprintf(&sb, "\n## Vault \"%s\"\n\n", vault.Name)
+		fmt.Fprintf(&sb, "- Vault id: `%s`\n", vault.VaultID)
+		fmt.Fprintf(&sb, "- Threshold: %d (the backups state %d)\n", vault.Threshold, vault.Quorum)
+		fmt.Fprintf(&sb, "- Reshare nonce: %d\n", vault.Nonce)
+		fmt.Fprintf(&sb, "- Shares found: %d\n", vault.Shares)
+		for _, check := range vault.Checks {
+			fmt.Fprintf(&sb, "- %s\n", check)
+		}
+
+		sb.WriteString("\n| Chain | Field | Value |\n| --- | --- | --- |\n")
+		for _, section := range vault.Sections {
+			for _, field := range section.Fields {
+				fmt.Fprintf(&sb, "| %s | %s | `%s` |\n", section.Title, field.Label, markdownCell(field.Value))
+			}
+		}
+		if len(vault.Warnings) > 0 {
+			sb.WriteString("\nWarnings:\n\n")
+			for _, w := range vault.Warnings {
+				fmt.Fprintf(&sb, "- %s\n", strings.ReplaceAll(w, "\n⚠ ", " "))
+			}
+		}
+	}
+	sb.WriteString("\nThis report holds no private keys, WIFs or phrases.\n")
+	return sb.String()
+}
+
+// writeReport writes the -report of the recovered vaults, with the hashes of the input files.
+func writeReport(appConfig config.AppConfig, vaults []reportVault) error {
+	digests, err := fileDigests(appConfig.Filenames)
+	if err != nil {
+		return err
+	}
+	// the file was checked before the recovery, so that a recovery is not run only to fail here
+	if err = writeFileAtomic(appConfig.Report, []byte(renderReport(time.Now(), appConfig.VerifyOnly, digests, vaults)), 0o644); err != nil {
+		return fmt.Errorf("⚠ could not write the -report file: %s", strings.TrimPrefix(err.Error(), "⚠ "))
+	}
+	return nil
+}
+
+// markdownCell keeps a value, e.g. a signed message, from breaking the row of a Markdown table.
+func markdownCell(value string) string {
+	return strings.NewReplacer("|", "\\|", "\r", " ", "\n", " ").Replace(value)
+}
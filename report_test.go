@@ -0,0 +1,54 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/config"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderReport(t *testing.T) {
+
+	result := testResult(t, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7", "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44")
+	result.Nonce, result.Quorum, result.Threshold, result.Shares = 4, 3, 3, 3
+	result.Warnings = []recovery.Warning{{Message: "⚠ a warning"}}
+	sections := recoveredSections(result, "", recovery.BTCAddressBech32, false)
+	vault := newReportVault(result, sections, config.AppConfig{ExpectedAddress: result.Address})
+	files := []fileDigest{{File: "a|b.json", SHA256: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8"}}
+
+	out := renderReport(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), false, files, []reportVault{vault})
+	assert.Contains(t, out, "- Date: 2024-05-01T12:00:00Z\n")
+	assert.Contains(t, out, "| `a\\|b.json` | `5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8` |\n")
+	assert.Contains(t, out, "- Threshold: 3 (the backups state 3)\n- Reshare nonce: 4\n- Shares found: 3\n")
+	assert.Contains(t, out, "- Expected address `0xeFb4d67625fE88A4B7Cb854b75f6B1ef370550d1`: matches\n")
+	assert.Contains(t, out, "| Ethereum | Address | `0xeFb4d67625fE88A4B7Cb854b75f6B1ef370550d1` |\n")
+	assert.Contains(t, out, "- ⚠ a warning\n")
+	assert.NotContains(t, out, "0a8376f6cb75d7e4197d35d2f7254f60f08827d5604589ea57843c3f754983b7")
+	assert.NotContains(t, out, "04523b4b19d426517fb20b51935bc969900e016d26da0a3357f4cb1af57d8e44")
+	assert.NotContains(t, out, result.Chains.BitcoinMainnet.WIF)
+	assert.NotContains(t, out, result.Chains.BitcoinTestnet.WIF)
+}
+
+func TestFileDigests(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "backup.json")
+	if !assert.NoError(t, os.WriteFile(file, []byte("password"), 0o600)) {
+		return
+	}
+	digests, err := fileDigests([]string{file})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []fileDigest{{File: file, SHA256: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8"}}, digests)
+
+	_, err = fileDigests([]string{filepath.Join(t.TempDir(), "missing.json")})
+	assert.ErrorContains(t, err, "for the -report")
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/version"
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+)
+
+// selfTestBackup is a synthetic single signer vault whose keys are not sensitive, so that -self-test needs no real backup.
+//
+//go:embed test-files/new_single.json.gz
+var selfTestBackup []byte
+
+const (
+	selfTestMnemonic = "jacket zone rotate merry forward paper cruel forget train prevent teach bitter lumber razor uncle stairs finger chief curtain render tray tower odor garbage"
+	selfTestVaultID  = "phrot42ltzawmn7nrm7mqvl5"
+	selfTestEthereum = "0xeFb4d67625fE88A4B7Cb854b75f6B1ef370550d1"
+	selfTestSolana   = "J5GSXo8C1CGCy6qZJE7JwN93xuZ7R4LWGfXta7R697ig"
+)
+
+// runSelfTest recovers the embedded sample vault through the same steps as a real recovery, from decrypting its backup file
+// to deriving its addresses, and checks the addresses against the known ones. The keys are wiped before it returns.
+func runSelfTest(ctx context.Context) (selfTestJSON, error) {
+	dir, err := os.MkdirTemp("", "recovery-self-test-*")
+	if err != nil {
+		return selfTestJSON{}, fmt.Errorf("⚠ self-test: unable to create a temporary folder: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "sample.json.gz")
+	if err = os.WriteFile(file, selfTestBackup, 0o600); err != nil {
+		return selfTestJSON{}, fmt.Errorf("⚠ self-test: unable to write the sample backup file: %s", err)
+	}
+
+	result, err := recovery.Recover(ctx, []recovery.VaultsDataFile{{File: file, Mnemonics: selfTestMnemonic}}, recovery.NewOptions(selfTestVaultID))
+	if result != nil {
+		defer result.Wipe()
+	}
+	if err != nil {
+		return selfTestJSON{}, fmt.Errorf("⚠ self-test FAILED: the sample vault could not be recovered: %s", err)
+	}
+	commit, date := version.Info()
+	passed := selfTestJSON{
+		versionJSON:     versionJSON{Version: version.Version, Commit: commit, BuildDate: date},
+		Pass:            true,
+		VaultID:         result.VaultID,
+		EthereumAddress: result.Address,
+		SolanaAddress:   result.Chains.Solana,
+	}
+	for _, check := range []struct{ chain, got, want string }{
+		{"Ethereum", result.Address, selfTestEthereum},
+		{"Solana", result.Chains.Solana, selfTestSolana},
+	} {
+		if check.got != check.want {
+			return selfTestJSON{}, fmt.Errorf("⚠ self-test FAILED: the sample vault has the %s address `%s` instead of `%s`. Do not use this build", check.chain, check.got, check.want)
+		}
+		passed.Checks = append(passed.Checks, selfTestCheckJSON{Check: check.chain + " address", Expected: check.want, Actual: check.got, Pass: true})
+	}
+	return passed, nil
+}
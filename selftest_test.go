@@ -0,0 +1,40 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunSelfTest(t *testing.T) {
+	result, err := runSelfTest(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	commit, date := version.Info()
+	assert.Equal(t, selfTestJSON{
+		versionJSON:     versionJSON{Version: version.Version, Commit: commit, BuildDate: date},
+		Pass:            true,
+		VaultID:         selfTestVaultID,
+		EthereumAddress: selfTestEthereum,
+		SolanaAddress:   selfTestSolana,
+		Checks: []selfTestCheckJSON{
+			{Check: "Ethereum address", Expected: selfTestEthereum, Actual: selfTestEthereum, Pass: true},
+			{Check: "Solana address", Expected: selfTestSolana, Actual: selfTestSolana, Pass: true},
+		},
+	}, result)
+
+	out, err := json.Marshal(result)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(out), `"version":"`+version.Version+`","commit":"`+commit+`"`)
+	assert.Contains(t, string(out), `"checks":[{"check":"Ethereum address","expected":"`+selfTestEthereum+`","actual":"`+selfTestEthereum+`","pass":true}`)
+}
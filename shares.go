@@ -0,0 +1,47 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+// renderShareTable renders the -show-shares table of a vault's shares, with the shares found in more than one file in red.
+func renderShareTable(vault *recovery.VaultShares, plain bool) string {
+	t := table.New().Headers("#", "FILE", "NONCE", "CURVE", "SHARE ID", "PARTY", "NOTE")
+	for i, share := range vault.Shares {
+		party := "-"
+		if share.PartyIndex > 0 {
+			party = fmt.Sprintf("%d/%d", share.PartyIndex, share.Parties)
+		}
+		note := ""
+		if share.Duplicate {
+			note = "duplicate"
+		}
+		t.Row(strconv.Itoa(i+1), share.File, strconv.Itoa(share.Nonce), share.Curve, share.ShareID, party, note)
+	}
+	return styleTable(t, plain, func(i int) bool { return vault.Shares[i].Duplicate })
+}
+
+// shareSummary tells whether the distinct ECDSA shares of the vault meet its threshold, after the corrupt shares that were skipped.
+func shareSummary(vault *recovery.VaultShares) string {
+	var sb strings.Builder
+	for _, skipped := range vault.Skipped {
+		fmt.Fprintf(&sb, "⚠ Skipped a corrupt share in %s.\n", skipped)
+	}
+	distinct := vault.Distinct(recovery.CurveECDSA)
+	if distinct < vault.Threshold {
+		fmt.Fprintf(&sb, "⚠ Vault \"%s\" has %d distinct ECDSA share(s), but its threshold is %d: add the backup files of %d more of its parties.\n",
+			vault.Name, distinct, vault.Threshold, vault.Threshold-distinct)
+	} else {
+		fmt.Fprintf(&sb, "✓ Vault \"%s\" has %d distinct ECDSA share(s), which meets its threshold of %d.\n", vault.Name, distinct, vault.Threshold)
+	}
+	return sb.String()
+}
@@ -0,0 +1,43 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/recovery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderShareTable(t *testing.T) {
+	vault := &recovery.VaultShares{VaultID: "v1", Name: "A", Threshold: 2, Shares: []recovery.ShareInfo{
+		{File: "a.json", Nonce: 1, Curve: recovery.CurveECDSA, ShareID: "123", PartyIndex: 1, Parties: 3},
+		{File: "b.json", Nonce: 1, Curve: recovery.CurveECDSA, ShareID: "123", PartyIndex: 1, Parties: 3, Duplicate: true},
+		{File: "c.json", Nonce: 0, Curve: recovery.CurveECDSA, ShareID: "456"},
+	}}
+	lines := strings.Split(strings.TrimRight(renderShareTable(vault, true), "\n"), "\n")
+	if !assert.Len(t, lines, 4) {
+		return
+	}
+	assert.Equal(t, []string{"#", "FILE", "NONCE", "CURVE", "SHARE", "ID", "PARTY", "NOTE"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"2", "b.json", "1", "ECDSA", "123", "1/3", "duplicate"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{"3", "c.json", "0", "ECDSA", "456", "-"}, strings.Fields(lines[3]))
+}
+
+func TestShareSummary(t *testing.T) {
+	vault := &recovery.VaultShares{Name: "A", Threshold: 2, Shares: []recovery.ShareInfo{
+		{Curve: recovery.CurveECDSA, ShareID: "1"},
+		{Curve: recovery.CurveEdDSA, ShareID: "1"},
+		{Curve: recovery.CurveECDSA, ShareID: "1", Duplicate: true},
+	}}
+	assert.Contains(t, shareSummary(vault), "has 1 distinct ECDSA share(s), but its threshold is 2: add the backup files of 1 more")
+
+	vault.Shares = append(vault.Shares, recovery.ShareInfo{Curve: recovery.CurveECDSA, ShareID: "2"})
+	assert.Contains(t, shareSummary(vault), "✓ Vault \"A\" has 2 distinct ECDSA share(s), which meets its threshold of 2.")
+
+	vault.Skipped = []string{"`c.json`: ECDSA V2 share 3: its deflate data is corrupt or cut short (unexpected EOF)"}
+	assert.Contains(t, shareSummary(vault), "⚠ Skipped a corrupt share in `c.json`: ECDSA V2 share 3: its deflate data is corrupt or cut short (unexpected EOF).\n✓ Vault")
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// signedMessage is a message signed with the recovered key to prove control of the vault.
+type signedMessage struct {
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+	Address   string `json:"address"`
+}
+
+// signMessage signs message as Ethereum personal_sign does, with the EIP-191 prefix, and returns the 65-byte signature as hex
+// with the address recovered from it. The V byte is 27 or 28, as wallets and block explorers expect.
+func signMessage(ecSK []byte, message string) (*signedMessage, error) {
+	privKey, err := ethcrypto.ToECDSA(ecSK)
+	if err != nil {
+		return nil, fmt.Errorf("could not load the private key to sign the message: %v", err)
+	}
+	// the copy of the key made for signing is cleared too
+	defer privKey.D.SetInt64(0)
+
+	hash := accounts.TextHash([]byte(message))
+	sig, err := ethcrypto.Sign(hash, privKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not sign the message: %v", err)
+	}
+	pub, err := ethcrypto.SigToPub(hash, sig)
+	if err != nil {
+		return nil, fmt.Errorf("could not recover the address of the signature: %v", err)
+	}
+	sig[64] += 27
+	return &signedMessage{
+		Message:   message,
+		Signature: "0x" + hex.EncodeToString(sig),
+		Address:   ethcrypto.PubkeyToAddress(*pub).Hex(),
+	}, nil
+}
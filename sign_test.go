@@ -0,0 +1,27 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignMessage(t *testing.T) {
+	// the web3.js eth.accounts.sign example
+	ecSK, _ := hex.DecodeString("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+
+	signed, err := signMessage(ecSK, "Some data")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Some data", signed.Message)
+	assert.Equal(t, "0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c", signed.Signature)
+	assert.Equal(t, "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23", signed.Address)
+	// the key passed in is left for the caller to wipe
+	assert.Equal(t, "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318", hex.EncodeToString(ecSK))
+}
@@ -0,0 +1,215 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// sweepGasLimit is the gas of a plain transfer. A destination contract that runs code when it is paid needs more, and is not supported.
+const sweepGasLimit = 21000
+
+// sweepTx is a signed -sweep-to transaction, for the operator to check and broadcast. It is never broadcast by the tool.
+type sweepTx struct {
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Value        string `json:"value"`
+	Nonce        uint64 `json:"nonce"`
+	GasPriceGwei string `json:"gasPriceGwei"`
+	GasLimit     uint64 `json:"gasLimit"`
+	ChainID      int64  `json:"chainId"`
+	Hash         string `json:"hash"`
+	RawTx        string `json:"rawTransaction"`
+}
+
+// sweepParams are the values of the sweep transaction that are not derived from the key: set with the -sweep flags,
+// or left unset (nil, or -1 for the nonce) to be looked up with -rpc. The chain id is always the one of -chain-id.
+type sweepParams struct {
+	nonce    int64
+	gasPrice *big.Int
+	chainID  *big.Int
+	balance  *big.Int
+}
+
+// validateSweep checks the -sweep-to flags before a recovery is run: without -rpc, every value of the transaction must be given.
+func validateSweep(appConfig config.AppConfig) error {
+	if !common.IsHexAddress(appConfig.SweepTo) {
+		return fmt.Errorf("invalid -sweep-to `%s`, expected a 0x address", appConfig.SweepTo)
+	}
+	if appConfig.VerifyOnly {
+		return fmt.Errorf("-sweep-to signs a transaction with the private key, so it can't be combined with -verify or -addresses-only")
+	}
+	if appConfig.WIFOnly {
+		return fmt.Errorf("-sweep-to sends from the Ethereum address, which -wif-only leaves out")
+	}
+	if _, err := newSweepParams(appConfig); err != nil {
+		return err
+	}
+	if appConfig.RPC == "" && (appConfig.SweepNonce < 0 || appConfig.SweepGasPrice == "" || appConfig.SweepBalance == "") {
+		return fmt.Errorf("without -rpc, -sweep-to needs -sweep-nonce, -sweep-gas-price and -sweep-balance")
+	}
+	return nil
+}
+
+// newSweepParams reads the values of the sweep transaction that were given with the -sweep flags.
+func newSweepParams(appConfig config.AppConfig) (sweepParams, error) {
+	params := sweepParams{nonce: int64(appConfig.SweepNonce), chainID: big.NewInt(appConfig.ChainID)}
+	var err error
+	if appConfig.SweepGasPrice != "" {
+		if params.gasPrice, err = parseUnits(appConfig.SweepGasPrice, 9); err != nil {
+			return sweepParams{}, fmt.Errorf("invalid -sweep-gas-price `%s`, expected an amount in gwei: %v", appConfig.SweepGasPrice, err)
+		}
+	}
+	if appConfig.SweepBalance != "" {
+		if params.balance, err = parseUnits(appConfig.SweepBalance, 18); err != nil {
+			return sweepParams{}, fmt.Errorf("invalid -sweep-balance `%s`, expected an amount in ether: %v", appConfig.SweepBalance, err)
+		}
+	}
+	return params, nil
+}
+
+// lookupSweepParams fills in the values of the sweep transaction that were not given, from the -rpc endpoint.
+// The endpoint must be on the chain of -chain-id, as a transaction signed for another chain would not be accepted by it.
+func lookupSweepParams(params sweepParams, rpcURL, address string) (sweepParams, error) {
+	client := &http.Client{Timeout: rpcTimeout}
+	chainID, err := rpcQuantity(client, rpcURL, "eth_chainId")
+	if err != nil {
+		return sweepParams{}, err
+	}
+	if chainID.Cmp(params.chainID) != 0 {
+		return sweepParams{}, fmt.Errorf("-rpc is on chain id %s, but the transaction is signed for -chain-id %s; set -chain-id %s to sign for that chain",
+			chainID, params.chainID, chainID)
+	}
+	lookups := []struct {
+		method string
+		args   []any
+		into   **big.Int
+	}{
+		{"eth_gasPrice", []any{}, &params.gasPrice},
+		{"eth_getBalance", []any{address, "latest"}, &params.balance},
+	}
+	for _, lookup := range lookups {
+		if *lookup.into != nil {
+			continue
+		}
+		value, err := rpcQuantity(client, rpcURL, lookup.method, lookup.args...)
+		if err != nil {
+			return sweepParams{}, err
+		}
+		*lookup.into = value
+	}
+	if params.nonce < 0 {
+		// the pending count also covers transactions of the address that are not mined yet
+		nonce, err := rpcQuantity(client, rpcURL, "eth_getTransactionCount", address, "pending")
+		if err != nil {
+			return sweepParams{}, err
+		}
+		if !nonce.IsInt64() {
+			return sweepParams{}, fmt.Errorf("eth_getTransactionCount returned an invalid nonce %s", nonce)
+		}
+		params.nonce = nonce.Int64()
+	}
+	return params, nil
+}
+
+// buildSweep signs a legacy (EIP-155) transaction that sends the whole balance of the vault's Ethereum address, less the fee
+// of a plain transfer, to the -sweep-to address. The values that were not given with the -sweep flags are looked up with -rpc.
+func buildSweep(appConfig config.AppConfig, ecSK []byte, address string) (*sweepTx, error) {
+	to := common.HexToAddress(appConfig.SweepTo)
+	if strings.EqualFold(to.Hex(), address) {
+		return nil, fmt.Errorf("-sweep-to is the vault's own address `%s`", address)
+	}
+	params, err := newSweepParams(appConfig)
+	if err != nil {
+		return nil, err
+	}
+	if appConfig.RPC != "" {
+		if params, err = lookupSweepParams(params, appConfig.RPC, address); err != nil {
+			return nil, err
+		}
+	}
+
+	fee := new(big.Int).Mul(params.gasPrice, big.NewInt(sweepGasLimit))
+	value := new(big.Int).Sub(params.balance, fee)
+	if value.Sign() <= 0 {
+		return nil, fmt.Errorf("the balance of %s ether does not cover the fee of %s ether", formatUnits(params.balance, 18), formatUnits(fee, 18))
+	}
+
+	privKey, err := ethcrypto.ToECDSA(ecSK)
+	if err != nil {
+		return nil, fmt.Errorf("could not load the private key to sign the transaction: %v", err)
+	}
+	// the copy of the key made for signing is cleared too
+	defer privKey.D.SetInt64(0)
+
+	tx, err := types.SignNewTx(privKey, types.NewEIP155Signer(params.chainID), &types.LegacyTx{
+		Nonce:    uint64(params.nonce),
+		GasPrice: params.gasPrice,
+		Gas:      sweepGasLimit,
+		To:       &to,
+		Value:    value,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not sign the transaction: %v", err)
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("could not encode the transaction: %v", err)
+	}
+	return &sweepTx{
+		From:         address,
+		To:           to.Hex(),
+		Value:        formatUnits(value, 18),
+		Nonce:        tx.Nonce(),
+		GasPriceGwei: formatUnits(params.gasPrice, 9),
+		GasLimit:     tx.Gas(),
+		ChainID:      params.chainID.Int64(),
+		Hash:         tx.Hash().Hex(),
+		RawTx:        hexutil.Encode(raw),
+	}, nil
+}
+
+// sweepSection outputs the signed -sweep-to transaction.
+func sweepSection(sweep *sweepTx) outputSection {
+	return outputSection{
+		Title: "Sweep transaction",
+		Note: "Signed, but NOT broadcast. Check the values, then broadcast the raw transaction yourself, " +
+			"e.g. with eth_sendRawTransaction or a block explorer's broadcast page.",
+		Fields: []outputField{
+			{Label: "From", Value: sweep.From},
+			{Label: "To", Value: sweep.To},
+			{Label: "Amount", Value: sweep.Value + " ether"},
+			{Label: "Nonce", Value: fmt.Sprint(sweep.Nonce)},
+			{Label: "Gas price", Value: sweep.GasPriceGwei + " gwei"},
+			{Label: "Gas limit", Value: fmt.Sprint(sweep.GasLimit)},
+			{Label: "Chain id", Value: fmt.Sprint(sweep.ChainID)},
+			{Label: "Tx hash", Value: sweep.Hash},
+			{Label: "Raw transaction", Value: sweep.RawTx},
+		},
+	}
+}
+
+// parseUnits parses a decimal amount, e.g. of ether or gwei, into its smallest unit, with the given number of decimals.
+func parseUnits(amount string, decimals int) (*big.Int, error) {
+	whole, frac, _ := strings.Cut(strings.TrimSpace(amount), ".")
+	if len(frac) > decimals {
+		return nil, fmt.Errorf("more than %d decimals", decimals)
+	}
+	digits := whole + frac + strings.Repeat("0", decimals-len(frac))
+	n, ok := new(big.Int).SetString(digits, 10)
+	if !ok || whole == "" || n.Sign() < 0 || strings.ContainsAny(digits, "+-") {
+		return nil, fmt.Errorf("not a positive decimal number")
+	}
+	return n, nil
+}
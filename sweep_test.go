@@ -0,0 +1,164 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/config"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// The test sweeps go from the address of the private key 1 to the address of the private key 2.
+var (
+	sweepKey     = big.NewInt(1).FillBytes(make([]byte, 32))
+	sweepFrom    = "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"
+	sweepAddress = "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"
+)
+
+// decodeSweep decodes the raw transaction of a sweep and checks that it is signed by sweepKey.
+func decodeSweep(t *testing.T, sweep *sweepTx) *types.Transaction {
+	t.Helper()
+	raw, err := hexutil.Decode(sweep.RawTx)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	tx := new(types.Transaction)
+	if !assert.NoError(t, tx.UnmarshalBinary(raw)) {
+		t.FailNow()
+	}
+	sender, err := types.Sender(types.NewEIP155Signer(tx.ChainId()), tx)
+	if !assert.NoError(t, err) || !assert.Equal(t, sweepFrom, sender.Hex()) {
+		t.FailNow()
+	}
+	return tx
+}
+
+func TestBuildSweep_Offline(t *testing.T) {
+	appConfig := config.AppConfig{SweepTo: sweepAddress, SweepNonce: 7, SweepGasPrice: "20", ChainID: 1, SweepBalance: "1.5"}
+	if !assert.NoError(t, validateSweep(appConfig)) {
+		return
+	}
+	sweep, err := buildSweep(appConfig, sweepKey, sweepFrom)
+	if !assert.NoError(t, err) {
+		return
+	}
+	tx := decodeSweep(t, sweep)
+	assert.Equal(t, uint64(7), tx.Nonce())
+	assert.Equal(t, int64(1), tx.ChainId().Int64())
+	assert.Equal(t, uint64(sweepGasLimit), tx.Gas())
+	assert.Equal(t, sweepAddress, tx.To().Hex())
+	// 1.5 ether less 21000 gas at 20 gwei
+	assert.Equal(t, "1499580000000000000", tx.Value().String())
+	assert.Equal(t, "1.49958", sweep.Value)
+	assert.Equal(t, tx.Hash().Hex(), sweep.Hash)
+}
+
+func TestBuildSweep_RPC(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&req)) {
+			return
+		}
+		results := map[string]string{
+			"eth_chainId":             "0xaa36a7",
+			"eth_gasPrice":            "0x3b9aca00",
+			"eth_getBalance":          "0xde0b6b3a7640000",
+			"eth_getTransactionCount": "0x2a",
+		}
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, results[req.Method])
+	}))
+	defer server.Close()
+
+	// the gas price that is given is used instead of the one of the endpoint
+	appConfig := config.AppConfig{SweepTo: sweepAddress, SweepNonce: -1, SweepGasPrice: "2", ChainID: 11155111, RPC: server.URL}
+	sweep, err := buildSweep(appConfig, sweepKey, sweepFrom)
+	if !assert.NoError(t, err) {
+		return
+	}
+	tx := decodeSweep(t, sweep)
+	assert.Equal(t, uint64(42), tx.Nonce())
+	assert.Equal(t, int64(11155111), tx.ChainId().Int64())
+	assert.Equal(t, "2000000000", tx.GasPrice().String())
+	assert.Equal(t, "999958000000000000", tx.Value().String())
+
+	// a transaction for mainnet is not signed with an endpoint on Sepolia
+	appConfig.ChainID = 1
+	_, err = buildSweep(appConfig, sweepKey, sweepFrom)
+	assert.ErrorContains(t, err, "-rpc is on chain id 11155111, but the transaction is signed for -chain-id 1")
+}
+
+func TestBuildSweep_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		appConfig config.AppConfig
+		expected  string
+	}{
+		{"Balance Below Fee", config.AppConfig{SweepTo: sweepAddress, SweepNonce: 0, SweepGasPrice: "20", ChainID: 1, SweepBalance: "0.0001"},
+			"the balance of 0.0001 ether does not cover the fee of 0.00042 ether"},
+		{"Own Address", config.AppConfig{SweepTo: sweepFrom, SweepNonce: 0, SweepGasPrice: "20", ChainID: 1, SweepBalance: "1"},
+			"-sweep-to is the vault's own address"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildSweep(tt.appConfig, sweepKey, sweepFrom)
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestValidateSweep(t *testing.T) {
+	tests := []struct {
+		name      string
+		appConfig config.AppConfig
+		expected  string
+	}{
+		{"Bad Address", config.AppConfig{SweepTo: "vitalik.eth", RPC: "https://eth.example.com"}, "invalid -sweep-to `vitalik.eth`"},
+		{"Verify", config.AppConfig{SweepTo: sweepAddress, RPC: "https://eth.example.com", VerifyOnly: true}, "can't be combined with -verify"},
+		{"Missing Values Offline", config.AppConfig{SweepTo: sweepAddress, SweepNonce: -1, SweepGasPrice: "20", ChainID: 1, SweepBalance: "1"},
+			"without -rpc, -sweep-to needs -sweep-nonce"},
+		{"Bad Gas Price", config.AppConfig{SweepTo: sweepAddress, SweepGasPrice: "20gwei", RPC: "https://eth.example.com"}, "invalid -sweep-gas-price `20gwei`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, validateSweep(tt.appConfig), tt.expected)
+		})
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		decimals int
+		expected string
+	}{
+		{"Whole", "1", 18, "1000000000000000000"},
+		{"Fraction", "1.5", 9, "1500000000"},
+		{"Smallest Unit", "0.000000001", 9, "1"},
+		{"Zero", "0", 9, "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := parseUnits(tt.amount, tt.decimals)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, n.String())
+			}
+		})
+	}
+	for _, bad := range []string{"", ".5", "-1", "+1", "1e9", "0.0000000001"} {
+		_, err := parseUnits(bad, 9)
+		assert.Error(t, err, bad)
+	}
+}
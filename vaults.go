@@ -0,0 +1,209 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/ui"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+// resolveVault finds the vault referred to by the -vault-id argument.
+// It is matched against the full vault id, then a unique id prefix, then the vault name,
+// and finally the 1-based position of the vault in the (sorted) vault list.
+func resolveVault(vaults []ui.VaultPickerItem, query string) (ui.VaultPickerItem, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return ui.VaultPickerItem{}, fmt.Errorf("no vault id given")
+	}
+	for _, vault := range vaults {
+		if vault.VaultID == query {
+			return vault, nil
+		}
+	}
+
+	byPrefix := make([]ui.VaultPickerItem, 0, 1)
+	byName := make([]ui.VaultPickerItem, 0, 1)
+	for _, vault := range vaults {
+		if strings.HasPrefix(vault.VaultID, query) {
+			byPrefix = append(byPrefix, vault)
+		}
+		if strings.EqualFold(vault.Name, query) {
+			byName = append(byName, vault)
+		}
+	}
+	for _, matches := range [][]ui.VaultPickerItem{byPrefix, byName} {
+		switch {
+		case len(matches) == 1:
+			return matches[0], nil
+		case len(matches) > 1:
+			return ui.VaultPickerItem{}, fmt.Errorf("`%s` matches more than one vault: %s", query, describeVaults(matches))
+		}
+	}
+
+	if idx, err := strconv.Atoi(query); err == nil {
+		if idx < 1 || idx > len(vaults) {
+			return ui.VaultPickerItem{}, fmt.Errorf("vault number %d is out of range, expected 1 to %d", idx, len(vaults))
+		}
+		return vaults[idx-1], nil
+	}
+	return ui.VaultPickerItem{}, fmt.Errorf("vault with ID %s not found", query)
+}
+
+// resolveVaultName finds the vault named name, ignoring case. Failing an exact match, a name that contains it also works, as long as only one does.
+func resolveVaultName(vaults []ui.VaultPickerItem, name string) (ui.VaultPickerItem, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ui.VaultPickerItem{}, fmt.Errorf("no vault name given")
+	}
+	byName := make([]ui.VaultPickerItem, 0, 1)
+	for _, vault := range vaults {
+		if strings.EqualFold(vault.Name, name) {
+			byName = append(byName, vault)
+		}
+	}
+	if len(byName) == 0 {
+		byName = filterVaults(vaults, name)
+	}
+	switch len(byName) {
+	case 0:
+		return ui.VaultPickerItem{}, fmt.Errorf("no vault named `%s` found", name)
+	case 1:
+		return byName[0], nil
+	default:
+		return ui.VaultPickerItem{}, fmt.Errorf("more than one vault is named `%s`, pass -vault-id with one of: %s", name, describeVaults(byName))
+	}
+}
+
+// filterVaults keeps the vaults whose name contains filter, ignoring case. An empty filter keeps them all.
+func filterVaults(vaults []ui.VaultPickerItem, filter string) []ui.VaultPickerItem {
+	if filter == "" {
+		return vaults
+	}
+	filter = strings.ToLower(filter)
+	kept := make([]ui.VaultPickerItem, 0, len(vaults))
+	for _, vault := range vaults {
+		if strings.Contains(strings.ToLower(vault.Name), filter) {
+			kept = append(kept, vault)
+		}
+	}
+	return kept
+}
+
+// vaultIDsFlag collects the values of a repeated -vault-id flag.
+type vaultIDsFlag []string
+
+func (f *vaultIDsFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *vaultIDsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// selectVaults resolves each query with resolveVault, in order. A vault that more than one query resolves to is selected once.
+func selectVaults(vaults []ui.VaultPickerItem, queries []string) ([]ui.VaultPickerItem, error) {
+	selected := make([]ui.VaultPickerItem, 0, len(queries))
+	seen := make(map[string]struct{}, len(queries))
+	for _, query := range queries {
+		vault, err := resolveVault(vaults, query)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[vault.VaultID]; !ok {
+			seen[vault.VaultID] = struct{}{}
+			selected = append(selected, vault)
+		}
+	}
+	return selected, nil
+}
+
+// walletFileName names the wallet v3 file of a vault in -output-dir after its name and id, keeping only the characters that are safe in a file name.
+func walletFileName(vault ui.VaultPickerItem) string {
+	name := strings.Trim(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, vault.Name), "_")
+	if name == "" {
+		return vault.VaultID + ".json"
+	}
+	return name + "-" + vault.VaultID + ".json"
+}
+
+// vaultListCSV renders the -list-csv spreadsheet of the vaults, with a header row.
+func vaultListCSV(vaults []ui.VaultPickerItem) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	rows := [][]string{{"vault_id", "name", "threshold", "shares", "last_reshare_nonce", "enough_shares"}}
+	for _, vault := range vaults {
+		rows = append(rows, []string{
+			vault.VaultID,
+			vault.Name,
+			strconv.Itoa(vault.Quorum),
+			strconv.Itoa(vault.NumberOfShares),
+			strconv.Itoa(vault.LastReShareNonce),
+			strconv.FormatBool(vault.NumberOfShares >= vault.Quorum),
+		})
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderVaultTable renders the -list table of the vaults, with the vaults that have fewer shares than their threshold in red.
+// NONCES lists every reshare nonce found for the vault. With plain, the table has no borders and no colours.
+func renderVaultTable(vaults []ui.VaultPickerItem, plain bool) string {
+	t := table.New().Headers("#", "ID", "NAME", "THRESHOLD", "SHARES", "NONCE", "NONCES")
+	for i, vault := range vaults {
+		nonces := make([]string, len(vault.ReShareNonces))
+		for j, nonce := range vault.ReShareNonces {
+			nonces[j] = strconv.Itoa(nonce)
+		}
+		t.Row(strconv.Itoa(i+1), vault.VaultID, vault.Name, strconv.Itoa(vault.Quorum), strconv.Itoa(vault.NumberOfShares), strconv.Itoa(vault.LastReShareNonce),
+			strings.Join(nonces, ","))
+	}
+	return styleTable(t, plain, func(i int) bool { return vaults[i].NumberOfShares < vaults[i].Quorum })
+}
+
+// styleTable renders a table with a bold header and the rows for which flagged is true in red, or with no borders and no colours with plain.
+func styleTable(t *table.Table, plain bool, flagged func(i int) bool) string {
+	if plain {
+		// the columns are only separated by spaces
+		return t.Border(lipgloss.HiddenBorder()).BorderTop(false).BorderBottom(false).BorderLeft(false).BorderRight(false).BorderHeader(false).
+			StyleFunc(func(row, col int) lipgloss.Style { return lipgloss.NewStyle().PaddingRight(1) }).String() + "\n"
+	}
+
+	cell := lipgloss.NewStyle().Padding(0, 1)
+	header, red := cell.Bold(true), cell.Foreground(lipgloss.Color("9"))
+	return t.Border(lipgloss.RoundedBorder()).StyleFunc(func(row, col int) lipgloss.Style {
+		switch {
+		case row == 0:
+			return header
+		case flagged(row - 1):
+			return red
+		default:
+			return cell
+		}
+	}).String() + "\n"
+}
+
+func describeVaults(vaults []ui.VaultPickerItem) string {
+	descs := make([]string, len(vaults))
+	for i, vault := range vaults {
+		descs[i] = fmt.Sprintf("%s (%s)", vault.VaultID, vault.Name)
+	}
+	return strings.Join(descs, ", ")
+}
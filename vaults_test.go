@@ -0,0 +1,180 @@
+// Copyright (C) 2021 io finnet group, inc.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Full license text available in LICENSE file in repository root.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/IoFinnet/io-vault-disaster-recovery-cli/internal/ui"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveVault(t *testing.T) {
+	vaults := []ui.VaultPickerItem{
+		{VaultID: "a70uaean4isi6aci8zzky970", Name: "NewCurveVault"},
+		{VaultID: "afpuzaa5j3k7wyjfgkuvbcxz", Name: "NewCurveVault1"},
+		{VaultID: "liw3bn8yqykgh96uort11knz", Name: "James"},
+		{VaultID: "prd15bna3h9oxoo04dc4cn1p", Name: "James"},
+		{VaultID: "3bc8uksrk5zuxihufj4m8dkt", Name: "UpgradeTest4"},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		wantErr  string
+	}{
+		{"Full ID", "afpuzaa5j3k7wyjfgkuvbcxz", "afpuzaa5j3k7wyjfgkuvbcxz", ""},
+		{"Unique Prefix", "liw3", "liw3bn8yqykgh96uort11knz", ""},
+		{"Ambiguous Prefix", "a", "", "matches more than one vault"},
+		{"Name", "newcurvevault1", "afpuzaa5j3k7wyjfgkuvbcxz", ""},
+		{"Ambiguous Name", "James", "", "matches more than one vault"},
+		{"Prefix Before Index", "3", "3bc8uksrk5zuxihufj4m8dkt", ""},
+		{"Index", "4", "prd15bna3h9oxoo04dc4cn1p", ""},
+		{"Index Out Of Range", "6", "", "out of range"},
+		{"Index Zero", "0", "", "out of range"},
+		{"Not Found", "nope", "", "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vault, err := resolveVault(vaults, tt.query)
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.wantErr)
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expected, vault.VaultID)
+		})
+	}
+}
+
+func TestResolveVaultName(t *testing.T) {
+	vaults := []ui.VaultPickerItem{
+		{VaultID: "a70uaean4isi6aci8zzky970", Name: "NewCurveVault"},
+		{VaultID: "afpuzaa5j3k7wyjfgkuvbcxz", Name: "NewCurveVault1"},
+		{VaultID: "liw3bn8yqykgh96uort11knz", Name: "James"},
+		{VaultID: "prd15bna3h9oxoo04dc4cn1p", Name: "James"},
+		{VaultID: "3bc8uksrk5zuxihufj4m8dkt", Name: "UpgradeTest4"},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		wantErr  string
+	}{
+		{"Exact Name", "NewCurveVault", "a70uaean4isi6aci8zzky970", ""},
+		{"Any Case", " newcurvevault1 ", "afpuzaa5j3k7wyjfgkuvbcxz", ""},
+		{"Unique Part", "upgrade", "3bc8uksrk5zuxihufj4m8dkt", ""},
+		{"Ambiguous Name", "james", "", "liw3bn8yqykgh96uort11knz (James), prd15bna3h9oxoo04dc4cn1p (James)"},
+		{"Ambiguous Part", "Curve", "", "more than one vault"},
+		{"Not An ID", "liw3", "", "no vault named `liw3` found"},
+		{"Empty", " ", "", "no vault name given"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vault, err := resolveVaultName(vaults, tt.query)
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.wantErr)
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expected, vault.VaultID)
+		})
+	}
+}
+
+func TestSelectVaults(t *testing.T) {
+	vaults := []ui.VaultPickerItem{
+		{VaultID: "a70uaean4isi6aci8zzky970", Name: "NewCurveVault"},
+		{VaultID: "liw3bn8yqykgh96uort11knz", Name: "James"},
+		{VaultID: "3bc8uksrk5zuxihufj4m8dkt", Name: "UpgradeTest4"},
+	}
+
+	selected, err := selectVaults(vaults, []string{"UpgradeTest4", "a70u", "3bc8uksrk5zuxihufj4m8dkt"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, selected, 2) {
+		return
+	}
+	assert.Equal(t, "3bc8uksrk5zuxihufj4m8dkt", selected[0].VaultID)
+	assert.Equal(t, "a70uaean4isi6aci8zzky970", selected[1].VaultID)
+
+	_, err = selectVaults(vaults, []string{"James", "nope"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not found")
+	}
+}
+
+func TestWalletFileName(t *testing.T) {
+	tests := []struct {
+		name     string
+		vault    ui.VaultPickerItem
+		expected string
+	}{
+		{"Plain Name", ui.VaultPickerItem{VaultID: "liw3bn8yqykgh96uort11knz", Name: "James"}, "James-liw3bn8yqykgh96uort11knz.json"},
+		{"Unsafe Characters", ui.VaultPickerItem{VaultID: "phrot42ltzawmn7nrm7mqvl5", Name: "EdDSA Export/Tool Test"}, "EdDSA_Export_Tool_Test-phrot42ltzawmn7nrm7mqvl5.json"},
+		{"Unsafe Name", ui.VaultPickerItem{VaultID: "liw3bn8yqykgh96uort11knz", Name: "../"}, "liw3bn8yqykgh96uort11knz.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, walletFileName(tt.vault))
+		})
+	}
+}
+
+func TestVaultListCSV(t *testing.T) {
+	vaults := []ui.VaultPickerItem{
+		{VaultID: "liw3bn8yqykgh96uort11knz", Name: "James", Quorum: 2, NumberOfShares: 2, LastReShareNonce: 1},
+		{VaultID: "bfc8uksrk5zuxihufj4m8dkt", Name: "Upgrade, Test 4", Quorum: 2, NumberOfShares: 1},
+	}
+	out, err := vaultListCSV(vaults)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "vault_id,name,threshold,shares,last_reshare_nonce,enough_shares\n"+
+		"liw3bn8yqykgh96uort11knz,James,2,2,1,true\n"+
+		"bfc8uksrk5zuxihufj4m8dkt,\"Upgrade, Test 4\",2,1,0,false\n", string(out))
+}
+
+func TestRenderVaultTable(t *testing.T) {
+	vaults := []ui.VaultPickerItem{
+		{VaultID: "liw3bn8yqykgh96uort11knz", Name: "James", Quorum: 2, NumberOfShares: 2, LastReShareNonce: 4, ReShareNonces: []int{1, 2, 4}},
+		{VaultID: "nbpxb6hmupk1ygcl53jf9zg5", Name: "2VSSmartContractMode1Iphone", Quorum: 3, NumberOfShares: 1, ReShareNonces: []int{0}},
+	}
+	lines := strings.Split(strings.TrimRight(renderVaultTable(vaults, true), "\n"), "\n")
+	if !assert.Len(t, lines, 3) {
+		return
+	}
+	assert.Equal(t, []string{"#", "ID", "NAME", "THRESHOLD", "SHARES", "NONCE", "NONCES"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"1", "liw3bn8yqykgh96uort11knz", "James", "2", "2", "4", "1,2,4"}, strings.Fields(lines[1]))
+	// the columns are aligned, as each one is padded to its widest cell
+	assert.Equal(t, strings.Index(lines[0], "THRESHOLD"), strings.Index(lines[2], " 3 ")+1)
+}
+
+func TestFilterVaults(t *testing.T) {
+	vaults := []ui.VaultPickerItem{
+		{VaultID: "a70uaean4isi6aci8zzky970", Name: "NewCurveVault"},
+		{VaultID: "liw3bn8yqykgh96uort11knz", Name: "James"},
+		{VaultID: "prd15bna3h9oxoo04dc4cn1p", Name: "James Test Big vault"},
+	}
+	assert.Equal(t, vaults, filterVaults(vaults, ""))
+	assert.Equal(t, vaults[1:], filterVaults(vaults, "JAMES"))
+	assert.Equal(t, []ui.VaultPickerItem{vaults[0], vaults[2]}, filterVaults(vaults, "vault"))
+	assert.Empty(t, filterVaults(vaults, "nope"))
+}